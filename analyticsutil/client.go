@@ -0,0 +1,187 @@
+package analyticsutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/couchbase/tools-common/cbrest"
+	"github.com/couchbase/tools-common/queryutil"
+)
+
+// EndpointAnalyticsService is used to execute analytics statements.
+const EndpointAnalyticsService cbrest.Endpoint = "/analytics/service"
+
+const (
+	// listScopesStatement returns all the user created analytics scopes.
+	listScopesStatement = "SELECT VALUE d FROM Metadata.`Dataverse` d WHERE d.DataverseName <> \"Metadata\""
+
+	// listDatasetsStatement returns all the user created datasets.
+	listDatasetsStatement = "SELECT VALUE d FROM Metadata.`Dataset` d WHERE d.DataverseName <> \"Metadata\""
+)
+
+// Scope represents an analytics scope (previously known as a dataverse).
+type Scope struct {
+	// Name is the name of the scope, multi-part names are separated by a '/' e.g. 'bucket/scope'.
+	Name string `json:"DataverseName"`
+}
+
+// Dataset represents an analytics dataset (also known as an analytics collection).
+type Dataset struct {
+	Name       string `json:"DatasetName"`
+	Scope      string `json:"DataverseName"`
+	LinkName   string `json:"LinkName"`
+	BucketName string `json:"BucketName"`
+}
+
+// DatasetDefinition encapsulates the options which may be used to create a dataset.
+type DatasetDefinition struct {
+	// Scope is the analytics scope the dataset will be created in, the 'Default' scope is used if not provided.
+	Scope string
+
+	// Name is the name of the dataset.
+	//
+	// NOTE: This attribute is required.
+	Name string
+
+	// Bucket is the name of the bucket which is the source of the dataset.
+	//
+	// NOTE: This attribute is required.
+	Bucket string
+
+	// BucketScope/BucketCollection are the source collection for the dataset, where the bucket is used if neither is
+	// provided.
+	BucketScope      string
+	BucketCollection string
+
+	// Link is the name of the link the source bucket should be accessed through, the local link is used if not
+	// provided.
+	Link string
+
+	// Where is an optional analytics expression used to filter the documents in the dataset, this is not escaped.
+	Where string
+
+	// IgnoreIfExists indicates that no error should be returned if the dataset already exists.
+	IgnoreIfExists bool
+}
+
+// Client is a wrapper around the 'cbrest' client which implements methods to manage analytics metadata, and execute
+// analytics statements.
+type Client struct {
+	*cbrest.Client
+}
+
+// NewClient creates a new client which will dispatch requests using the given 'cbrest' client.
+func NewClient(client *cbrest.Client) *Client {
+	return &Client{client}
+}
+
+// ExecuteStatement executes the given analytics statement, streaming the rows to the provided function as they are
+// returned by the Analytics Service; the statement metadata is returned once all the rows have been processed.
+//
+// Failures reported before results are returned are reported as a 'cbrest.ServiceError', failures reported after
+// results have begun being returned are reported as a 'queryutil.QueryError'.
+func (c *Client) ExecuteStatement(ctx context.Context, options queryutil.QueryOptions,
+	fn queryutil.RowFunc,
+) (*queryutil.Metadata, error) {
+	if options.Statement == "" {
+		return nil, ErrStatementRequired
+	}
+
+	body, err := options.Encode()
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	request := &cbrest.Request{
+		Body:               body,
+		ContentType:        cbrest.ContentTypeJSON,
+		Endpoint:           EndpointAnalyticsService,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            cbrest.ServiceAnalytics,
+	}
+
+	reader, err := c.ExecuteReaderWithContext(ctx, request)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+	defer reader.Close()
+
+	metadata, err := queryutil.DecodeResponse(reader, fn)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	return metadata, metadata.Err()
+}
+
+// ListScopes returns all the user created analytics scopes.
+func (c *Client) ListScopes(ctx context.Context) ([]*Scope, error) {
+	scopes := make([]*Scope, 0)
+
+	_, err := c.ExecuteStatement(ctx, queryutil.QueryOptions{Statement: listScopesStatement},
+		func(row json.RawMessage) error {
+			var scope *Scope
+
+			err := json.Unmarshal(row, &scope)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal scope: %w", err)
+			}
+
+			scopes = append(scopes, scope)
+
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scopes: %w", err)
+	}
+
+	return scopes, nil
+}
+
+// CreateScope creates the given analytics scope, multi-part names should be separated by a '/' e.g. 'bucket/scope'.
+func (c *Client) CreateScope(ctx context.Context, name string, ignoreIfExists bool) error {
+	return c.execute(ctx, createScopeStatement(name, ignoreIfExists))
+}
+
+// ListDatasets returns all the user created datasets.
+func (c *Client) ListDatasets(ctx context.Context) ([]*Dataset, error) {
+	datasets := make([]*Dataset, 0)
+
+	_, err := c.ExecuteStatement(ctx, queryutil.QueryOptions{Statement: listDatasetsStatement},
+		func(row json.RawMessage) error {
+			var dataset *Dataset
+
+			err := json.Unmarshal(row, &dataset)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal dataset: %w", err)
+			}
+
+			datasets = append(datasets, dataset)
+
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list datasets: %w", err)
+	}
+
+	return datasets, nil
+}
+
+// CreateDataset creates the given dataset.
+func (c *Client) CreateDataset(ctx context.Context, definition DatasetDefinition) error {
+	return c.execute(ctx, createDatasetStatement(definition))
+}
+
+// execute executes the given statement, discarding any returned rows.
+func (c *Client) execute(ctx context.Context, statement string) error {
+	_, err := c.ExecuteStatement(ctx, queryutil.QueryOptions{Statement: statement},
+		func(_ json.RawMessage) error { return nil })
+	if err != nil {
+		return fmt.Errorf("failed to execute statement '%s': %w", statement, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package analyticsutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/cbrest"
+	"github.com/couchbase/tools-common/queryutil"
+)
+
+func newTestClient(t *testing.T, handlers cbrest.TestHandlers) *Client {
+	nodes := cbrest.TestNodes{{Services: []cbrest.Service{cbrest.ServiceAnalytics}}}
+
+	return NewClient(cbrest.NewTestClusterClient(t, nodes, handlers))
+}
+
+// newTestStatementHandler returns a handler which records the executed statements, and responds with the given rows.
+func newTestStatementHandler(t *testing.T, statements *[]string, rows string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+
+		*statements = append(*statements, body["statement"].(string))
+
+		_, err := writer.Write([]byte(`{"requestID":"request","results":` + rows + `,"status":"success",` +
+			`"metrics":{"elapsedTime":"1ms","executionTime":"1ms","resultCount":0,"processedObjects":0}}`))
+		require.NoError(t, err)
+	}
+}
+
+func TestClientExecuteStatement(t *testing.T) {
+	var statements []string
+
+	handlers := make(cbrest.TestHandlers)
+	handlers.Add(http.MethodPost, string(EndpointAnalyticsService),
+		newTestStatementHandler(t, &statements, `[{"a":1},{"a":2}]`))
+
+	var rows []string
+
+	metadata, err := newTestClient(t, handlers).ExecuteStatement(context.Background(),
+		queryutil.QueryOptions{Statement: "SELECT 1"}, func(row json.RawMessage) error {
+			rows = append(rows, string(row))
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, "request", metadata.RequestID)
+	require.Equal(t, []string{`{"a":1}`, `{"a":2}`}, rows)
+	require.Equal(t, []string{"SELECT 1"}, statements)
+}
+
+func TestClientExecuteStatementErrors(t *testing.T) {
+	type test struct {
+		name   string
+		status int
+		body   string
+		check  func(t *testing.T, err error)
+	}
+
+	tests := []*test{
+		{
+			name:   "ServiceError",
+			status: http.StatusNotFound,
+			body:   `{"errors":[{"code":24045,"msg":"Cannot find analytics scope with name s1"}],"status":"fatal"}`,
+			check: func(t *testing.T, err error) {
+				require.True(t, cbrest.IsServiceErrorCode(err, 24045))
+
+				var notFound *cbrest.EndpointNotFoundError
+				require.ErrorAs(t, err, &notFound)
+			},
+		},
+		{
+			name:   "QueryError",
+			status: http.StatusOK,
+			body:   `{"results":[],"errors":[{"code":23000,"msg":"timeout"}],"status":"timeout"}`,
+			check: func(t *testing.T, err error) {
+				var queryErr *queryutil.QueryError
+				require.ErrorAs(t, err, &queryErr)
+				require.Equal(t, queryutil.StatusTimeout, queryErr.Status())
+				require.Equal(t, []queryutil.Error{{Code: 23000, Message: "timeout"}}, queryErr.Errors())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handlers := make(cbrest.TestHandlers)
+			handlers.Add(http.MethodPost, string(EndpointAnalyticsService),
+				cbrest.NewTestHandler(t, test.status, []byte(test.body)))
+
+			_, err := newTestClient(t, handlers).ExecuteStatement(context.Background(),
+				queryutil.QueryOptions{Statement: "SELECT 1"}, func(_ json.RawMessage) error { return nil })
+			test.check(t, err)
+		})
+	}
+
+	_, err := (&Client{}).ExecuteStatement(context.Background(), queryutil.QueryOptions{}, nil)
+	require.ErrorIs(t, err, ErrStatementRequired)
+}
+
+func TestClientScopesAndDatasets(t *testing.T) {
+	var statements []string
+
+	handlers := make(cbrest.TestHandlers)
+	handlers.Add(http.MethodPost, string(EndpointAnalyticsService), func(writer http.ResponseWriter,
+		request *http.Request,
+	) {
+		var rows string
+
+		switch len(statements) {
+		case 0:
+			rows = `[{"DataverseName":"Default"},{"DataverseName":"bucket/scope"}]`
+		case 1:
+			rows = `[{"DatasetName":"ds","DataverseName":"Default","LinkName":"Local","BucketName":"default"}]`
+		default:
+			rows = `[]`
+		}
+
+		newTestStatementHandler(t, &statements, rows)(writer, request)
+	})
+
+	client := newTestClient(t, handlers)
+
+	scopes, err := client.ListScopes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []*Scope{{Name: "Default"}, {Name: "bucket/scope"}}, scopes)
+
+	datasets, err := client.ListDatasets(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []*Dataset{{Name: "ds", Scope: "Default", LinkName: "Local", BucketName: "default"}}, datasets)
+
+	require.NoError(t, client.CreateScope(context.Background(), "scope", true))
+	require.NoError(t, client.CreateDataset(context.Background(), DatasetDefinition{Name: "ds", Bucket: "default"}))
+
+	expected := []string{
+		listScopesStatement,
+		listDatasetsStatement,
+		"CREATE DATAVERSE `scope` IF NOT EXISTS",
+		"CREATE DATASET `ds` ON `default`",
+	}
+
+	require.Equal(t, expected, statements)
+}
+
+func TestClientLinks(t *testing.T) {
+	var actual url.Values
+
+	handlers := make(cbrest.TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointLinks), cbrest.NewTestHandler(t, http.StatusOK,
+		[]byte(`[{"scope":"Default","name":"remote","type":"couchbase","bootstrapHostname":"host"}]`)))
+	handlers.Add(http.MethodGet, "/analytics/link/bucket/scope", cbrest.NewTestHandler(t, http.StatusOK,
+		[]byte(`[]`)))
+	handlers.Add(http.MethodPost, "/analytics/link/bucket/scope/remote",
+		cbrest.NewTestHandlerWithValue(t, http.StatusOK, nil, &actual))
+
+	client := newTestClient(t, handlers)
+
+	links, err := client.ListLinks(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, []*Link{{Scope: "Default", Name: "remote", Type: LinkTypeCouchbase, Hostname: "host"}}, links)
+
+	links, err = client.ListLinks(context.Background(), "bucket/scope")
+	require.NoError(t, err)
+	require.Empty(t, links)
+
+	err = client.CreateLink(context.Background(), &Link{
+		Scope:      "bucket/scope",
+		Name:       "remote",
+		Type:       LinkTypeCouchbase,
+		Hostname:   "host",
+		Encryption: EncryptionNone,
+		Username:   "admin",
+		Password:   "password",
+	})
+	require.NoError(t, err)
+	require.Equal(t, url.Values{
+		"type":       {"couchbase"},
+		"hostname":   {"host"},
+		"encryption": {"none"},
+		"username":   {"admin"},
+		"password":   {"password"},
+	}, actual)
+
+	require.ErrorIs(t, client.CreateLink(context.Background(), &Link{Name: "remote"}), ErrLinkTypeRequired)
+
+	require.ErrorIs(t, client.CreateLink(context.Background(), &Link{Scope: "Default", Type: LinkTypeCouchbase}),
+		ErrLinkNameRequired)
+
+	for _, scope := range []string{"", "bucket/", "/scope"} {
+		require.ErrorIs(t, client.CreateLink(context.Background(), &Link{
+			Scope: scope,
+			Name:  "remote",
+			Type:  LinkTypeCouchbase,
+		}), ErrLinkScopeRequired)
+	}
+}
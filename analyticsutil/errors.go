@@ -0,0 +1,18 @@
+package analyticsutil
+
+import "errors"
+
+var (
+	// ErrStatementRequired is returned if the user attempts to execute an analytics statement without providing one.
+	ErrStatementRequired = errors.New("a statement is required")
+
+	// ErrLinkTypeRequired is returned if the user attempts to create a link without providing its type.
+	ErrLinkTypeRequired = errors.New("a link type is required")
+
+	// ErrLinkNameRequired is returned if the user attempts to create a link without providing its name.
+	ErrLinkNameRequired = errors.New("a link name is required")
+
+	// ErrLinkScopeRequired is returned if the user attempts to create a link without providing the scope it belongs to,
+	// or where any part of a multi-part scope name is empty e.g. 'bucket/'.
+	ErrLinkScopeRequired = errors.New("a link scope is required")
+)
@@ -0,0 +1,221 @@
+package analyticsutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+// EndpointLinks is used to list/manage analytics links.
+const EndpointLinks cbrest.Endpoint = "/analytics/link"
+
+// LinkType represents the type of an analytics link.
+type LinkType string
+
+const (
+	// LinkTypeCouchbase is a link to a remote Couchbase cluster.
+	LinkTypeCouchbase LinkType = "couchbase"
+
+	// LinkTypeS3 is a link to AWS S3 (or an S3 compatible object store).
+	LinkTypeS3 LinkType = "s3"
+)
+
+// Encryption represents the encryption level used by a link to a remote Couchbase cluster.
+type Encryption string
+
+const (
+	// EncryptionNone indicates that no data is encrypted.
+	EncryptionNone Encryption = "none"
+
+	// EncryptionHalf indicates that only credentials are encrypted.
+	EncryptionHalf Encryption = "half"
+
+	// EncryptionFull indicates that all data is encrypted, a certificate must be provided.
+	EncryptionFull Encryption = "full"
+)
+
+// Link represents an analytics link to a remote Couchbase cluster or S3.
+//
+// NOTE: Secrets such as passwords/secret keys are redacted by the Analytics Service when listing links, so they must be
+// re-supplied when recreating a link.
+type Link struct {
+	// Scope is the analytics scope the link belongs to, multi-part names are separated by a '/' e.g. 'bucket/scope';
+	// links in the default scope should use 'Default'.
+	Scope string
+	Name  string
+	Type  LinkType
+
+	// The following attributes are used by links to remote Couchbase clusters.
+	Hostname          string
+	Encryption        Encryption
+	Username          string
+	Password          string
+	Certificate       string
+	ClientCertificate string
+	ClientKey         string
+
+	// The following attributes are used by links to S3.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+	ServiceEndpoint string
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, handling the differences between the attributes returned
+// by different versions of the Analytics Service.
+func (l *Link) UnmarshalJSON(data []byte) error {
+	type overlay struct {
+		Scope             string     `json:"scope"`
+		Dataverse         string     `json:"dataverse"`
+		Name              string     `json:"name"`
+		Type              LinkType   `json:"type"`
+		Hostname          string     `json:"hostname"`
+		BootstrapHostname string     `json:"bootstrapHostname"`
+		Encryption        Encryption `json:"encryption"`
+		Username          string     `json:"username"`
+		Certificate       string     `json:"certificate"`
+		ClientCertificate string     `json:"clientCertificate"`
+		AccessKeyID       string     `json:"accessKeyId"`
+		Region            string     `json:"region"`
+		ServiceEndpoint   string     `json:"serviceEndpoint"`
+	}
+
+	var decoded overlay
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	*l = Link{
+		Scope:             decoded.Scope,
+		Name:              decoded.Name,
+		Type:              decoded.Type,
+		Hostname:          decoded.Hostname,
+		Encryption:        decoded.Encryption,
+		Username:          decoded.Username,
+		Certificate:       decoded.Certificate,
+		ClientCertificate: decoded.ClientCertificate,
+		AccessKeyID:       decoded.AccessKeyID,
+		Region:            decoded.Region,
+		ServiceEndpoint:   decoded.ServiceEndpoint,
+	}
+
+	if l.Scope == "" {
+		l.Scope = decoded.Dataverse
+	}
+
+	if l.Hostname == "" {
+		l.Hostname = decoded.BootstrapHostname
+	}
+
+	return nil
+}
+
+// values returns the form encoded values used to create/update the link.
+func (l *Link) values() url.Values {
+	values := url.Values{"type": {string(l.Type)}}
+
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	set("hostname", l.Hostname)
+	set("encryption", string(l.Encryption))
+	set("username", l.Username)
+	set("password", l.Password)
+	set("certificate", l.Certificate)
+	set("clientCertificate", l.ClientCertificate)
+	set("clientKey", l.ClientKey)
+	set("accessKeyId", l.AccessKeyID)
+	set("secretAccessKey", l.SecretAccessKey)
+	set("sessionToken", l.SessionToken)
+	set("region", l.Region)
+	set("serviceEndpoint", l.ServiceEndpoint)
+
+	return values
+}
+
+// ListLinks returns the analytics links in the given scope, or all the links if no scope is provided.
+func (c *Client) ListLinks(ctx context.Context, scope string) ([]*Link, error) {
+	endpoint := EndpointLinks
+	if scope != "" {
+		endpoint = linkEndpoint(scope)
+	}
+
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           endpoint,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            cbrest.ServiceAnalytics,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	links := make([]*Link, 0)
+
+	err = json.Unmarshal(response.Body, &links)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return links, nil
+}
+
+// CreateLink creates the given analytics link.
+func (c *Client) CreateLink(ctx context.Context, link *Link) error {
+	if link.Type == "" {
+		return ErrLinkTypeRequired
+	}
+
+	if link.Name == "" {
+		return ErrLinkNameRequired
+	}
+
+	if slices.Contains(strings.Split(link.Scope, "/"), "") {
+		return ErrLinkScopeRequired
+	}
+
+	request := &cbrest.Request{
+		Body:               []byte(link.values().Encode()),
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           linkEndpoint(link.Scope, link.Name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            cbrest.ServiceAnalytics,
+	}
+
+	_, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	return nil
+}
+
+// linkEndpoint returns the endpoint for the given scope/link, each part of a multi-part scope name is a separate path
+// segment.
+func linkEndpoint(scope string, name ...string) cbrest.Endpoint {
+	parts := append(strings.Split(scope, "/"), name...)
+
+	escaped := make([]string, 0, len(parts))
+	for _, part := range parts {
+		escaped = append(escaped, url.PathEscape(part))
+	}
+
+	return cbrest.Endpoint(string(EndpointLinks) + "/" + strings.Join(escaped, "/"))
+}
@@ -0,0 +1,74 @@
+package analyticsutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+func TestLinkUnmarshalJSON(t *testing.T) {
+	type test struct {
+		name     string
+		data     string
+		expected *Link
+	}
+
+	tests := []*test{
+		{
+			name: "Couchbase",
+			data: `{"scope":"Default","name":"remote","type":"couchbase","bootstrapHostname":"host:8091",` +
+				`"activeHostname":"host:8091","encryption":"half","username":"admin",` +
+				`"password":"<redacted sensitive entry>","uuid":"uuid"}`,
+			expected: &Link{
+				Scope:      "Default",
+				Name:       "remote",
+				Type:       LinkTypeCouchbase,
+				Hostname:   "host:8091",
+				Encryption: EncryptionHalf,
+				Username:   "admin",
+			},
+		},
+		{
+			name: "S3",
+			data: `{"dataverse":"bucket/scope","name":"s3","type":"s3","accessKeyId":"key","region":"us-east-1",` +
+				`"secretAccessKey":"<redacted sensitive entry>"}`,
+			expected: &Link{
+				Scope:       "bucket/scope",
+				Name:        "s3",
+				Type:        LinkTypeS3,
+				AccessKeyID: "key",
+				Region:      "us-east-1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var link *Link
+
+			require.NoError(t, json.Unmarshal([]byte(test.data), &link))
+			require.Equal(t, test.expected, link)
+		})
+	}
+}
+
+func TestLinkValues(t *testing.T) {
+	link := &Link{
+		Scope:           "Default",
+		Name:            "s3",
+		Type:            LinkTypeS3,
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		Region:          "us-east-1",
+	}
+
+	require.Equal(t, "accessKeyId=key&region=us-east-1&secretAccessKey=secret&type=s3", link.values().Encode())
+}
+
+func TestLinkEndpoint(t *testing.T) {
+	require.Equal(t, cbrest.Endpoint("/analytics/link/Default"), linkEndpoint("Default"))
+	require.Equal(t, cbrest.Endpoint("/analytics/link/bucket/sc%20ope/link"), linkEndpoint("bucket/sc ope", "link"))
+}
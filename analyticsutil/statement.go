@@ -0,0 +1,77 @@
+package analyticsutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/couchbase/tools-common/cbvalue"
+)
+
+// quoteIdentifier returns the given identifier escaped using backticks.
+func quoteIdentifier(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+// quoteScope returns the given analytics scope name escaped using backticks, multi-part scope names (which are
+// separated by a '/') are converted to their dotted form e.g. 'bucket/scope' becomes `bucket`.`scope`.
+func quoteScope(scope string) string {
+	parts := strings.Split(scope, "/")
+
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		quoted = append(quoted, quoteIdentifier(part))
+	}
+
+	return strings.Join(quoted, ".")
+}
+
+// withDefault returns the given name, or the default if it's empty.
+func withDefault(name, def string) string {
+	if name == "" {
+		return def
+	}
+
+	return name
+}
+
+// ifNotExists returns the 'IF NOT EXISTS' clause when required.
+func ifNotExists(ignoreIfExists bool) string {
+	if ignoreIfExists {
+		return " IF NOT EXISTS"
+	}
+
+	return ""
+}
+
+// createScopeStatement returns the statement which will create the given analytics scope.
+func createScopeStatement(scope string, ignoreIfExists bool) string {
+	return fmt.Sprintf("CREATE DATAVERSE %s%s", quoteScope(scope), ifNotExists(ignoreIfExists))
+}
+
+// createDatasetStatement returns the statement which will create the given dataset.
+func createDatasetStatement(definition DatasetDefinition) string {
+	var statement strings.Builder
+
+	statement.WriteString("CREATE DATASET" + ifNotExists(definition.IgnoreIfExists) + " ")
+
+	if definition.Scope != "" {
+		statement.WriteString(quoteScope(definition.Scope) + ".")
+	}
+
+	statement.WriteString(quoteIdentifier(definition.Name) + " ON " + quoteIdentifier(definition.Bucket))
+
+	if definition.BucketScope != "" || definition.BucketCollection != "" {
+		statement.WriteString("." + quoteIdentifier(withDefault(definition.BucketScope, cbvalue.DefaultScope)) + "." +
+			quoteIdentifier(withDefault(definition.BucketCollection, cbvalue.DefaultCollection)))
+	}
+
+	if definition.Link != "" {
+		statement.WriteString(" AT " + quoteIdentifier(definition.Link))
+	}
+
+	if definition.Where != "" {
+		statement.WriteString(" WHERE " + definition.Where)
+	}
+
+	return statement.String()
+}
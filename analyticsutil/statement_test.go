@@ -0,0 +1,54 @@
+package analyticsutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateScopeStatement(t *testing.T) {
+	require.Equal(t, "CREATE DATAVERSE `scope`", createScopeStatement("scope", false))
+	require.Equal(t, "CREATE DATAVERSE `bucket`.`scope` IF NOT EXISTS", createScopeStatement("bucket/scope", true))
+}
+
+func TestCreateDatasetStatement(t *testing.T) {
+	type test struct {
+		name       string
+		definition DatasetDefinition
+		expected   string
+	}
+
+	tests := []*test{
+		{
+			name:       "Bucket",
+			definition: DatasetDefinition{Name: "ds", Bucket: "default"},
+			expected:   "CREATE DATASET `ds` ON `default`",
+		},
+		{
+			name: "Collection",
+			definition: DatasetDefinition{
+				Scope:            "bucket/scope",
+				Name:             "ds",
+				Bucket:           "default",
+				BucketScope:      "s1",
+				BucketCollection: "c1",
+				Link:             "remote",
+				Where:            "`type` = \"beer\"",
+				IgnoreIfExists:   true,
+			},
+			expected: "CREATE DATASET IF NOT EXISTS `bucket`.`scope`.`ds` ON `default`.`s1`.`c1` AT `remote` " +
+				"WHERE `type` = \"beer\"",
+		},
+		{
+			name:       "DefaultCollection",
+			definition: DatasetDefinition{Name: "ds", Bucket: "default", BucketScope: "s1"},
+			expected:   "CREATE DATASET `ds` ON `default`.`s1`.`_default`",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, createDatasetStatement(test.definition))
+		})
+	}
+}
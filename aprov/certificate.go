@@ -0,0 +1,140 @@
+package aprov
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/couchbase/tools-common/log"
+	"github.com/couchbase/tools-common/tlsutil"
+)
+
+// ErrCertFileRequired is returned if the user attempts to create a certificate provider without a certificate file.
+var ErrCertFileRequired = errors.New("a client certificate file is required")
+
+// CertificateProvider is implemented by providers which authenticate using a client certificate (mTLS) rather than
+// a username/password; clients should not send basic auth credentials when using a certificate provider.
+type CertificateProvider interface {
+	Provider
+
+	// GetClientCertificate returns the client certificate which should be presented during a TLS handshake, it has the
+	// same signature as the 'GetClientCertificate' attribute of a 'tls.Config'.
+	GetClientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error)
+}
+
+// CertificateOptions encapsulates the options which may be supplied when creating a certificate provider.
+type CertificateOptions struct {
+	UserAgent string
+
+	// CertFile is the path to the client certificate (chain), this may either be a PEM encoded certificate or a PKCS#12
+	// file containing both the certificate and key.
+	//
+	// NOTE: This attribute is required.
+	CertFile string
+
+	// KeyFile is the path to the private key for the client certificate, this is not required when 'CertFile' is a
+	// PKCS#12 file.
+	KeyFile string
+
+	// Password is the password used to decrypt the private key or PKCS#12 file.
+	Password []byte
+}
+
+// Certificate implements the 'CertificateProvider' interface, loading a client certificate from disk.
+//
+// NOTE: The certificate is reloaded during the next TLS handshake after the certificate/key files change, allowing
+// certificates to be rotated without restarting the process; the previous certificate continues to be used if the new
+// one can't be loaded.
+type Certificate struct {
+	userAgent string
+	password  []byte
+
+	lock sync.Mutex
+	cert *tls.Certificate
+
+	// certFile/keyFile are used to detect when the certificate/key have been changed on disk.
+	certFile *watchedFile
+	keyFile  *watchedFile
+}
+
+var _ CertificateProvider = (*Certificate)(nil)
+
+// NewCertificate creates a new certificate provider, returning an error if the certificate/key can't be loaded.
+func NewCertificate(options CertificateOptions) (*Certificate, error) {
+	if options.CertFile == "" {
+		return nil, ErrCertFileRequired
+	}
+
+	provider := &Certificate{
+		userAgent: options.UserAgent,
+		password:  options.Password,
+		certFile:  &watchedFile{path: options.CertFile},
+		keyFile:   &watchedFile{path: options.KeyFile},
+	}
+
+	err := provider.load()
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	return provider, nil
+}
+
+// GetCredentials returns empty credentials, authentication is performed using the client certificate.
+func (c *Certificate) GetCredentials(_ string) (string, string) {
+	return "", ""
+}
+
+func (c *Certificate) GetUserAgent() string {
+	return c.userAgent
+}
+
+// GetClientCertificate returns the current client certificate, reloading it if the certificate/key files have changed.
+func (c *Certificate) GetClientCertificate(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.certFile.changed() && !c.keyFile.changed() {
+		return c.cert, nil
+	}
+
+	err := c.loadLocked()
+	if err != nil {
+		log.Warnf("(aprov) Failed to reload client certificate, continuing to use the previous certificate: %s", err)
+	}
+
+	return c.cert, nil
+}
+
+// load loads the client certificate/key.
+func (c *Certificate) load() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.loadLocked()
+}
+
+// loadLocked loads the client certificate/key using 'tlsutil', which handles PEM, PKCS#8 and PKCS#12 files.
+//
+// NOTE: Expects the lock to be held by the caller.
+func (c *Certificate) loadLocked() error {
+	cert, err := c.certFile.read()
+	if err != nil {
+		return fmt.Errorf("failed to read client certificate: %w", err)
+	}
+
+	key, err := c.keyFile.read()
+	if err != nil {
+		return fmt.Errorf("failed to read client key: %w", err)
+	}
+
+	config, err := tlsutil.NewTLSConfig(tlsutil.TLSConfigOptions{ClientCert: cert, ClientKey: key, Password: c.password})
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	c.cert = &config.Certificates[0]
+
+	return nil
+}
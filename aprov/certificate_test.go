@@ -0,0 +1,100 @@
+package aprov
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/testutil"
+)
+
+// writeCertificate writes a newly generated certificate/key with the given common name to the given files, ensuring the
+// modification time changes so that the change is detected.
+func writeCertificate(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	cert, key := testutil.GenerateCertificate(t, commonName)
+
+	require.NoError(t, os.WriteFile(certFile, cert, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func TestNewCertificate(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+	)
+
+	writeCertificate(t, certFile, keyFile, "client", time.Now())
+
+	provider, err := NewCertificate(CertificateOptions{UserAgent: "agent", CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	require.Equal(t, "agent", provider.GetUserAgent())
+
+	username, password := provider.GetCredentials("")
+	require.Zero(t, username)
+	require.Zero(t, password)
+
+	cert, err := provider.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "client", cert.Leaf.Subject.CommonName)
+}
+
+func TestNewCertificatePKCS12(t *testing.T) {
+	provider, err := NewCertificate(CertificateOptions{
+		CertFile: filepath.Join("testdata", "valid_cert_and_key.p12"),
+		Password: []byte("asdasd"),
+	})
+	require.NoError(t, err)
+
+	cert, err := provider.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.NotNil(t, cert.Leaf)
+	require.NotNil(t, cert.PrivateKey)
+}
+
+func TestNewCertificateErrors(t *testing.T) {
+	_, err := NewCertificate(CertificateOptions{})
+	require.ErrorIs(t, err, ErrCertFileRequired)
+
+	_, err = NewCertificate(CertificateOptions{CertFile: filepath.Join(t.TempDir(), "missing.pem")})
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = NewCertificate(CertificateOptions{
+		CertFile: filepath.Join("testdata", "valid_cert_and_key.p12"),
+		Password: []byte("not-the-password"),
+	})
+	require.Error(t, err)
+}
+
+func TestCertificateReload(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+		now      = time.Now()
+	)
+
+	writeCertificate(t, certFile, keyFile, "first", now)
+
+	provider, err := NewCertificate(CertificateOptions{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+
+	writeCertificate(t, certFile, keyFile, "second", now.Add(time.Minute))
+
+	cert, err := provider.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "second", cert.Leaf.Subject.CommonName)
+
+	// The previous certificate should continue to be used if the new one is invalid
+	require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0o600))
+	require.NoError(t, os.Chtimes(certFile, now.Add(2*time.Minute), now.Add(2*time.Minute)))
+
+	cert, err = provider.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "second", cert.Leaf.Subject.CommonName)
+}
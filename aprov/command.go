@@ -0,0 +1,137 @@
+package aprov
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbase/tools-common/log"
+)
+
+// DefaultCommandTimeout is the default maximum amount of time a credential helper may run for.
+const DefaultCommandTimeout = 30 * time.Second
+
+// ErrCommandRequired is returned if the user attempts to create a command provider without a command.
+var ErrCommandRequired = errors.New("a credential helper command is required")
+
+// CommandOptions encapsulates the options which may be supplied when creating a command provider.
+type CommandOptions struct {
+	UserAgent string
+
+	// Command is the credential helper which will be run to fetch credentials, it must write the credentials to stdout
+	// in the form '{"username":"...","password":"..."}' and exit with a zero status code.
+	//
+	// NOTE: This attribute is required.
+	Command string
+
+	// Args are the arguments passed to the credential helper.
+	Args []string
+
+	// TTL is the duration for which the credentials are cached, once expired, the credential helper will be run again
+	// the next time the credentials are requested. A zero value means the credentials are cached until the provider is
+	// explicitly refreshed.
+	TTL time.Duration
+
+	// Timeout is the maximum amount of time the credential helper may run for, defaults to 'DefaultCommandTimeout'.
+	Timeout time.Duration
+}
+
+// Command implements the 'RefreshableProvider' interface, fetching credentials by running an external credential
+// helper.
+//
+// NOTE: When the credential helper fails after the TTL expires, the previous credentials continue to be used until the
+// TTL expires again, at which point the credential helper will be retried.
+type Command struct {
+	options CommandOptions
+
+	lock        sync.Mutex
+	credentials credentials
+	expires     time.Time
+}
+
+var _ RefreshableProvider = (*Command)(nil)
+
+// NewCommand creates a new command provider, returning an error if the credential helper fails.
+func NewCommand(options CommandOptions) (*Command, error) {
+	if options.Command == "" {
+		return nil, ErrCommandRequired
+	}
+
+	if options.Timeout == 0 {
+		options.Timeout = DefaultCommandTimeout
+	}
+
+	provider := &Command{options: options}
+
+	err := provider.Refresh()
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	return provider, nil
+}
+
+// GetCredentials returns the cached credentials, running the credential helper if they've expired.
+func (c *Command) GetCredentials(_ string) (string, string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.options.TTL == 0 || time.Now().Before(c.expires) {
+		return c.credentials.Username, c.credentials.Password
+	}
+
+	err := c.runLocked()
+	if err != nil {
+		log.Warnf("(aprov) Failed to run credential helper, continuing to use the previous credentials: %s", err)
+
+		// Don't run the credential helper again until the TTL expires, otherwise a failing (or hanging) credential helper
+		// would be run, whilst holding the lock, for every request.
+		c.expires = time.Now().Add(c.options.TTL)
+	}
+
+	return c.credentials.Username, c.credentials.Password
+}
+
+func (c *Command) GetUserAgent() string {
+	return c.options.UserAgent
+}
+
+// Refresh forces the credential helper to be run, regardless of whether the cached credentials have expired.
+func (c *Command) Refresh() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.runLocked()
+}
+
+// runLocked runs the credential helper, caching the returned credentials.
+//
+// NOTE: Expects the lock to be held by the caller.
+func (c *Command) runLocked() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.options.Timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, c.options.Command, c.options.Args...).Output()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) != 0 {
+		return fmt.Errorf("failed to run credential helper: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to run credential helper: %w", err)
+	}
+
+	creds, err := parseCredentials(output)
+	if err != nil {
+		return fmt.Errorf("failed to parse credential helper output: %w", err)
+	}
+
+	c.credentials, c.expires = creds, time.Now().Add(c.options.TTL)
+
+	return nil
+}
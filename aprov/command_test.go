@@ -0,0 +1,134 @@
+package aprov
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCommandHelperProcess isn't a real test, it's run as the credential helper by the command provider tests and
+// writes the contents of the file pointed to by 'CB_TEST_APROV_CREDENTIALS' to stdout. Each run is recorded by appending
+// to the file pointed to by 'CB_TEST_APROV_RUNS' (if set).
+func TestCommandHelperProcess(t *testing.T) {
+	if os.Getenv("CB_TEST_APROV_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	if runs := os.Getenv("CB_TEST_APROV_RUNS"); runs != "" {
+		file, err := os.OpenFile(runs, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, _ = file.Write([]byte{'.'})
+			_ = file.Close()
+		}
+	}
+
+	data, err := os.ReadFile(os.Getenv("CB_TEST_APROV_CREDENTIALS"))
+	if err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Print(string(data))
+	os.Exit(0)
+}
+
+// newTestCommand returns a command provider which runs the test helper process as its credential helper, returning the
+// path to the file containing the credentials it will return.
+func newTestCommand(t *testing.T, ttl time.Duration) (*Command, string) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"username":"username","password":"password"}`), 0o600))
+
+	t.Setenv("CB_TEST_APROV_HELPER_PROCESS", "1")
+	t.Setenv("CB_TEST_APROV_CREDENTIALS", path)
+
+	provider, err := NewCommand(CommandOptions{
+		UserAgent: "agent",
+		Command:   os.Args[0],
+		Args:      []string{"-test.run=TestCommandHelperProcess"},
+		TTL:       ttl,
+	})
+	require.NoError(t, err)
+
+	return provider, path
+}
+
+func TestNewCommand(t *testing.T) {
+	provider, _ := newTestCommand(t, 0)
+	require.Equal(t, "agent", provider.GetUserAgent())
+
+	username, password := provider.GetCredentials("")
+	require.Equal(t, "username", username)
+	require.Equal(t, "password", password)
+}
+
+func TestNewCommandErrors(t *testing.T) {
+	_, err := NewCommand(CommandOptions{})
+	require.ErrorIs(t, err, ErrCommandRequired)
+
+	t.Setenv("CB_TEST_APROV_HELPER_PROCESS", "1")
+	t.Setenv("CB_TEST_APROV_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+
+	_, err = NewCommand(CommandOptions{Command: os.Args[0], Args: []string{"-test.run=TestCommandHelperProcess"}})
+	require.ErrorContains(t, err, "no such file or directory")
+}
+
+func TestCommandCachedUntilRefreshed(t *testing.T) {
+	provider, path := newTestCommand(t, 0)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"username":"username","password":"rotated"}`), 0o600))
+
+	_, password := provider.GetCredentials("")
+	require.Equal(t, "password", password)
+
+	require.NoError(t, provider.Refresh())
+
+	_, password = provider.GetCredentials("")
+	require.Equal(t, "rotated", password)
+}
+
+func TestCommandTTL(t *testing.T) {
+	provider, path := newTestCommand(t, 50*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"username":"username","password":"rotated"}`), 0o600))
+
+	_, password := provider.GetCredentials("")
+	require.Equal(t, "password", password)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, password = provider.GetCredentials("")
+	require.Equal(t, "rotated", password)
+
+	// A failing credential helper should result in the previous credentials being used
+	require.NoError(t, os.Remove(path))
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, password = provider.GetCredentials("")
+	require.Equal(t, "rotated", password)
+}
+
+func TestCommandFailingHelperNotRetriedUntilTTLExpires(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+	t.Setenv("CB_TEST_APROV_RUNS", runs)
+
+	provider, path := newTestCommand(t, 100*time.Millisecond)
+
+	require.NoError(t, os.Remove(path))
+
+	time.Sleep(150 * time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		_, password := provider.GetCredentials("")
+		require.Equal(t, "password", password)
+	}
+
+	// The helper is run once when creating the provider, and once more after the TTL expires
+	data, err := os.ReadFile(runs)
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+}
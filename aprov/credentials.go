@@ -0,0 +1,33 @@
+package aprov
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUsernameRequired is returned when credentials loaded from an external source don't contain a username.
+var ErrUsernameRequired = errors.New("credentials must contain a username")
+
+// credentials is the JSON representation of credentials loaded from an external source e.g. a file or a credential
+// helper.
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// parseCredentials parses the given JSON encoded credentials.
+func parseCredentials(data []byte) (credentials, error) {
+	var creds credentials
+
+	err := json.Unmarshal(data, &creds)
+	if err != nil {
+		return credentials{}, fmt.Errorf("failed to unmarshal credentials: %w", err)
+	}
+
+	if creds.Username == "" {
+		return credentials{}, ErrUsernameRequired
+	}
+
+	return creds, nil
+}
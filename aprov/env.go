@@ -0,0 +1,45 @@
+package aprov
+
+import "github.com/couchbase/tools-common/envvar"
+
+const (
+	// DefaultUsernameVar is the environment variable used by the 'Env' provider when no username variable is supplied.
+	DefaultUsernameVar = "CB_USERNAME"
+
+	// DefaultPasswordVar is the environment variable used by the 'Env' provider when no password variable is supplied.
+	DefaultPasswordVar = "CB_PASSWORD"
+)
+
+// Env implements the 'Provider' interface, reading credentials from environment variables.
+//
+// NOTE: The environment variables are read each time the credentials are requested, so changes made to the environment
+// of the running process are picked up immediately.
+type Env struct {
+	UserAgent string
+
+	// UsernameVar/PasswordVar are the names of the environment variables containing the username/password, they
+	// default to 'CB_USERNAME' and 'CB_PASSWORD' respectively.
+	UsernameVar, PasswordVar string
+}
+
+var _ Provider = (*Env)(nil)
+
+func (e *Env) GetCredentials(_ string) (string, string) {
+	username, _ := envvar.GetString(valueOrDefault(e.UsernameVar, DefaultUsernameVar))
+	password, _ := envvar.GetString(valueOrDefault(e.PasswordVar, DefaultPasswordVar))
+
+	return username, password
+}
+
+func (e *Env) GetUserAgent() string {
+	return e.UserAgent
+}
+
+// valueOrDefault returns the given value, or the default if it's empty.
+func valueOrDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+
+	return value
+}
@@ -0,0 +1,36 @@
+package aprov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvGetCredentials(t *testing.T) {
+	t.Setenv(DefaultUsernameVar, "username")
+	t.Setenv(DefaultPasswordVar, "password")
+
+	provider := &Env{UserAgent: "agent"}
+
+	username, password := provider.GetCredentials("")
+	require.Equal(t, "username", username)
+	require.Equal(t, "password", password)
+	require.Equal(t, "agent", provider.GetUserAgent())
+
+	// Changes to the environment should be picked up immediately
+	t.Setenv(DefaultPasswordVar, "rotated")
+
+	_, password = provider.GetCredentials("")
+	require.Equal(t, "rotated", password)
+}
+
+func TestEnvGetCredentialsCustomVars(t *testing.T) {
+	t.Setenv("CB_TEST_APROV_USERNAME", "username")
+	t.Setenv("CB_TEST_APROV_PASSWORD", "password")
+
+	provider := &Env{UsernameVar: "CB_TEST_APROV_USERNAME", PasswordVar: "CB_TEST_APROV_PASSWORD"}
+
+	username, password := provider.GetCredentials("")
+	require.Equal(t, "username", username)
+	require.Equal(t, "password", password)
+}
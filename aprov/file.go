@@ -0,0 +1,146 @@
+package aprov
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/couchbase/tools-common/log"
+)
+
+// ErrCredentialsFileRequired is returned if the user attempts to create a file provider without a credentials file.
+var ErrCredentialsFileRequired = errors.New("a credentials file is required")
+
+// watchedFile tracks the modification time/size of a file, so that we can detect when it has been changed.
+type watchedFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// changed returns a boolean indicating whether the file has changed since it was last loaded.
+func (w *watchedFile) changed() bool {
+	if w.path == "" {
+		return false
+	}
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+
+	return !info.ModTime().Equal(w.modTime) || info.Size() != w.size
+}
+
+// read reads the file, recording its modification time/size.
+func (w *watchedFile) read() ([]byte, error) {
+	if w.path == "" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	w.modTime, w.size = info.ModTime(), info.Size()
+
+	return data, nil
+}
+
+// FileOptions encapsulates the options which may be supplied when creating a file provider.
+type FileOptions struct {
+	UserAgent string
+
+	// Path is the path to a JSON file containing the credentials in the form '{"username":"...","password":"..."}'.
+	//
+	// NOTE: This attribute is required.
+	Path string
+}
+
+// File implements the 'RefreshableProvider' interface, reading credentials from a file on disk.
+//
+// NOTE: The credentials are reloaded the next time they're requested after the file changes, allowing credentials to
+// be rotated without restarting the process; the previous credentials continue to be used if the new ones can't be
+// loaded.
+type File struct {
+	userAgent string
+
+	lock        sync.Mutex
+	file        *watchedFile
+	credentials credentials
+}
+
+var _ RefreshableProvider = (*File)(nil)
+
+// NewFile creates a new file provider, returning an error if the credentials can't be loaded.
+func NewFile(options FileOptions) (*File, error) {
+	if options.Path == "" {
+		return nil, ErrCredentialsFileRequired
+	}
+
+	provider := &File{userAgent: options.UserAgent, file: &watchedFile{path: options.Path}}
+
+	err := provider.Refresh()
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	return provider, nil
+}
+
+// GetCredentials returns the current credentials, reloading them if the credentials file has changed.
+func (f *File) GetCredentials(_ string) (string, string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.file.changed() {
+		return f.credentials.Username, f.credentials.Password
+	}
+
+	err := f.loadLocked()
+	if err != nil {
+		log.Warnf("(aprov) Failed to reload credentials from '%s', continuing to use the previous credentials: %s",
+			f.file.path, err)
+	}
+
+	return f.credentials.Username, f.credentials.Password
+}
+
+func (f *File) GetUserAgent() string {
+	return f.userAgent
+}
+
+// Refresh forces the credentials to be reloaded from disk.
+func (f *File) Refresh() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.loadLocked()
+}
+
+// loadLocked reads and parses the credentials file.
+//
+// NOTE: Expects the lock to be held by the caller.
+func (f *File) loadLocked() error {
+	data, err := f.file.read()
+	if err != nil {
+		return fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	creds, err := parseCredentials(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+
+	f.credentials = creds
+
+	return nil
+}
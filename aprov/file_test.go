@@ -0,0 +1,91 @@
+package aprov
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeCredentials writes the given credentials to the given file, ensuring the modification time changes so that the
+// change is detected.
+func writeCredentials(t *testing.T, path, data string, modTime time.Time) {
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+
+	writeCredentials(t, path, `{"username":"username","password":"password"}`, time.Now())
+
+	provider, err := NewFile(FileOptions{UserAgent: "agent", Path: path})
+	require.NoError(t, err)
+	require.Equal(t, "agent", provider.GetUserAgent())
+
+	username, password := provider.GetCredentials("")
+	require.Equal(t, "username", username)
+	require.Equal(t, "password", password)
+}
+
+func TestNewFileErrors(t *testing.T) {
+	type test struct {
+		name string
+		data string
+	}
+
+	tests := []*test{
+		{
+			name: "InvalidJSON",
+			data: "username:password",
+		},
+		{
+			name: "MissingUsername",
+			data: `{"password":"password"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "credentials.json")
+
+			writeCredentials(t, path, test.data, time.Now())
+
+			_, err := NewFile(FileOptions{Path: path})
+			require.Error(t, err)
+		})
+	}
+
+	_, err := NewFile(FileOptions{})
+	require.ErrorIs(t, err, ErrCredentialsFileRequired)
+
+	_, err = NewFile(FileOptions{Path: filepath.Join(t.TempDir(), "missing.json")})
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFileReloadOnChange(t *testing.T) {
+	var (
+		path = filepath.Join(t.TempDir(), "credentials.json")
+		now  = time.Now()
+	)
+
+	writeCredentials(t, path, `{"username":"username","password":"password"}`, now)
+
+	provider, err := NewFile(FileOptions{Path: path})
+	require.NoError(t, err)
+
+	writeCredentials(t, path, `{"username":"username","password":"rotated"}`, now.Add(time.Second))
+
+	_, password := provider.GetCredentials("")
+	require.Equal(t, "rotated", password)
+
+	// An invalid file should result in the previous credentials being used
+	writeCredentials(t, path, `{"username":`, now.Add(2*time.Second))
+
+	_, password = provider.GetCredentials("")
+	require.Equal(t, "rotated", password)
+
+	require.Error(t, provider.Refresh())
+}
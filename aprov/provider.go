@@ -6,3 +6,13 @@ type Provider interface {
 	GetCredentials(host string) (string, string)
 	GetUserAgent() string
 }
+
+// RefreshableProvider is implemented by providers whose credentials may change during the lifetime of the process, for
+// example, because they're rotated by an external system.
+type RefreshableProvider interface {
+	Provider
+
+	// Refresh forces the provider to reload its credentials, clients should call this function when the credentials
+	// returned by the provider have been rejected.
+	Refresh() error
+}
@@ -0,0 +1,98 @@
+package cbrest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ExecuteOnAllNodesOptions encapsulates the options which may be supplied when executing a request on all the nodes
+// running a service.
+type ExecuteOnAllNodesOptions struct {
+	// FailFast cancels any outstanding requests, and returns an error as soon as the request fails on any node; by
+	// default, the request is executed to completion on every node and the errors are returned in the results.
+	FailFast bool
+}
+
+// NodeResult is the result of executing a request against a single node.
+type NodeResult struct {
+	// Host is the fully qualified hostname with scheme and port of the node.
+	Host string
+
+	// Response is the response returned by the node, this may be non-nil when the request failed, for example, when
+	// the node responded with an unexpected status code.
+	Response *Response
+
+	// Error is the error returned when executing the request against the node.
+	Error error
+}
+
+// NodeResults is a readability wrapper around a slice of per-node results.
+type NodeResults []*NodeResult
+
+// Failed returns the results for the nodes where the request failed.
+func (n NodeResults) Failed() NodeResults {
+	failed := make(NodeResults, 0)
+
+	for _, result := range n {
+		if result.Error != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// ExecuteOnAllNodes executes the given request in parallel against every node running the requested service, returning
+// a result for each node in the same order as 'GetAllServiceHosts'. This is useful for requests which return node local
+// information e.g. statistics/logs.
+//
+// NOTE: By default, a nil error will be returned when the request fails on one or more nodes; the per-node errors
+// should be checked using the returned results. The request is retried on the same node, and is never dispatched to a
+// different node.
+func (c *Client) ExecuteOnAllNodes(ctx context.Context, request *Request,
+	options ExecuteOnAllNodesOptions,
+) (NodeResults, error) {
+	hosts, err := c.authProvider.GetAllServiceHosts(request.Service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hosts for service '%s': %w", request.Service, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results = make(NodeResults, len(hosts))
+		wg      sync.WaitGroup
+		once    sync.Once
+		failure error
+	)
+
+	for index, host := range hosts {
+		wg.Add(1)
+
+		go func(index int, host string) {
+			defer wg.Done()
+
+			pinned := *request
+			pinned.host = host
+
+			response, err := c.ExecuteWithContext(ctx, &pinned)
+
+			results[index] = &NodeResult{Host: host, Response: response, Error: err}
+
+			if err == nil || !options.FailFast {
+				return
+			}
+
+			once.Do(func() {
+				failure = fmt.Errorf("failed to execute request on node '%s': %w", host, err)
+				cancel()
+			})
+		}(index, host)
+	}
+
+	wg.Wait()
+
+	return results, failure
+}
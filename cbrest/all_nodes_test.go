@@ -0,0 +1,121 @@
+package cbrest
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/testutil"
+)
+
+func TestNodeResultsFailed(t *testing.T) {
+	results := NodeResults{{Host: "a"}, {Host: "b", Error: ErrNodeUninitialized}, {Host: "c"}}
+	require.Equal(t, NodeResults{{Host: "b", Error: ErrNodeUninitialized}}, results.Failed())
+}
+
+func TestClientExecuteOnAllNodes(t *testing.T) {
+	var requests atomic.Int64
+
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, "/test", func(writer http.ResponseWriter, request *http.Request) {
+		// Fail the second request, the remaining requests should still be completed
+		if requests.Add(1) == 2 {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		testutil.Write(t, writer, []byte("body"))
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{}, {}, {}}, Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           "/test",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	results, err := client.ExecuteOnAllNodes(context.Background(), request, ExecuteOnAllNodesOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Len(t, results.Failed(), 1)
+	require.Equal(t, int64(3), requests.Load())
+
+	for _, result := range results {
+		require.Equal(t, cluster.URL(), result.Host)
+
+		if result.Error != nil {
+			require.Equal(t, http.StatusBadRequest, result.Response.StatusCode)
+			continue
+		}
+
+		require.Equal(t, []byte("body"), result.Response.Body)
+	}
+}
+
+func TestClientExecuteOnAllNodesFailFast(t *testing.T) {
+	var requests atomic.Int64
+
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, "/test", func(writer http.ResponseWriter, request *http.Request) {
+		// Fail the first request, the remaining requests should be cancelled
+		if requests.Add(1) == 1 {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		<-request.Context().Done()
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{}, {}, {}}, Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           "/test",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+		Timeout:            -1,
+	}
+
+	results, err := client.ExecuteOnAllNodes(context.Background(), request, ExecuteOnAllNodesOptions{FailFast: true})
+
+	var unexpected *UnexpectedStatusCodeError
+	require.ErrorAs(t, err, &unexpected)
+	require.Len(t, results, 3)
+	require.Len(t, results.Failed(), 3)
+}
+
+func TestClientExecuteOnAllNodesServiceNotAvailable(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           "/test",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceAnalytics,
+	}
+
+	_, err = client.ExecuteOnAllNodes(context.Background(), request, ExecuteOnAllNodesOptions{})
+
+	var notAvailable *ServiceNotAvailableError
+	require.ErrorAs(t, err, &notAvailable)
+}
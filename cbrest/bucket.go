@@ -0,0 +1,375 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// BucketType represents the type of a bucket e.g. a Couchbase bucket.
+type BucketType string
+
+const (
+	// BucketTypeCouchbase is a persistent bucket which supports replication.
+	BucketTypeCouchbase BucketType = "couchbase"
+
+	// BucketTypeEphemeral is an in-memory bucket which supports replication.
+	BucketTypeEphemeral BucketType = "ephemeral"
+
+	// BucketTypeMemcached is a legacy in-memory bucket which does not support replication.
+	BucketTypeMemcached BucketType = "memcached"
+)
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, Couchbase buckets are reported by 'ns_server' using their
+// legacy name 'membase'.
+func (b *BucketType) UnmarshalJSON(data []byte) error {
+	var decoded string
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	if decoded == "membase" {
+		decoded = string(BucketTypeCouchbase)
+	}
+
+	*b = BucketType(decoded)
+
+	return nil
+}
+
+// EvictionPolicy represents the policy used by the data service when ejecting items from memory.
+type EvictionPolicy string
+
+const (
+	// EvictionPolicyValueOnly ejects only values, keeping keys/metadata resident; Couchbase buckets only.
+	EvictionPolicyValueOnly EvictionPolicy = "valueOnly"
+
+	// EvictionPolicyFullEviction ejects keys, metadata and values; Couchbase buckets only.
+	EvictionPolicyFullEviction EvictionPolicy = "fullEviction"
+
+	// EvictionPolicyNoEviction rejects new writes once the quota is reached; Ephemeral buckets only.
+	EvictionPolicyNoEviction EvictionPolicy = "noEviction"
+
+	// EvictionPolicyNRUEviction ejects the not recently used items once the quota is reached; Ephemeral buckets only.
+	EvictionPolicyNRUEviction EvictionPolicy = "nruEviction"
+)
+
+// DurabilityLevel represents the minimum durability level applied to writes to a bucket.
+type DurabilityLevel string
+
+const (
+	// DurabilityLevelNone means writes are acknowledged once they are in memory on the active node.
+	DurabilityLevelNone DurabilityLevel = "none"
+
+	// DurabilityLevelMajority means writes are acknowledged once they are in memory on a majority of nodes.
+	DurabilityLevelMajority DurabilityLevel = "majority"
+
+	// DurabilityLevelMajorityAndPersistActive means writes are acknowledged once they are in memory on a majority of
+	// nodes and persisted on the active node.
+	DurabilityLevelMajorityAndPersistActive DurabilityLevel = "majorityAndPersistActive"
+
+	// DurabilityLevelPersistToMajority means writes are acknowledged once they are persisted on a majority of nodes.
+	DurabilityLevelPersistToMajority DurabilityLevel = "persistToMajority"
+)
+
+// StorageBackend represents the storage engine used by a Couchbase bucket.
+type StorageBackend string
+
+const (
+	// StorageBackendCouchstore is the default storage backend.
+	StorageBackendCouchstore StorageBackend = "couchstore"
+
+	// StorageBackendMagma is the storage backend optimized for data sets which are much larger than memory.
+	StorageBackendMagma StorageBackend = "magma"
+)
+
+// Bucket represents the settings for an existing bucket, as reported by the cluster.
+type Bucket struct {
+	Name               string
+	UUID               string
+	Type               BucketType
+	RAMQuotaMiB        uint64
+	NumReplicas        int
+	EvictionPolicy     EvictionPolicy
+	DurabilityMinLevel DurabilityLevel
+	StorageBackend     StorageBackend
+	FlushEnabled       bool
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, converting the 'ns_server' bucket payload into a 'Bucket'.
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	type overlay struct {
+		Name               string          `json:"name"`
+		UUID               string          `json:"uuid"`
+		Type               BucketType      `json:"bucketType"`
+		NumReplicas        int             `json:"replicaNumber"`
+		EvictionPolicy     EvictionPolicy  `json:"evictionPolicy"`
+		DurabilityMinLevel DurabilityLevel `json:"durabilityMinLevel"`
+		StorageBackend     StorageBackend  `json:"storageBackend"`
+		Quota              struct {
+			RawRAM uint64 `json:"rawRAM"`
+		} `json:"quota"`
+		Controllers struct {
+			Flush string `json:"flush"`
+		} `json:"controllers"`
+	}
+
+	var decoded overlay
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	*b = Bucket{
+		Name:               decoded.Name,
+		UUID:               decoded.UUID,
+		Type:               decoded.Type,
+		RAMQuotaMiB:        decoded.Quota.RawRAM / 1024 / 1024,
+		NumReplicas:        decoded.NumReplicas,
+		EvictionPolicy:     decoded.EvictionPolicy,
+		DurabilityMinLevel: decoded.DurabilityMinLevel,
+		StorageBackend:     decoded.StorageBackend,
+		// The flush controller is only reported when flush is enabled for the bucket
+		FlushEnabled: decoded.Controllers.Flush != "",
+	}
+
+	return nil
+}
+
+// BucketSettings encapsulates the settings which may be supplied when creating/updating a bucket.
+//
+// NOTE: Zero values are not sent to the cluster, meaning the cluster defaults will be used when creating a bucket and
+// the existing values will be retained when updating a bucket.
+type BucketSettings struct {
+	// Name is the name of the bucket.
+	//
+	// NOTE: This attribute is required.
+	Name string
+
+	// Type is the type of the bucket, this may not be changed once the bucket is created.
+	Type BucketType
+
+	// RAMQuotaMiB is the per-node memory quota for the bucket in MiB.
+	RAMQuotaMiB uint64
+
+	// NumReplicas is the number of replicas for the bucket, a pointer is used to allow explicitly disabling replicas.
+	NumReplicas *int
+
+	EvictionPolicy     EvictionPolicy
+	DurabilityMinLevel DurabilityLevel
+
+	// StorageBackend is the storage backend for the bucket, this may not be changed once the bucket is created.
+	StorageBackend StorageBackend
+
+	// FlushEnabled indicates whether the bucket may be flushed, a pointer is used to allow explicitly disabling flush.
+	FlushEnabled *bool
+}
+
+// values returns the settings encoded as form values, the name/type/storage backend are only encoded when creating a
+// bucket since they're immutable.
+func (b BucketSettings) values(create bool) url.Values {
+	values := make(url.Values)
+
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	if create {
+		set("name", b.Name)
+		set("bucketType", string(b.Type))
+		set("storageBackend", string(b.StorageBackend))
+	}
+
+	if b.RAMQuotaMiB != 0 {
+		set("ramQuota", strconv.FormatUint(b.RAMQuotaMiB, 10))
+	}
+
+	if b.NumReplicas != nil {
+		set("replicaNumber", strconv.Itoa(*b.NumReplicas))
+	}
+
+	set("evictionPolicy", string(b.EvictionPolicy))
+	set("durabilityMinLevel", string(b.DurabilityMinLevel))
+
+	if b.FlushEnabled != nil {
+		set("flushEnabled", map[bool]string{false: "0", true: "1"}[*b.FlushEnabled])
+	}
+
+	return values
+}
+
+// ListBuckets returns the settings for all the buckets in the cluster.
+func (c *Client) ListBuckets(ctx context.Context) ([]*Bucket, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBuckets,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var buckets []*Bucket
+
+	err = json.Unmarshal(response.Body, &buckets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// GetBucket returns the settings for the given bucket, a 'BucketNotFoundError' is returned if the bucket does not
+// exist.
+func (c *Client) GetBucket(ctx context.Context, name string) (*Bucket, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucket.Format(name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleBucketError(name, response, err)
+	}
+
+	var bucket *Bucket
+
+	err = json.Unmarshal(response.Body, &bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return bucket, nil
+}
+
+// CreateBucket creates a new bucket using the given settings.
+//
+// NOTE: Bucket creation is asynchronous, the bucket may not be ready to accept data once this function returns.
+func (c *Client) CreateBucket(ctx context.Context, settings BucketSettings) error {
+	request := &Request{
+		Body:               []byte(settings.values(true).Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBuckets,
+		ExpectedStatusCode: http.StatusAccepted,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleBucketError(settings.Name, response, err)
+	}
+
+	return nil
+}
+
+// UpdateBucket updates the settings for an existing bucket, only the non-zero settings will be updated.
+func (c *Client) UpdateBucket(ctx context.Context, settings BucketSettings) error {
+	request := &Request{
+		Body:               []byte(settings.values(false).Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucket.Format(settings.Name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleBucketError(settings.Name, response, err)
+	}
+
+	return nil
+}
+
+// FlushBucket removes all the data from the given bucket, a 'FlushDisabledError' is returned if flush is not enabled.
+func (c *Client) FlushBucket(ctx context.Context, name string) error {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucketFlush.Format(name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleBucketError(name, response, err)
+	}
+
+	return nil
+}
+
+// DeleteBucket deletes the given bucket, a 'BucketNotFoundError' is returned if the bucket does not exist.
+func (c *Client) DeleteBucket(ctx context.Context, name string) error {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucket.Format(name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodDelete,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleBucketError(name, response, err)
+	}
+
+	return nil
+}
+
+// handleBucketError converts the error returned by a bucket management request into a typed error where possible,
+// using the response body returned by 'ns_server'.
+func handleBucketError(name string, response *Response, err error) error {
+	if response == nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return &BucketNotFoundError{name: name}
+	}
+
+	if response.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	type overlay struct {
+		Errors map[string]string `json:"errors"`
+		Error  string            `json:"_"`
+	}
+
+	var decoded overlay
+
+	// Purposefully ignored, in the event that the body isn't in the expected format, we'll return the original error
+	_ = json.Unmarshal(response.Body, &decoded)
+
+	if strings.Contains(strings.ToLower(decoded.Error), "flush is disabled") {
+		return &FlushDisabledError{name: name}
+	}
+
+	if strings.Contains(strings.ToLower(decoded.Errors["name"]), "already exists") {
+		return &BucketExistsError{name: name}
+	}
+
+	if len(decoded.Errors) != 0 {
+		return &BucketSettingsError{name: name, errors: decoded.Errors}
+	}
+
+	return fmt.Errorf("failed to execute request: %w", err)
+}
@@ -0,0 +1,240 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/ptrutil"
+)
+
+func TestBucketSettingsValues(t *testing.T) {
+	type test struct {
+		name     string
+		settings BucketSettings
+		create   bool
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name: "Create",
+			settings: BucketSettings{
+				Name:               "default",
+				Type:               BucketTypeCouchbase,
+				RAMQuotaMiB:        256,
+				NumReplicas:        ptrutil.ToPtr(0),
+				EvictionPolicy:     EvictionPolicyFullEviction,
+				DurabilityMinLevel: DurabilityLevelMajority,
+				StorageBackend:     StorageBackendMagma,
+				FlushEnabled:       ptrutil.ToPtr(true),
+			},
+			create: true,
+			expected: "bucketType=couchbase&durabilityMinLevel=majority&evictionPolicy=fullEviction&flushEnabled=1&" +
+				"name=default&ramQuota=256&replicaNumber=0&storageBackend=magma",
+		},
+		{
+			name: "UpdateIgnoresImmutable",
+			settings: BucketSettings{
+				Name:           "default",
+				Type:           BucketTypeCouchbase,
+				RAMQuotaMiB:    256,
+				StorageBackend: StorageBackendMagma,
+				FlushEnabled:   ptrutil.ToPtr(false),
+			},
+			expected: "flushEnabled=0&ramQuota=256",
+		},
+		{
+			name:     "ZeroValuesOmitted",
+			settings: BucketSettings{Name: "default"},
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.settings.values(test.create).Encode())
+		})
+	}
+}
+
+func TestBucketUnmarshalJSON(t *testing.T) {
+	data := []byte(`{
+  "name": "default",
+  "uuid": "uuid",
+  "bucketType": "membase",
+  "replicaNumber": 2,
+  "evictionPolicy": "valueOnly",
+  "durabilityMinLevel": "persistToMajority",
+  "storageBackend": "couchstore",
+  "quota": {"ram": 536870912, "rawRAM": 268435456},
+  "controllers": {"flush": "/pools/default/buckets/default/controller/doFlush"}
+}`)
+
+	var bucket *Bucket
+
+	require.NoError(t, json.Unmarshal(data, &bucket))
+
+	expected := &Bucket{
+		Name:               "default",
+		UUID:               "uuid",
+		Type:               BucketTypeCouchbase,
+		RAMQuotaMiB:        256,
+		NumReplicas:        2,
+		EvictionPolicy:     EvictionPolicyValueOnly,
+		DurabilityMinLevel: DurabilityLevelPersistToMajority,
+		StorageBackend:     StorageBackendCouchstore,
+		FlushEnabled:       true,
+	}
+
+	require.Equal(t, expected, bucket)
+}
+
+func TestClientBucketManagement(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{
+		Nodes: TestNodes{{}, {}},
+		Buckets: TestBuckets{
+			"existing": {UUID: "uuid", NumVBuckets: 64, RAMQuotaMiB: 100, NumReplicas: 1},
+		},
+	})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	err = client.CreateBucket(ctx, BucketSettings{
+		Name:               "default",
+		Type:               BucketTypeEphemeral,
+		RAMQuotaMiB:        256,
+		NumReplicas:        ptrutil.ToPtr(0),
+		DurabilityMinLevel: DurabilityLevelMajority,
+	})
+	require.NoError(t, err)
+
+	bucket, err := client.GetBucket(ctx, "default")
+	require.NoError(t, err)
+	require.NotEmpty(t, bucket.UUID)
+
+	bucket.UUID = ""
+
+	expected := &Bucket{
+		Name:               "default",
+		Type:               BucketTypeEphemeral,
+		RAMQuotaMiB:        256,
+		NumReplicas:        0,
+		EvictionPolicy:     EvictionPolicyNoEviction,
+		DurabilityMinLevel: DurabilityLevelMajority,
+		StorageBackend:     StorageBackendCouchstore,
+	}
+
+	require.Equal(t, expected, bucket)
+
+	err = client.UpdateBucket(ctx, BucketSettings{Name: "default", RAMQuotaMiB: 512, FlushEnabled: ptrutil.ToPtr(true)})
+	require.NoError(t, err)
+
+	buckets, err := client.ListBuckets(ctx)
+	require.NoError(t, err)
+	require.Len(t, buckets, 2)
+	require.Equal(t, "default", buckets[0].Name)
+	require.Equal(t, uint64(512), buckets[0].RAMQuotaMiB)
+	require.True(t, buckets[0].FlushEnabled)
+	require.Equal(t, "existing", buckets[1].Name)
+	require.Equal(t, BucketTypeCouchbase, buckets[1].Type)
+
+	require.NoError(t, client.FlushBucket(ctx, "default"))
+	require.Equal(t, 1, cluster.options.Buckets["default"].Flushes)
+
+	require.NoError(t, client.DeleteBucket(ctx, "default"))
+
+	_, err = client.GetBucket(ctx, "default")
+	require.True(t, IsBucketNotFound(err))
+}
+
+func TestClientBucketManagementErrors(t *testing.T) {
+	type test struct {
+		name     string
+		fn       func(client *Client) error
+		check    func(t *testing.T, err error)
+		handlers TestHandlers
+	}
+
+	tests := []*test{
+		{
+			name: "CreateExists",
+			fn: func(client *Client) error {
+				return client.CreateBucket(context.Background(), BucketSettings{Name: "existing", RAMQuotaMiB: 100})
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsBucketExists(err)) },
+		},
+		{
+			name: "CreateInvalidSettings",
+			fn: func(client *Client) error {
+				return client.CreateBucket(context.Background(), BucketSettings{
+					Name:        "default",
+					RAMQuotaMiB: 50,
+					NumReplicas: ptrutil.ToPtr(4),
+				})
+			},
+			check: func(t *testing.T, err error) {
+				var settingsErr *BucketSettingsError
+				require.ErrorAs(t, err, &settingsErr)
+				require.Equal(t, map[string]string{
+					"ramQuota":      "RAM quota cannot be less than 100 MiB",
+					"replicaNumber": "Replica number must be in the range 0 to 3.",
+				}, settingsErr.Errors())
+			},
+		},
+		{
+			name: "UpdateNotFound",
+			fn: func(client *Client) error {
+				return client.UpdateBucket(context.Background(), BucketSettings{Name: "default", RAMQuotaMiB: 100})
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsBucketNotFound(err)) },
+		},
+		{
+			name: "FlushDisabled",
+			fn:   func(client *Client) error { return client.FlushBucket(context.Background(), "existing") },
+			check: func(t *testing.T, err error) {
+				var flushErr *FlushDisabledError
+				require.ErrorAs(t, err, &flushErr)
+			},
+		},
+		{
+			name:  "DeleteNotFound",
+			fn:    func(client *Client) error { return client.DeleteBucket(context.Background(), "default") },
+			check: func(t *testing.T, err error) { require.True(t, IsBucketNotFound(err)) },
+		},
+		{
+			name: "UnexpectedStatus",
+			fn:   func(client *Client) error { return client.DeleteBucket(context.Background(), "existing") },
+			check: func(t *testing.T, err error) {
+				var statusErr *UnexpectedStatusCodeError
+				require.ErrorAs(t, err, &statusErr)
+				require.Equal(t, http.StatusBadRequest, statusErr.Status)
+			},
+			handlers: TestHandlers{
+				"DELETE:/pools/default/buckets/existing": NewTestHandler(t, http.StatusBadRequest, []byte("{}")),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := NewTestCluster(t, TestClusterOptions{
+				Buckets:  TestBuckets{"existing": {RAMQuotaMiB: 100}},
+				Handlers: test.handlers,
+			})
+			defer cluster.Close()
+
+			client, err := newTestClient(cluster, true)
+			require.NoError(t, err)
+
+			test.check(t, test.fn(client))
+		})
+	}
+}
@@ -48,12 +48,34 @@ type ClientOptions struct {
 	// client functions to return stale data/attempt to address missing nodes.
 	DisableCCP bool
 
+	// StreamCC causes the client to subscribe to a streaming endpoint to receive cluster config updates as soon as they
+	// happen, rather than periodically polling. The client will fall back to polling if the stream breaks, and will
+	// periodically attempt to re-establish the stream.
+	StreamCC bool
+
+	// StreamCCBucket is the bucket whose terse streaming endpoint should be used when 'StreamCC' is enabled, by default
+	// the '/pools/default/nodeServicesStreaming' endpoint is used.
+	StreamCCBucket string
+
 	// ConnectionMode is the connection mode to use when connecting to the cluster, this may be used to limit how/where
 	// REST requests are dispatched.
 	ConnectionMode ConnectionMode
 
 	// ReqResLogLevel is the level at which to the dispatching and receiving of requests/responses.
 	ReqResLogLevel log.Level
+
+	// HostSelector is used to select which host each request is dispatched to, defaults to 'OrderedHostSelector'.
+	HostSelector HostSelector
+
+	// HostHealth controls when hosts which are repeatedly failing are temporarily ejected, ejected hosts will not be
+	// selected whilst there are other healthy hosts running the required service.
+	//
+	// NOTE: Ejection is disabled by default, set 'HostHealth.FailureThreshold' to enable it.
+	HostHealth HostHealthOptions
+
+	// Recorder records all the requests dispatched by the client, along with their responses; this may be used to
+	// create fixtures which can be replayed in tests using 'NewReplayHandlers'.
+	Recorder *Recorder
 }
 
 // Client is a REST client used to retrieve/send information to/from a Couchbase Cluster.
@@ -69,6 +91,16 @@ type Client struct {
 
 	reqResLogLevel log.Level
 
+	hostSelector HostSelector
+	hosts        *hostTracker
+
+	// refreshLock ensures credentials are only refreshed by a single request at once, see 'refreshCredentials'.
+	refreshLock sync.Mutex
+
+	// ccStreamEndpoint is the endpoint used to stream cluster config updates, an empty endpoint indicates that the
+	// cluster config should be periodically polled.
+	ccStreamEndpoint Endpoint
+
 	wg         sync.WaitGroup
 	ctx        context.Context
 	cancelFunc context.CancelFunc
@@ -179,17 +211,41 @@ func returnBootstrappedClient(options ClientOptions) (*Client, error) {
 		return nil, fmt.Errorf("failed to get timeouts for REST HTTP client: %w", err)
 	}
 
+	tlsConfig, err := newTLSConfig(options, resolved.UseSSL)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
 	authProvider := NewAuthProvider(resolved, options.Provider)
 
+	var transport http.RoundTripper = netutil.NewHTTPTransport(tlsConfig, timeouts)
+	if options.Recorder != nil {
+		transport = options.Recorder.Transport(transport)
+	}
+
 	// Added nil ClusterInfo so that it can be populated later if needed.
 	client := &Client{
-		client:         newHTTPClient(clientTimeout, netutil.NewHTTPTransport(options.TLSConfig, timeouts)),
+		client:         newHTTPClient(clientTimeout, transport),
 		authProvider:   authProvider,
 		connectionMode: options.ConnectionMode,
 		pollTimeout:    pollTimeout,
 		requestRetries: requestRetries,
 		reqResLogLevel: options.ReqResLogLevel,
 		clusterInfo:    &cbvalue.ClusterInfo{},
+		hostSelector:   options.HostSelector,
+		hosts:          newHostTracker(options.HostHealth),
+	}
+
+	if client.hostSelector == nil {
+		client.hostSelector = OrderedHostSelector{}
+	}
+
+	if options.StreamCC {
+		client.ccStreamEndpoint = EndpointNodesServicesStreaming
+	}
+
+	if options.StreamCC && options.StreamCCBucket != "" {
+		client.ccStreamEndpoint = EndpointBucketStreaming.Format(options.StreamCCBucket)
 	}
 
 	err = client.bootstrap()
@@ -200,6 +256,28 @@ func returnBootstrappedClient(options ClientOptions) (*Client, error) {
 	return client, nil
 }
 
+// newTLSConfig returns the TLS config which should be used by the client, when using a certificate provider the client
+// certificate is fetched from the provider during each TLS handshake, allowing the provider to rotate certificates.
+func newTLSConfig(options ClientOptions, useSSL bool) (*tls.Config, error) {
+	provider, ok := options.Provider.(aprov.CertificateProvider)
+	if !ok {
+		return options.TLSConfig, nil
+	}
+
+	if !useSSL {
+		return nil, ErrCertificateAuthRequiresTLS
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if options.TLSConfig != nil {
+		config = options.TLSConfig.Clone()
+	}
+
+	config.GetClientCertificate = provider.GetClientCertificate
+
+	return config, nil
+}
+
 // bootstrap attempts to bootstrap the client using the hosts from the given collection string provided by the user.
 func (c *Client) bootstrap() error {
 	// Attempt to bootstrap the HTTP client, internally the auth provider will return the next available bootstrap host
@@ -256,6 +334,11 @@ func (c *Client) beginCCP() {
 	// Allow the proper cleanup of the goroutine when the user calls 'Close'
 	c.ctx, c.cancelFunc = context.WithCancel(context.Background())
 
+	if c.ccStreamEndpoint != "" {
+		go c.streamCC()
+		return
+	}
+
 	// Spin up a goroutine which will periodically update the clients cluster config the client allowing it to
 	// correctly handle dynamic changes to the target cluster; this includes proper handling/detection of
 	// adding/removing nodes.
@@ -280,6 +363,123 @@ func (c *Client) pollCC() {
 	}
 }
 
+// streamCC loops until cancelled, updating the clients cluster config using a streaming endpoint. If the stream breaks
+// we fall back to polling, and attempt to re-establish the stream each time the cluster config expires. This goroutine
+// will be cleaned up after a call to 'Close'.
+func (c *Client) streamCC() {
+	defer c.wg.Done()
+
+	for {
+		err := c.consumeCCStream()
+
+		if c.ctx.Err() != nil {
+			return
+		}
+
+		log.Warnf("(REST) (CCP) Cluster config stream closed, falling back to polling: %v", err)
+
+		if err := c.updateCC(); err != nil {
+			log.Warnf("(REST) Failed to update cluster config, will retry: %v", err)
+		}
+
+		c.authProvider.manager.WaitUntilExpired(c.ctx)
+
+		if c.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// consumeCCStream opens a cluster config stream, updating the clients cluster config with each payload. Blocks until
+// either the stream is closed, or the client is closed.
+func (c *Client) consumeCCStream() error {
+	host, err := c.authProvider.GetServiceHost(ServiceManagement, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get host for service '%s': %w", ServiceManagement, err)
+	}
+
+	address, err := c.resolveHost(host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve host '%s': %w", host, err)
+	}
+
+	// This shouldn't really fail since we should be constructing valid hosts in the auth provider
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse host '%s': %w", address, err)
+	}
+
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           c.ccStreamEndpoint,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+		// The stream must be consumed from the host parsed above, since it's used to populate '$HOST' and missing
+		// hostnames in the streamed cluster configs
+		host: host,
+	}
+
+	stream, err := c.ExecuteStreamWithContext(c.ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	log.Infof("(REST) (CCP) Streaming cluster config updates from endpoint '%s'", request.Endpoint)
+
+	// The stream will send updates as soon as they happen, however, requests may still explicitly request an update
+	// e.g. after receiving a 401 so we must still handle being signaled.
+	signal := c.authProvider.manager.createSignalChannel()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case <-signal:
+			if err := c.updateCC(); err != nil {
+				log.Warnf("(REST) Failed to update cluster config, will retry: %v", err)
+			}
+
+			signal = c.authProvider.manager.createSignalChannel()
+		case response, ok := <-stream:
+			if !ok {
+				return ErrStreamClosed
+			}
+
+			if response.Error != nil {
+				return fmt.Errorf("failed to read from stream: %w", response.Error)
+			}
+
+			c.updateCCFromPayload(parsed.Hostname(), response.Payload)
+		}
+	}
+}
+
+// updateCCFromPayload updates the clients cluster config using a payload received from a cluster config stream.
+func (c *Client) updateCCFromPayload(host string, payload []byte) {
+	config, err := c.unmarshalCC(host, payload)
+	if err != nil {
+		log.Warnf("(REST) (CCP) Failed to unmarshal streamed cluster config: %v", err)
+		return
+	}
+
+	var errOld *OldClusterConfigError
+
+	err = c.authProvider.SetClusterConfig(host, config)
+	if err != nil && !errors.As(err, &errOld) {
+		log.Warnf("(REST) (CCP) Failed to update streamed cluster config: %v", err)
+	}
+}
+
+// SubscribeClusterConfig returns a channel which will receive the cluster config each time the client receives a newer
+// revision, this may be used to react to topology changes e.g. a rebalance. The returned function must be called to
+// unsubscribe, this closes the returned channel.
+//
+// NOTE: Updates are only received when the client is updating its cluster config i.e. when CCP is enabled.
+func (c *Client) SubscribeClusterConfig() (<-chan *ClusterConfig, func()) {
+	return c.authProvider.manager.Subscribe()
+}
+
 // updateCC attempts to update the cluster config using each of the known nodes in the cluster.
 //
 // NOTE: It's possible for this to completely fail if we were unable find a valid config from any node in the cluster.
@@ -614,6 +814,41 @@ func (c *Client) ExecuteStreamWithContext(ctx context.Context, request *Request)
 	return nil, handleResponseError(request.Method, request.Endpoint, resp.StatusCode, body)
 }
 
+// ExecuteReaderWithContext executes the given request using the provided context, returning the response body as a
+// reader rather than reading it into memory. This should be used for large responses which aren't newline delimited,
+// and will be incrementally decoded by the caller, for example the results of an analytics statement.
+//
+// NOTE: The caller is responsible for closing the returned reader. As with streaming requests, the client timeout is
+// disabled, the provided context should be used to control the lifetime of the request.
+func (c *Client) ExecuteReaderWithContext(ctx context.Context, request *Request) (io.ReadCloser, error) {
+	if request.Timeout != -1 && request.Timeout != 0 {
+		return nil, ErrStreamWithTimeout
+	}
+
+	// Use a timeout of -1 to indicate that we want to disable the 'Client.Timeout' since the caller may take a long time
+	// to consume the response body.
+	request.Timeout = -1
+
+	resp, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if resp.StatusCode == request.ExpectedStatusCode {
+		return resp.Body, nil
+	}
+
+	// Received a valid response, but with the wrong status code, ensure we drain and close the response body
+	defer cleanupResp(resp)
+
+	body, err := readBody(request.Method, request.Endpoint, resp.Body, resp.ContentLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return nil, handleResponseError(request.Method, request.Endpoint, resp.StatusCode, body)
+}
+
 // beginStream constructs a stream, and kicks off a goroutine to wait for, and process mutations.
 func (c *Client) beginStream(ctx *retry.Context, request *Request, resp *http.Response) <-chan StreamingResponse {
 	log.Logf(c.reqResLogLevel, "(REST) (Attempt %d) (%s) Beginning stream for endpoint '%s'",
@@ -718,12 +953,17 @@ func (c *Client) Do(ctx context.Context, request *Request) (*http.Response, erro
 		Cleanup:     cleanup,
 	})
 
+	// Credentials are only refreshed once per request, if the refreshed credentials are also rejected it's unlikely
+	// that refreshing them again will help.
+	var refreshed bool
+
 	payload, err := retryer.DoWithContext(
 		ctx,
-		func(ctx *retry.Context) (any, error) { return c.do(ctx, request) }, //nolint:bodyclose
+		func(ctx *retry.Context) (any, error) { return c.doWithRefresh(ctx, request, &refreshed) }, //nolint:bodyclose
 	)
 
-	resp := payload.(*http.Response)
+	// The payload may be <nil> in the event that the context was cancelled before the first attempt
+	resp, _ := payload.(*http.Response)
 
 	if err == nil || (resp != nil && resp.StatusCode == request.ExpectedStatusCode) {
 		return resp, err
@@ -820,14 +1060,27 @@ func (c *Client) waitUntilUpdated(ctx context.Context) {
 	}
 }
 
-// do is a convenience which prepares then performs the provided request.
+// do is a convenience which selects a host, then prepares and performs the provided request; the outcome of the request
+// is recorded so that repeatedly failing hosts may be ejected.
 func (c *Client) do(ctx *retry.Context, request *Request) (*http.Response, error) {
-	prep, err := c.prepare(ctx, request)
+	host, err := c.requestHost(request, ctx.Attempt()-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select host: %w", err)
+	}
+
+	prep, err := c.prepare(ctx, request, host)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare request: %w", err)
 	}
 
+	c.hosts.begin(host)
+
+	start := time.Now()
+
 	resp, err := c.perform(ctx, prep, c.reqResLogLevel, request.Timeout)
+
+	c.hosts.end(host, hostFailed(ctx, resp, err), time.Since(start))
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform request: %w", err)
 	}
@@ -835,16 +1088,84 @@ func (c *Client) do(ctx *retry.Context, request *Request) (*http.Response, error
 	return resp, nil
 }
 
-// prepare converts the request into a raw HTTP request which can be dispatched to the cluster. Uses the same context
-// meaning the request timeout is not reset by retries.
-func (c *Client) prepare(ctx *retry.Context, request *Request) (*http.Request, error) {
+// hostFailed returns a boolean indicating whether the outcome of a request indicates that the host is unhealthy.
+//
+// NOTE: Requests which failed because the context was cancelled aren't considered as failures of the host.
+func hostFailed(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+
+	return netutil.IsTemporaryFailure(resp.StatusCode)
+}
+
+// doWithRefresh performs the provided request, if the request fails with a 401 and the auth provider supports
+// refreshing its credentials, they're refreshed and the request is retried once. This allows credentials to be rotated
+// without failing long running operations.
+//
+// NOTE: The request is retried regardless of whether it's idempotent, since it won't have been processed.
+func (c *Client) doWithRefresh(ctx *retry.Context, request *Request, refreshed *bool) (*http.Response, error) {
+	resp, err := c.do(ctx, request)
+	if err != nil || *refreshed || resp.StatusCode != http.StatusUnauthorized ||
+		request.ExpectedStatusCode == http.StatusUnauthorized {
+		return resp, err
+	}
+
+	provider, ok := c.authProvider.provider.(aprov.RefreshableProvider)
+	if !ok {
+		return resp, nil
+	}
+
+	*refreshed = true
+
+	err = c.refreshCredentials(provider, resp.Request)
+	if err != nil {
+		log.Warnf("(REST) (Attempt %d) (%s) Failed to refresh credentials after request to endpoint '%s' was "+
+			"unauthorized: %s", ctx.Attempt(), request.Method, request.Endpoint, err)
+
+		return resp, nil
+	}
+
+	cleanupResp(resp)
+
+	log.Infof("(REST) (Attempt %d) (%s) Refreshed credentials after request to endpoint '%s' was unauthorized, "+
+		"retrying", ctx.Attempt(), request.Method, request.Endpoint)
+
+	return c.do(ctx, request)
+}
+
+// refreshCredentials refreshes the credentials of the given provider after the given request was unauthorized. Concurrent
+// refreshes are coalesced, the credentials aren't refreshed if they've changed since they were used for the request
+// (e.g. because another request was also unauthorized after the credentials were rotated).
+//
+// NOTE: Requests which don't use basic auth (e.g. client certificates) always refresh the credentials.
+func (c *Client) refreshCredentials(provider aprov.RefreshableProvider, req *http.Request) error {
+	c.refreshLock.Lock()
+	defer c.refreshLock.Unlock()
+
+	username, password, ok := req.BasicAuth()
+	if !ok {
+		return provider.Refresh()
+	}
+
+	latestUsername, latestPassword := provider.GetCredentials(req.URL.Scheme + "://" + req.URL.Host)
+	if username != latestUsername || password != latestPassword {
+		return nil
+	}
+
+	return provider.Refresh()
+}
+
+// prepare converts the request into a raw HTTP request which can be dispatched to the given host. Uses the same
+// context meaning the request timeout is not reset by retries.
+func (c *Client) prepare(ctx *retry.Context, request *Request, host string) (*http.Request, error) {
 	// Get the fully qualified address to the node that we are sending this request to
-	host, err := c.serviceHost(request.Service, ctx.Attempt()-1)
+	address, err := c.resolveHost(host)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get host for service '%s': %w", request.Service, err)
+		return nil, fmt.Errorf("failed to resolve host '%s': %w", host, err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, string(request.Method), host+string(request.Endpoint),
+	req, err := http.NewRequestWithContext(ctx, string(request.Method), address+string(request.Endpoint),
 		bytes.NewReader(request.Body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -861,7 +1182,7 @@ func (c *Client) prepare(ctx *retry.Context, request *Request) (*http.Request, e
 		req.Header.Set(key, value)
 	}
 
-	setAuthHeaders(host, c.authProvider, req)
+	setAuthHeaders(address, c.authProvider, req)
 
 	// Set the content type for the request body. Note that we don't default to a value e.g. if must be set for every
 	// request otherwise the string zero value will be used.
@@ -870,6 +1191,30 @@ func (c *Client) prepare(ctx *retry.Context, request *Request) (*http.Request, e
 	return req, nil
 }
 
+// requestHost returns the host that the given request should be dispatched to, requests may be pinned to a specific
+// host, otherwise one is chosen using the host selector.
+func (c *Client) requestHost(request *Request, attempt int) (string, error) {
+	if request.host != "" {
+		return request.host, nil
+	}
+
+	return c.selectHost(request.Service, attempt)
+}
+
+// selectHost uses the host selector to choose the host that a request for the given service should be dispatched to.
+//
+// NOTE: The returned host has not been resolved, and should be passed to 'resolveHost' before being used.
+func (c *Client) selectHost(service Service, attempt int) (string, error) {
+	hosts, err := c.authProvider.GetAllServiceHosts(service)
+	if err != nil {
+		return "", fmt.Errorf("failed to get hosts for service '%s': %w", service, err)
+	}
+
+	candidates := c.hosts.candidates(service, hosts)
+
+	return candidates[c.hostSelector.Select(candidates, attempt)].Host, nil
+}
+
 // serviceHost returns the service host that this request should be dispatched too.
 func (c *Client) serviceHost(service Service, attempt int) (string, error) {
 	host, err := c.authProvider.GetServiceHost(service, attempt)
@@ -877,6 +1222,12 @@ func (c *Client) serviceHost(service Service, attempt int) (string, error) {
 		return "", fmt.Errorf("failed to get host for service '%s': %w", service, err)
 	}
 
+	return c.resolveHost(host)
+}
+
+// resolveHost returns the address that should be used to communicate with the given host, this will be different to
+// the host when using the loopback connection mode.
+func (c *Client) resolveHost(host string) (string, error) {
 	if c.connectionMode != ConnectionModeLoopback {
 		return host, nil
 	}
@@ -924,6 +1275,12 @@ func (c *Client) GetServiceHost(service Service) (string, error) {
 	return c.serviceHost(service, 0)
 }
 
+// HostStats returns the request statistics for each of the hosts tracked by the client, sorted by host; this includes
+// the hosts running the services the client has dispatched requests to, hosts which leave the cluster are removed.
+func (c *Client) HostStats() []HostStats {
+	return c.hosts.snapshot()
+}
+
 // GetAllServiceHosts retrieves a list of all the nodes in the cluster that are running the provided service.
 func (c *Client) GetAllServiceHosts(service Service) ([]string, error) {
 	if !c.connectionMode.ThisNodeOnly() {
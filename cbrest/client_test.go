@@ -8,9 +8,13 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -424,6 +428,264 @@ func TestNewClientTLS(t *testing.T) {
 	require.Equal(t, expected, client.authProvider)
 }
 
+func TestNewClientCertificateAuth(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+	)
+
+	cert, key := testutil.GenerateCertificate(t, "client")
+	require.NoError(t, os.WriteFile(certFile, cert, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+
+	provider, err := aprov.NewCertificate(aprov.CertificateOptions{
+		UserAgent: userAgent,
+		CertFile:  certFile,
+		KeyFile:   keyFile,
+	})
+	require.NoError(t, err)
+
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, "/test", func(writer http.ResponseWriter, request *http.Request) {
+		// Basic auth credentials should not be sent when using certificate authentication
+		_, _, ok := request.BasicAuth()
+		require.False(t, ok)
+
+		require.Len(t, request.TLS.PeerCertificates, 1)
+		require.Equal(t, "client", request.TLS.PeerCertificates[0].Subject.CommonName)
+		require.Equal(t, userAgent, request.UserAgent())
+
+		testutil.Write(t, writer, []byte("body"))
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{
+		Nodes:     TestNodes{{SSL: true}},
+		Handlers:  handlers,
+		TLSConfig: &tls.Config{ClientAuth: tls.RequireAnyClientCert},
+	})
+	defer cluster.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cluster.Certificate())
+
+	client, err := NewClient(ClientOptions{
+		ConnectionString: cluster.URL(),
+		DisableCCP:       true,
+		Provider:         provider,
+		TLSConfig:        &tls.Config{RootCAs: pool},
+	})
+	require.NoError(t, err)
+
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           "/test",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := client.Execute(request)
+	require.NoError(t, err)
+	require.Equal(t, []byte("body"), response.Body)
+}
+
+func TestNewClientCertificateAuthRequiresTLS(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+	)
+
+	cert, key := testutil.GenerateCertificate(t, "client")
+	require.NoError(t, os.WriteFile(certFile, cert, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+
+	provider, err := aprov.NewCertificate(aprov.CertificateOptions{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+
+	cluster := NewTestCluster(t, TestClusterOptions{})
+	defer cluster.Close()
+
+	_, err = NewClient(ClientOptions{ConnectionString: cluster.URL(), DisableCCP: true, Provider: provider})
+	require.ErrorIs(t, err, ErrCertificateAuthRequiresTLS)
+}
+
+// refreshableProvider is a provider which uses the next password from the given list each time it's refreshed.
+type refreshableProvider struct {
+	aprov.Static
+	lock      sync.Mutex
+	passwords []string
+	refreshes int
+}
+
+func (r *refreshableProvider) GetCredentials(host string) (string, string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.Static.GetCredentials(host)
+}
+
+func (r *refreshableProvider) Refresh() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.refreshes++
+
+	if len(r.passwords) == 0 {
+		return errors.New("no more passwords")
+	}
+
+	r.Password, r.passwords = r.passwords[0], r.passwords[1:]
+
+	return nil
+}
+
+func TestExecuteRefreshCredentialsOnUnauthorized(t *testing.T) {
+	type test struct {
+		name      string
+		passwords []string
+		success   bool
+	}
+
+	tests := []*test{
+		{
+			name:      "Rotated",
+			passwords: []string{"rotated"},
+			success:   true,
+		},
+		{
+			name:      "OnlyRefreshedOnce",
+			passwords: []string{"wrong", "rotated"},
+		},
+		{
+			name: "RefreshFailed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handlers := make(TestHandlers)
+			handlers.Add(http.MethodPost, "/test", func(writer http.ResponseWriter, request *http.Request) {
+				if _, password, _ := request.BasicAuth(); password != "rotated" {
+					writer.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				testutil.Write(t, writer, []byte("body"))
+			})
+
+			cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+			defer cluster.Close()
+
+			provider := &refreshableProvider{
+				Static:    aprov.Static{Username: username, Password: password, UserAgent: userAgent},
+				passwords: test.passwords,
+			}
+
+			client, err := NewClient(ClientOptions{
+				ConnectionString: cluster.URL(),
+				DisableCCP:       true,
+				Provider:         provider,
+			})
+			require.NoError(t, err)
+
+			// The request isn't idempotent, however, it should still be retried after refreshing the credentials
+			request := &Request{
+				ContentType:        ContentTypeURLEncoded,
+				Endpoint:           "/test",
+				ExpectedStatusCode: http.StatusOK,
+				Method:             http.MethodPost,
+				Service:            ServiceManagement,
+			}
+
+			response, err := client.Execute(request)
+			require.Equal(t, 1, provider.refreshes)
+
+			if !test.success {
+				var authErr *AuthenticationError
+				require.ErrorAs(t, err, &authErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, []byte("body"), response.Body)
+		})
+	}
+}
+
+func TestExecuteRefreshCredentialsCoalesced(t *testing.T) {
+	const requests = 8
+
+	var unauthorized sync.WaitGroup
+
+	unauthorized.Add(requests)
+
+	// Requests using the stale credentials are only rejected once they've all been received, ensuring that they're all
+	// unauthorized concurrently
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodPost, "/test", func(writer http.ResponseWriter, request *http.Request) {
+		if _, password, _ := request.BasicAuth(); password != "rotated" {
+			unauthorized.Done()
+			unauthorized.Wait()
+			writer.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		testutil.Write(t, writer, []byte("body"))
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	provider := &refreshableProvider{
+		Static:    aprov.Static{Username: username, Password: password, UserAgent: userAgent},
+		passwords: []string{"rotated", "other"},
+	}
+
+	client, err := NewClient(ClientOptions{
+		ConnectionString: cluster.URL(),
+		DisableCCP:       true,
+		Provider:         provider,
+	})
+	require.NoError(t, err)
+
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           "/test",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, requests)
+	)
+
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := client.Execute(request)
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 1, provider.refreshes)
+}
+
 func TestNewClientTLSReturnX509Errors(t *testing.T) {
 	// NOTE: These test certificates are simply those as generated by using all the defaults when using OpenSSL, this
 	// results in a 'x509: cannot validate certificate for 127.0.0.1 because it doesn't contain any IP SANs' error.
@@ -1147,6 +1409,46 @@ func TestClientExecuteStream(t *testing.T) {
 	require.Equal(t, 5, responses)
 }
 
+func TestClientExecuteReaderWithContext(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, "/test", NewTestHandler(t, http.StatusOK, []byte(`{"results":[1,2,3]}`)))
+	handlers.Add(http.MethodGet, "/error", NewTestHandler(t, http.StatusBadRequest,
+		[]byte(`{"errors":[{"code":24000,"msg":"syntax error"}]}`)))
+
+	cluster := NewTestCluster(t, TestClusterOptions{
+		Handlers: handlers,
+	})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           "/test",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	reader, err := client.ExecuteReaderWithContext(context.Background(), request)
+	require.NoError(t, err)
+
+	defer reader.Close()
+
+	require.Equal(t, []byte(`{"results":[1,2,3]}`), testutil.ReadAll(t, reader))
+
+	request.Endpoint = "/error"
+
+	_, err = client.ExecuteReaderWithContext(context.Background(), request)
+	require.True(t, IsServiceErrorCode(err, 24000))
+
+	request.Timeout = time.Second
+
+	_, err = client.ExecuteReaderWithContext(context.Background(), request)
+	require.ErrorIs(t, err, ErrStreamWithTimeout)
+}
+
 func TestClientExecuteStreamNoTimeout(t *testing.T) {
 	os.Setenv("CB_REST_CLIENT_TIMEOUT_SECS", "50ms")
 	defer os.Unsetenv("CB_REST_CLIENT_TIMEOUT_SECS")
@@ -2114,3 +2416,152 @@ func TestClientWaitUntilUpdated(t *testing.T) {
 		})
 	}
 }
+
+// newTestStreamingClient returns a client which is bootstrapped against the provided cluster, and which streams cluster
+// config updates.
+func newTestStreamingClient(t *testing.T, cluster *TestCluster, bucket string) *Client {
+	client, err := NewClient(ClientOptions{
+		ConnectionString: cluster.URL(),
+		Provider:         &aprov.Static{Username: username, Password: password, UserAgent: userAgent},
+		StreamCC:         true,
+		StreamCCBucket:   bucket,
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+// waitForRevision waits until a config with (at least) the given revision is received from the given channel.
+func waitForRevision(t *testing.T, configs <-chan *ClusterConfig, revision int64) {
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case config := <-configs:
+			if config.Revision >= revision {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for cluster config revision %d", revision)
+		}
+	}
+}
+
+func TestClientStreamCC(t *testing.T) {
+	for _, bucket := range []string{"", "default"} {
+		t.Run(fmt.Sprintf(`{"bucket":"%s"}`, bucket), func(t *testing.T) {
+			cluster := NewTestCluster(t, TestClusterOptions{Buckets: TestBuckets{"default": {}}})
+			defer cluster.Close()
+
+			client := newTestStreamingClient(t, cluster, bucket)
+			defer client.Close()
+
+			configs, unsubscribe := client.SubscribeClusterConfig()
+			defer unsubscribe()
+
+			for i := 0; i < 3; i++ {
+				cluster.BumpRevision()
+
+				cluster.lock.Lock()
+				revision := cluster.revision
+				cluster.lock.Unlock()
+
+				waitForRevision(t, configs, revision)
+			}
+		})
+	}
+}
+
+// lastHostSelector is a host selector which always selects the last candidate.
+type lastHostSelector struct{}
+
+func (l lastHostSelector) Select(candidates []HostStats, _ int) int {
+	return len(candidates) - 1
+}
+
+func TestClientStreamCCPinnedToHost(t *testing.T) {
+	var (
+		cluster *TestCluster
+		lock    sync.Mutex
+		hosts   []string
+	)
+
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointNodesServicesStreaming), func(writer http.ResponseWriter,
+		request *http.Request,
+	) {
+		lock.Lock()
+		hosts = append(hosts, request.Host)
+		lock.Unlock()
+
+		cluster.NodeServicesStreaming(writer, request)
+	})
+
+	cluster = NewTestCluster(t, TestClusterOptions{
+		SeparateNodeAddresses: true,
+		Nodes:                 TestNodes{{}, {}},
+		Handlers:              handlers,
+	})
+	defer cluster.Close()
+
+	// The stream should be consumed from the host used to populate '$HOST', regardless of the host selector
+	client, err := NewClient(ClientOptions{
+		ConnectionString: cluster.URL(),
+		Provider:         &aprov.Static{Username: username, Password: password, UserAgent: userAgent},
+		StreamCC:         true,
+		HostSelector:     lastHostSelector{},
+	})
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return len(hosts) != 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	expected, err := client.authProvider.GetServiceHost(ServiceManagement, 0)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(expected)
+	require.NoError(t, err)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	require.Equal(t, parsed.Host, hosts[0])
+}
+
+func TestClientStreamCCFallbackToPolling(t *testing.T) {
+	os.Setenv("CB_REST_CC_MAX_AGE", "50ms")
+	defer os.Unsetenv("CB_REST_CC_MAX_AGE")
+
+	var streams uint64
+
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointNodesServicesStreaming), func(writer http.ResponseWriter,
+		request *http.Request,
+	) {
+		atomic.AddUint64(&streams, 1)
+		writer.WriteHeader(http.StatusNotFound)
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	client := newTestStreamingClient(t, cluster, "")
+	defer client.Close()
+
+	configs, unsubscribe := client.SubscribeClusterConfig()
+	defer unsubscribe()
+
+	// The test cluster bumps the revision each time the config is polled
+	cluster.lock.Lock()
+	revision := cluster.revision
+	cluster.lock.Unlock()
+
+	waitForRevision(t, configs, revision+1)
+	require.Eventually(t, func() bool { return atomic.LoadUint64(&streams) >= 2 }, 5*time.Second, 10*time.Millisecond)
+}
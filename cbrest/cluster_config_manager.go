@@ -48,6 +48,10 @@ type ClusterConfigManager struct {
 	cond   *sync.Cond
 	signal chan struct{}
 	once   sync.Once
+
+	// subscribers are notified each time the cluster config is updated to a newer revision, guarded by the condition
+	// variable lock.
+	subscribers map[chan *ClusterConfig]struct{}
 }
 
 // NewClusterConfigManager returns a new cluster config manager which will not immediately trigger a cluster config
@@ -94,14 +98,67 @@ func (c *ClusterConfigManager) Update(config *ClusterConfig) error {
 		return &OldClusterConfigError{old: config.Revision, curr: c.config.Revision}
 	}
 
+	changed := c.config == nil || c.config.Revision < config.Revision
+
 	now := time.Now()
 
 	c.config = config
 	c.last = &now
 
+	if changed {
+		c.notify()
+	}
+
 	return nil
 }
 
+// Subscribe returns a channel which will receive a copy of the cluster config each time it's updated to a newer
+// revision, and a function which should be called to unsubscribe; this closes the returned channel.
+//
+// NOTE: Only the latest config is buffered, subscribers which don't keep up will not see every intermediate revision.
+func (c *ClusterConfigManager) Subscribe() (<-chan *ClusterConfig, func()) {
+	c.cond.L.Lock()
+	defer c.cond.L.Unlock()
+
+	if c.subscribers == nil {
+		c.subscribers = make(map[chan *ClusterConfig]struct{})
+	}
+
+	var (
+		subscriber = make(chan *ClusterConfig, 1)
+		once       sync.Once
+	)
+
+	c.subscribers[subscriber] = struct{}{}
+
+	unsubscribe := func() {
+		once.Do(func() {
+			c.cond.L.Lock()
+			defer c.cond.L.Unlock()
+
+			delete(c.subscribers, subscriber)
+			close(subscriber)
+		})
+	}
+
+	return subscriber, unsubscribe
+}
+
+// notify sends a copy of the current cluster config to all the subscribers, replacing any config which they've not yet
+// received.
+//
+// NOTE: The condition variable lock must be held by the caller.
+func (c *ClusterConfigManager) notify() {
+	for subscriber := range c.subscribers {
+		select {
+		case <-subscriber:
+		default:
+		}
+
+		subscriber <- c.GetClusterConfig()
+	}
+}
+
 // WaitUntilUpdated triggers a config update and then blocks the calling goroutine until the update is complete.
 func (c *ClusterConfigManager) WaitUntilUpdated(ctx context.Context) {
 	signal := make(chan struct{})
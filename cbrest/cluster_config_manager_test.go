@@ -219,3 +219,28 @@ func TestClusterConfigManagerWaitUntilExpiredContextCancel(t *testing.T) {
 
 	require.True(t, woken)
 }
+
+func TestClusterConfigManagerSubscribe(t *testing.T) {
+	manager := NewClusterConfigManager()
+
+	configs, unsubscribe := manager.Subscribe()
+
+	require.NoError(t, manager.Update(&ClusterConfig{Revision: 1}))
+	require.Equal(t, &ClusterConfig{Revision: 1, Nodes: Nodes{}}, <-configs)
+
+	// The same revision should not trigger a notification
+	require.NoError(t, manager.Update(&ClusterConfig{Revision: 1}))
+
+	// Only the latest config should be buffered
+	require.NoError(t, manager.Update(&ClusterConfig{Revision: 2}))
+	require.NoError(t, manager.Update(&ClusterConfig{Revision: 3}))
+	require.Equal(t, int64(3), (<-configs).Revision)
+
+	unsubscribe()
+	unsubscribe()
+
+	require.NoError(t, manager.Update(&ClusterConfig{Revision: 4}))
+
+	_, ok := <-configs
+	require.False(t, ok)
+}
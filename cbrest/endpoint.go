@@ -30,8 +30,82 @@ const (
 	// collection manifest for a bucket.
 	EndpointBucketManifest Endpoint = "/pools/default/buckets/%s/scopes"
 
+	// EndpointBucketScope represents the endpoint for interacting with a specific named scope.
+	EndpointBucketScope Endpoint = "/pools/default/buckets/%s/scopes/%s"
+
+	// EndpointBucketCollections represents the endpoint used to create collections in a scope.
+	EndpointBucketCollections Endpoint = "/pools/default/buckets/%s/scopes/%s/collections"
+
+	// EndpointBucketCollection represents the endpoint for interacting with a specific named collection.
+	EndpointBucketCollection Endpoint = "/pools/default/buckets/%s/scopes/%s/collections/%s"
+
+	// EndpointBucketEnsureManifest is used to wait until all the nodes in the cluster have the given manifest UID.
+	EndpointBucketEnsureManifest Endpoint = "/pools/default/buckets/%s/scopes/@ensureManifest/%s"
+
 	// EndpointNodesServices is used during the bootstrapping process to fetch a list of all the nodes in the cluster.
 	EndpointNodesServices Endpoint = "/pools/default/nodeServices"
+
+	// EndpointNodesServicesStreaming is the streaming version of 'EndpointNodesServices', a new payload is sent each time
+	// the cluster config changes.
+	EndpointNodesServicesStreaming Endpoint = "/pools/default/nodeServicesStreaming"
+
+	// EndpointBucketStreaming is the terse bucket streaming endpoint, a new payload is sent each time the config for the
+	// given bucket changes; this includes changes to the cluster topology.
+	EndpointBucketStreaming Endpoint = "/pools/default/bs/%s"
+
+	// EndpointBucketFlush is used to remove all the data from a bucket, flush must be enabled for the bucket.
+	EndpointBucketFlush Endpoint = "/pools/default/buckets/%s/controller/doFlush"
+
+	// EndpointAddNode is used to add a new node to the cluster, the node will not be active until the cluster is
+	// rebalanced.
+	EndpointAddNode Endpoint = "/controller/addNode"
+
+	// EndpointFailover is used to hard failover one or more nodes.
+	EndpointFailover Endpoint = "/controller/failOver"
+
+	// EndpointGracefulFailover is used to start a graceful failover of one or more nodes.
+	EndpointGracefulFailover Endpoint = "/controller/startGracefulFailover"
+
+	// EndpointSetRecoveryType is used to mark a failed over node for recovery, the node will be recovered during the next
+	// rebalance.
+	EndpointSetRecoveryType Endpoint = "/controller/setRecoveryType"
+
+	// EndpointRebalance is used to start a rebalance.
+	EndpointRebalance Endpoint = "/controller/rebalance"
+
+	// EndpointStopRebalance is used to stop a running rebalance.
+	EndpointStopRebalance Endpoint = "/controller/stopRebalance"
+
+	// EndpointRebalanceProgress is used to fetch the progress of a running rebalance/graceful failover.
+	EndpointRebalanceProgress Endpoint = "/pools/default/rebalanceProgress"
+
+	// EndpointTasks is used to list the long running tasks on the cluster e.g. rebalance, XDCR and compaction.
+	EndpointTasks Endpoint = "/pools/default/tasks"
+
+	// EndpointBackupTaskHistory is used to list the tasks (e.g. backups/merges) which have been run, or are running, for
+	// the given active Backup Service repository.
+	EndpointBackupTaskHistory Endpoint = "/api/v1/cluster/self/repository/active/%s/taskHistory"
+
+	// EndpointWhoAmI is used to fetch the identity and effective roles of the user making the request.
+	EndpointWhoAmI Endpoint = "/whoami"
+
+	// EndpointRBACRoles is used to list the roles which are supported by the cluster.
+	EndpointRBACRoles Endpoint = "/settings/rbac/roles"
+
+	// EndpointRBACUsers is used to list all the local/external users.
+	EndpointRBACUsers Endpoint = "/settings/rbac/users"
+
+	// EndpointRBACUser represents the endpoint for interacting with a specific user in the given domain.
+	EndpointRBACUser Endpoint = "/settings/rbac/users/%s/%s"
+
+	// EndpointRBACGroups is used to list all the user groups.
+	EndpointRBACGroups Endpoint = "/settings/rbac/groups"
+
+	// EndpointRBACGroup represents the endpoint for interacting with a specific named user group.
+	EndpointRBACGroup Endpoint = "/settings/rbac/groups/%s"
+
+	// EndpointCheckPermissions is used to determine whether the user making the request has the given permissions.
+	EndpointCheckPermissions Endpoint = "/pools/default/checkPermissions"
 )
 
 // Format returns a new endpoint using 'fmt.Sprintf' to fill in any missing/required elements of the endpoint using the
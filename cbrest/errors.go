@@ -3,7 +3,12 @@ package cbrest
 import (
 	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/couchbase/tools-common/cbvalue"
 	"github.com/couchbase/tools-common/format"
 )
 
@@ -31,6 +36,16 @@ var (
 
 	// ErrStreamWithTimeout is returned if the user attempts to execute a stream with a non-zero timeout.
 	ErrStreamWithTimeout = errors.New("using a timeout when executing a streaming request is unsupported")
+
+	// ErrStreamClosed is returned if a stream was unexpectedly closed by the remote end.
+	ErrStreamClosed = errors.New("stream closed by remote host")
+
+	// ErrNodesRequired is returned if the user attempts to failover without providing any nodes.
+	ErrNodesRequired = errors.New("at least one node must be provided")
+
+	// ErrCertificateAuthRequiresTLS is returned if the user attempts to authenticate using a client certificate without
+	// using TLS.
+	ErrCertificateAuthRequiresTLS = errors.New("certificate authentication requires TLS communication")
 )
 
 // BootstrapFailureError is returned to the user if we've failed to bootstrap the REST client.
@@ -139,6 +154,56 @@ func (e *UnexpectedStatusCodeError) Error() string {
 	return msg
 }
 
+// ServiceErrorDetail is a single error returned by a service which returns an array of errors in the response body,
+// for example the Analytics/Query Services.
+type ServiceErrorDetail struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+// ServiceError is returned if a request fails, and the response body contains an array of errors. The underlying
+// status code error (e.g. 'InternalServerError') may be retrieved using 'errors.As'.
+type ServiceError struct {
+	status  int
+	details []ServiceErrorDetail
+	err     error
+}
+
+func (e *ServiceError) Error() string {
+	msgs := make([]string, 0, len(e.details))
+	for _, detail := range e.details {
+		msgs = append(msgs, fmt.Sprintf("%d: %s", detail.Code, detail.Message))
+	}
+
+	return fmt.Sprintf("service returned status code %d with errors: %s", e.status, strings.Join(msgs, "; "))
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.err
+}
+
+// Status returns the status code returned by the service.
+func (e *ServiceError) Status() int {
+	return e.status
+}
+
+// Details returns a copy of the errors returned by the service.
+func (e *ServiceError) Details() []ServiceErrorDetail {
+	return slices.Clone(e.details)
+}
+
+// HasCode returns a boolean indicating whether the service returned an error with the given code.
+func (e *ServiceError) HasCode(code int) bool {
+	return slices.IndexFunc(e.details, func(detail ServiceErrorDetail) bool { return detail.Code == code }) != -1
+}
+
+// IsServiceErrorCode returns a boolean indicating whether the given error is a 'ServiceError' which contains an error
+// with the given code.
+func IsServiceErrorCode(err error, code int) bool {
+	var serviceErr *ServiceError
+	return errors.As(err, &serviceErr) && serviceErr.HasCode(code)
+}
+
 // ServiceNotAvailableError is returned if the requested service is is unavailable i.e. there are no nodes in the
 // cluster running that service.
 type ServiceNotAvailableError struct {
@@ -233,3 +298,359 @@ type OldClusterConfigError struct {
 func (e *OldClusterConfigError) Error() string {
 	return fmt.Sprintf("cluster config revision %d is older than the current revision %d", e.old, e.curr)
 }
+
+// BucketNotFoundError is returned if the requested bucket does not exist.
+type BucketNotFoundError struct {
+	name string
+}
+
+func (e *BucketNotFoundError) Error() string {
+	return fmt.Sprintf("bucket '%s' does not exist", e.name)
+}
+
+// IsBucketNotFound returns a boolean indicating whether the given error is a 'BucketNotFoundError'.
+func IsBucketNotFound(err error) bool {
+	var notFound *BucketNotFoundError
+	return err != nil && errors.As(err, &notFound)
+}
+
+// BucketExistsError is returned if the user attempts to create a bucket which already exists.
+type BucketExistsError struct {
+	name string
+}
+
+func (e *BucketExistsError) Error() string {
+	return fmt.Sprintf("bucket '%s' already exists", e.name)
+}
+
+// IsBucketExists returns a boolean indicating whether the given error is a 'BucketExistsError'.
+func IsBucketExists(err error) bool {
+	var exists *BucketExistsError
+	return err != nil && errors.As(err, &exists)
+}
+
+// BucketSettingsError is returned if the cluster rejected the settings supplied when creating/updating a bucket.
+type BucketSettingsError struct {
+	name   string
+	errors map[string]string
+}
+
+func (e *BucketSettingsError) Error() string {
+	keys := maps.Keys(e.errors)
+	slices.Sort(keys)
+
+	msgs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", key, e.errors[key]))
+	}
+
+	return fmt.Sprintf("invalid settings for bucket '%s': %s", e.name, strings.Join(msgs, ", "))
+}
+
+// Errors returns the validation errors returned by the cluster, keyed by the setting name.
+func (e *BucketSettingsError) Errors() map[string]string {
+	return maps.Clone(e.errors)
+}
+
+// FlushDisabledError is returned if the user attempts to flush a bucket which does not have flush enabled.
+type FlushDisabledError struct {
+	name string
+}
+
+func (e *FlushDisabledError) Error() string {
+	return fmt.Sprintf("flush is disabled for bucket '%s'", e.name)
+}
+
+// ScopeNotFoundError is returned if the requested scope does not exist.
+type ScopeNotFoundError struct {
+	bucket, scope string
+}
+
+func (e *ScopeNotFoundError) Error() string {
+	return fmt.Sprintf("scope '%s' does not exist in bucket '%s'", e.scope, e.bucket)
+}
+
+// IsScopeNotFound returns a boolean indicating whether the given error is a 'ScopeNotFoundError'.
+func IsScopeNotFound(err error) bool {
+	var notFound *ScopeNotFoundError
+	return err != nil && errors.As(err, &notFound)
+}
+
+// ScopeExistsError is returned if the user attempts to create a scope which already exists.
+type ScopeExistsError struct {
+	bucket, scope string
+}
+
+func (e *ScopeExistsError) Error() string {
+	return fmt.Sprintf("scope '%s' already exists in bucket '%s'", e.scope, e.bucket)
+}
+
+// IsScopeExists returns a boolean indicating whether the given error is a 'ScopeExistsError'.
+func IsScopeExists(err error) bool {
+	var exists *ScopeExistsError
+	return err != nil && errors.As(err, &exists)
+}
+
+// CollectionNotFoundError is returned if the requested collection does not exist.
+type CollectionNotFoundError struct {
+	bucket, scope, collection string
+}
+
+func (e *CollectionNotFoundError) Error() string {
+	return fmt.Sprintf("collection '%s.%s' does not exist in bucket '%s'", e.scope, e.collection, e.bucket)
+}
+
+// IsCollectionNotFound returns a boolean indicating whether the given error is a 'CollectionNotFoundError'.
+func IsCollectionNotFound(err error) bool {
+	var notFound *CollectionNotFoundError
+	return err != nil && errors.As(err, &notFound)
+}
+
+// CollectionExistsError is returned if the user attempts to create a collection which already exists.
+type CollectionExistsError struct {
+	bucket, scope, collection string
+}
+
+func (e *CollectionExistsError) Error() string {
+	return fmt.Sprintf("collection '%s.%s' already exists in bucket '%s'", e.scope, e.collection, e.bucket)
+}
+
+// IsCollectionExists returns a boolean indicating whether the given error is a 'CollectionExistsError'.
+func IsCollectionExists(err error) bool {
+	var exists *CollectionExistsError
+	return err != nil && errors.As(err, &exists)
+}
+
+// ManifestNotPropagatedError is returned if we timed out waiting for a manifest UID to be propagated to all the nodes
+// in the cluster.
+type ManifestNotPropagatedError struct {
+	bucket string
+	uid    cbvalue.UID
+}
+
+func (e *ManifestNotPropagatedError) Error() string {
+	return fmt.Sprintf("timed out waiting for manifest '%s' to be propagated to all nodes for bucket '%s'", e.uid,
+		e.bucket)
+}
+
+// NoVBucketMapError is returned if the requested bucket does not have a vBucket map e.g. it's a Memcached bucket.
+type NoVBucketMapError struct {
+	name string
+}
+
+func (e *NoVBucketMapError) Error() string {
+	return fmt.Sprintf("bucket '%s' does not have a vBucket map", e.name)
+}
+
+// IsNoVBucketMap returns a boolean indicating whether the given error is a 'NoVBucketMapError'.
+func IsNoVBucketMap(err error) bool {
+	var noMap *NoVBucketMapError
+	return err != nil && errors.As(err, &noMap)
+}
+
+// TopologyChangeError is returned if 'ns_server' rejected a request to change the cluster topology e.g. adding a node
+// which is already part of another cluster.
+type TopologyChangeError struct {
+	action string
+	reason string
+}
+
+func (e *TopologyChangeError) Error() string {
+	return fmt.Sprintf("failed to %s: %s", e.action, e.reason)
+}
+
+// Reason returns the reason given by 'ns_server' for rejecting the topology change.
+func (e *TopologyChangeError) Reason() string {
+	return e.reason
+}
+
+// RebalanceFailedError is returned if a rebalance (or graceful failover) failed.
+type RebalanceFailedError struct {
+	reason string
+}
+
+func (e *RebalanceFailedError) Error() string {
+	return fmt.Sprintf("rebalance failed: %s", e.reason)
+}
+
+// IsRebalanceFailed returns a boolean indicating whether the given error is a 'RebalanceFailedError'.
+func IsRebalanceFailed(err error) bool {
+	var failed *RebalanceFailedError
+	return err != nil && errors.As(err, &failed)
+}
+
+// TaskFailedError is returned if a task being waited on failed.
+type TaskFailedError struct {
+	task *Task
+}
+
+func (e *TaskFailedError) Error() string {
+	return fmt.Sprintf("%s task failed: %s", e.task.Type, e.task.ErrorMessage)
+}
+
+// Task returns the last reported state of the failed task.
+func (e *TaskFailedError) Task() *Task {
+	return e.task
+}
+
+// IsTaskFailed returns a boolean indicating whether the given error is a 'TaskFailedError'.
+func IsTaskFailed(err error) bool {
+	var failed *TaskFailedError
+	return err != nil && errors.As(err, &failed)
+}
+
+// TaskPausedError is returned if a task being waited on was paused, for example, a paused XDCR replication; the task
+// hasn't completed and may be resumed.
+type TaskPausedError struct {
+	task *Task
+}
+
+func (e *TaskPausedError) Error() string {
+	return fmt.Sprintf("%s task is paused, last reported progress was %.2f%%", e.task.Type, e.task.Progress)
+}
+
+// Task returns the last reported state of the paused task.
+func (e *TaskPausedError) Task() *Task {
+	return e.task
+}
+
+// IsTaskPaused returns a boolean indicating whether the given error is a 'TaskPausedError'.
+func IsTaskPaused(err error) bool {
+	var paused *TaskPausedError
+	return err != nil && errors.As(err, &paused)
+}
+
+// TaskTimeoutError is returned if we timed out waiting for a task to complete.
+type TaskTimeoutError struct {
+	task *Task
+}
+
+func (e *TaskTimeoutError) Error() string {
+	if e.task == nil {
+		return "timed out waiting for task to complete"
+	}
+
+	return fmt.Sprintf("timed out waiting for %s task to complete, last reported progress was %.2f%%", e.task.Type,
+		e.task.Progress)
+}
+
+// Task returns the last reported state of the task, this will be <nil> if the task was never reported.
+func (e *TaskTimeoutError) Task() *Task {
+	return e.task
+}
+
+// IsTaskTimeout returns a boolean indicating whether the given error is a 'TaskTimeoutError'.
+func IsTaskTimeout(err error) bool {
+	var timeout *TaskTimeoutError
+	return err != nil && errors.As(err, &timeout)
+}
+
+// UserNotFoundError is returned if the requested user does not exist.
+type UserNotFoundError struct {
+	domain AuthDomain
+	id     string
+}
+
+func (e *UserNotFoundError) Error() string {
+	return fmt.Sprintf("user '%s/%s' does not exist", e.domain, e.id)
+}
+
+// IsUserNotFound returns a boolean indicating whether the given error is a 'UserNotFoundError'.
+func IsUserNotFound(err error) bool {
+	var notFound *UserNotFoundError
+	return err != nil && errors.As(err, &notFound)
+}
+
+// UserExistsError is returned if the user attempts to create a user which already exists.
+type UserExistsError struct {
+	domain AuthDomain
+	id     string
+}
+
+func (e *UserExistsError) Error() string {
+	return fmt.Sprintf("user '%s/%s' already exists", e.domain, e.id)
+}
+
+// IsUserExists returns a boolean indicating whether the given error is a 'UserExistsError'.
+func IsUserExists(err error) bool {
+	var exists *UserExistsError
+	return err != nil && errors.As(err, &exists)
+}
+
+// GroupNotFoundError is returned if the requested user group does not exist.
+type GroupNotFoundError struct {
+	name string
+}
+
+func (e *GroupNotFoundError) Error() string {
+	return fmt.Sprintf("group '%s' does not exist", e.name)
+}
+
+// IsGroupNotFound returns a boolean indicating whether the given error is a 'GroupNotFoundError'.
+func IsGroupNotFound(err error) bool {
+	var notFound *GroupNotFoundError
+	return err != nil && errors.As(err, &notFound)
+}
+
+// GroupExistsError is returned if the user attempts to create a user group which already exists.
+type GroupExistsError struct {
+	name string
+}
+
+func (e *GroupExistsError) Error() string {
+	return fmt.Sprintf("group '%s' already exists", e.name)
+}
+
+// IsGroupExists returns a boolean indicating whether the given error is a 'GroupExistsError'.
+func IsGroupExists(err error) bool {
+	var exists *GroupExistsError
+	return err != nil && errors.As(err, &exists)
+}
+
+// RBACSettingsError is returned if the cluster rejected the settings supplied when creating/updating a user/group.
+type RBACSettingsError struct {
+	what   string
+	errors map[string]string
+}
+
+func (e *RBACSettingsError) Error() string {
+	keys := maps.Keys(e.errors)
+	slices.Sort(keys)
+
+	msgs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", key, e.errors[key]))
+	}
+
+	return fmt.Sprintf("invalid settings for %s: %s", e.what, strings.Join(msgs, ", "))
+}
+
+// Errors returns the validation errors returned by the cluster, keyed by the setting name.
+func (e *RBACSettingsError) Errors() map[string]string {
+	return maps.Clone(e.errors)
+}
+
+// MissingPermissionsError is returned if the user has not been granted one or more of the required permissions.
+type MissingPermissionsError struct {
+	missing []Permission
+}
+
+func (e *MissingPermissionsError) Error() string {
+	missing := make([]string, 0, len(e.missing))
+	for _, permission := range e.missing {
+		missing = append(missing, string(permission))
+	}
+
+	return fmt.Sprintf("missing required permissions: %s", strings.Join(missing, ", "))
+}
+
+// Missing returns the permissions which have not been granted to the user.
+func (e *MissingPermissionsError) Missing() []Permission {
+	return slices.Clone(e.missing)
+}
+
+// IsMissingPermissions returns a boolean indicating whether the given error is a 'MissingPermissionsError'.
+func IsMissingPermissions(err error) bool {
+	var missing *MissingPermissionsError
+	return err != nil && errors.As(err, &missing)
+}
@@ -0,0 +1,277 @@
+package cbrest
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultHostEjectionDuration is the amount of time for which a host is ejected after repeatedly failing.
+const DefaultHostEjectionDuration = 30 * time.Second
+
+// HostSelector is used by the client to select which host a request should be dispatched to, allowing users to control
+// how requests are balanced between the nodes in the cluster.
+type HostSelector interface {
+	// Select returns the index of the candidate which the request should be dispatched to; the candidates are the
+	// healthy hosts running the required service, with the node the client bootstrapped against first. The attempt is
+	// zero for the first attempt and is incremented for each retry.
+	//
+	// NOTE: Will always be called with at least one candidate, and must be safe for concurrent use.
+	Select(candidates []HostStats, attempt int) int
+}
+
+// OrderedHostSelector dispatches requests to the node the client bootstrapped against, moving to the next host for
+// each retry; this is the default host selector.
+type OrderedHostSelector struct{}
+
+var _ HostSelector = OrderedHostSelector{}
+
+func (o OrderedHostSelector) Select(candidates []HostStats, attempt int) int {
+	return attempt % len(candidates)
+}
+
+// RoundRobinHostSelector distributes requests evenly between the candidate hosts.
+type RoundRobinHostSelector struct {
+	next atomic.Uint64
+}
+
+var _ HostSelector = (*RoundRobinHostSelector)(nil)
+
+func (r *RoundRobinHostSelector) Select(candidates []HostStats, _ int) int {
+	return int((r.next.Add(1) - 1) % uint64(len(candidates)))
+}
+
+// LeastOutstandingHostSelector dispatches requests to the host with the fewest in-flight requests, meaning slower hosts
+// receive fewer requests. Ties are broken using the attempt, so that retries prefer a different host.
+type LeastOutstandingHostSelector struct{}
+
+var _ HostSelector = LeastOutstandingHostSelector{}
+
+func (l LeastOutstandingHostSelector) Select(candidates []HostStats, attempt int) int {
+	least := make([]int, 0, len(candidates))
+
+	for index, candidate := range candidates {
+		if len(least) != 0 && candidate.Outstanding > candidates[least[0]].Outstanding {
+			continue
+		}
+
+		if len(least) != 0 && candidate.Outstanding < candidates[least[0]].Outstanding {
+			least = least[:0]
+		}
+
+		least = append(least, index)
+	}
+
+	return least[attempt%len(least)]
+}
+
+// RandomHostSelector dispatches requests to a randomly selected host.
+type RandomHostSelector struct{}
+
+var _ HostSelector = RandomHostSelector{}
+
+func (r RandomHostSelector) Select(candidates []HostStats, _ int) int {
+	return rand.Intn(len(candidates)) //nolint:gosec
+}
+
+// HostHealthOptions controls how the client tracks the health of the hosts it dispatches requests to.
+//
+// NOTE: Ejection is disabled unless a 'FailureThreshold' is provided, ejected hosts are not given to the host selector
+// whilst there are other hosts which aren't ejected.
+type HostHealthOptions struct {
+	// FailureThreshold is the number of consecutive failed requests after which a host is ejected, a value less than or
+	// equal to zero disables ejection.
+	FailureThreshold int
+
+	// EjectionDuration is the amount of time for which a host is ejected, defaults to 'DefaultHostEjectionDuration'.
+	// Once expired, the host will receive requests again and will be immediately ejected if the next request fails.
+	EjectionDuration time.Duration
+}
+
+// HostStats encapsulates the request statistics for a single host.
+type HostStats struct {
+	// Host is the fully qualified hostname with scheme and port.
+	Host string
+
+	// Requests is the number of completed requests, Failures is the number of those which failed e.g. due to a
+	// connection error or a temporary failure status code.
+	Requests uint64
+	Failures uint64
+
+	// Outstanding is the number of in-flight requests.
+	Outstanding int
+
+	// ConsecutiveFailures is the number of requests which have failed since the last successful request.
+	ConsecutiveFailures int
+
+	// EjectedUntil is the time until which the host is ejected, and won't receive requests unless all other hosts are
+	// also ejected.
+	EjectedUntil time.Time
+
+	// TotalLatency is the total time taken by completed requests, this is the time taken to receive the response
+	// headers.
+	TotalLatency time.Duration
+}
+
+// Ejected returns a boolean indicating whether the host is currently ejected.
+func (h HostStats) Ejected() bool {
+	return time.Now().Before(h.EjectedUntil)
+}
+
+// AverageLatency returns the average time taken by completed requests.
+func (h HostStats) AverageLatency() time.Duration {
+	if h.Requests == 0 {
+		return 0
+	}
+
+	return h.TotalLatency / time.Duration(h.Requests)
+}
+
+// hostTracker tracks the request statistics/health of the hosts the client dispatches requests to.
+type hostTracker struct {
+	options HostHealthOptions
+
+	lock     sync.Mutex
+	stats    map[string]*HostStats
+	services map[Service][]string
+}
+
+// newHostTracker creates a new host tracker, populating any missing options with their defaults.
+func newHostTracker(options HostHealthOptions) *hostTracker {
+	if options.EjectionDuration == 0 {
+		options.EjectionDuration = DefaultHostEjectionDuration
+	}
+
+	return &hostTracker{
+		options:  options,
+		stats:    make(map[string]*HostStats),
+		services: make(map[Service][]string),
+	}
+}
+
+// candidates returns the stats for the given hosts (which are running the given service) that aren't currently ejected,
+// preserving their order. Any tracked hosts which are no longer running a service are pruned.
+//
+// NOTE: When all the hosts are ejected, they're all returned so that requests may still be dispatched.
+func (h *hostTracker) candidates(service Service, hosts []string) []HostStats {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.services[service] = hosts
+
+	h.pruneLocked()
+
+	var (
+		all     = make([]HostStats, 0, len(hosts))
+		healthy = make([]HostStats, 0, len(hosts))
+	)
+
+	for _, host := range hosts {
+		stats := *h.getLocked(host)
+
+		all = append(all, stats)
+
+		if !stats.Ejected() {
+			healthy = append(healthy, stats)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return all
+	}
+
+	return healthy
+}
+
+// begin records that a request is being dispatched to the given host.
+func (h *hostTracker) begin(host string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.getLocked(host).Outstanding++
+}
+
+// end records that a request to the given host has completed, ejecting the host if it's repeatedly failing.
+func (h *hostTracker) end(host string, failed bool, latency time.Duration) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	stats := h.getLocked(host)
+
+	stats.Outstanding--
+	stats.Requests++
+	stats.TotalLatency += latency
+
+	if !failed {
+		stats.ConsecutiveFailures = 0
+		return
+	}
+
+	stats.Failures++
+	stats.ConsecutiveFailures++
+
+	if h.options.FailureThreshold <= 0 || stats.ConsecutiveFailures < h.options.FailureThreshold {
+		return
+	}
+
+	stats.EjectedUntil = time.Now().Add(h.options.EjectionDuration)
+}
+
+// snapshot returns a copy of the stats for all the tracked hosts, sorted by host. Hosts are tracked once they've been a
+// candidate for, or been dispatched, a request and remain tracked whilst they're running a service which has been
+// requested; hosts which have never been dispatched a request will have zero stats.
+func (h *hostTracker) snapshot() []HostStats {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	hosts := maps.Keys(h.stats)
+	slices.Sort(hosts)
+
+	stats := make([]HostStats, 0, len(hosts))
+	for _, host := range hosts {
+		stats = append(stats, *h.stats[host])
+	}
+
+	return stats
+}
+
+// pruneLocked removes the stats for any hosts which are no longer running any of the services which have been requested
+// e.g. because they've been removed from the cluster, hosts with in-flight requests are retained.
+//
+// NOTE: Expects the lock to be held by the caller.
+func (h *hostTracker) pruneLocked() {
+	for host, stats := range h.stats {
+		if stats.Outstanding > 0 {
+			continue
+		}
+
+		var running bool
+
+		for _, hosts := range h.services {
+			if running = slices.Contains(hosts, host); running {
+				break
+			}
+		}
+
+		if !running {
+			delete(h.stats, host)
+		}
+	}
+}
+
+// getLocked returns the stats for the given host, creating them if they don't exist.
+//
+// NOTE: Expects the lock to be held by the caller.
+func (h *hostTracker) getLocked(host string) *HostStats {
+	stats, ok := h.stats[host]
+	if !ok {
+		stats = &HostStats{Host: host}
+		h.stats[host] = stats
+	}
+
+	return stats
+}
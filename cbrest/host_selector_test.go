@@ -0,0 +1,176 @@
+package cbrest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/aprov"
+)
+
+func TestOrderedHostSelector(t *testing.T) {
+	candidates := []HostStats{{Host: "a"}, {Host: "b"}, {Host: "c"}}
+
+	selector := OrderedHostSelector{}
+	require.Equal(t, 0, selector.Select(candidates, 0))
+	require.Equal(t, 1, selector.Select(candidates, 1))
+	require.Equal(t, 0, selector.Select(candidates, 3))
+}
+
+func TestRoundRobinHostSelector(t *testing.T) {
+	candidates := []HostStats{{Host: "a"}, {Host: "b"}, {Host: "c"}}
+
+	var (
+		selector RoundRobinHostSelector
+		selected = make([]int, 0)
+	)
+
+	for i := 0; i < 4; i++ {
+		selected = append(selected, selector.Select(candidates, 0))
+	}
+
+	require.Equal(t, []int{0, 1, 2, 0}, selected)
+}
+
+func TestLeastOutstandingHostSelector(t *testing.T) {
+	candidates := []HostStats{{Host: "a", Outstanding: 2}, {Host: "b", Outstanding: 1}, {Host: "c", Outstanding: 1}}
+
+	selector := LeastOutstandingHostSelector{}
+	require.Equal(t, 1, selector.Select(candidates, 0))
+
+	// Retries should prefer a different host with the same number of outstanding requests
+	require.Equal(t, 2, selector.Select(candidates, 1))
+}
+
+func TestRandomHostSelector(t *testing.T) {
+	candidates := []HostStats{{Host: "a"}, {Host: "b"}, {Host: "c"}}
+
+	for i := 0; i < 16; i++ {
+		require.Contains(t, []int{0, 1, 2}, RandomHostSelector{}.Select(candidates, 0))
+	}
+}
+
+func TestHostTrackerEjection(t *testing.T) {
+	tracker := newHostTracker(HostHealthOptions{FailureThreshold: 2, EjectionDuration: 50 * time.Millisecond})
+
+	fail := func(host string) {
+		tracker.begin(host)
+		tracker.end(host, true, time.Millisecond)
+	}
+
+	fail("a")
+	require.Len(t, tracker.candidates(ServiceManagement, []string{"a", "b"}), 2)
+
+	fail("a")
+
+	candidates := tracker.candidates(ServiceManagement, []string{"a", "b"})
+	require.Len(t, candidates, 1)
+	require.Equal(t, "b", candidates[0].Host)
+
+	// When every host is ejected, they should all be returned
+	fail("b")
+	fail("b")
+	require.Len(t, tracker.candidates(ServiceManagement, []string{"a", "b"}), 2)
+
+	// Once the ejection expires, the hosts should receive requests again
+	time.Sleep(100 * time.Millisecond)
+
+	candidates = tracker.candidates(ServiceManagement, []string{"a", "b"})
+	require.Len(t, candidates, 2)
+	require.False(t, candidates[0].Ejected())
+
+	// A successful request should reset the consecutive failures
+	tracker.begin("a")
+	tracker.end("a", false, 3*time.Millisecond)
+
+	stats := tracker.snapshot()
+	require.Len(t, stats, 2)
+	require.Equal(t, "a", stats[0].Host)
+	require.Equal(t, uint64(3), stats[0].Requests)
+	require.Equal(t, uint64(2), stats[0].Failures)
+	require.Zero(t, stats[0].ConsecutiveFailures)
+	require.Zero(t, stats[0].Outstanding)
+	require.Equal(t, 5*time.Millisecond/3, stats[0].AverageLatency())
+}
+
+func TestHostTrackerEjectionDisabled(t *testing.T) {
+	for _, threshold := range []int{0, -1} {
+		tracker := newHostTracker(HostHealthOptions{FailureThreshold: threshold})
+
+		for i := 0; i < 8; i++ {
+			tracker.begin("a")
+			tracker.end("a", true, 0)
+		}
+
+		require.False(t, tracker.snapshot()[0].Ejected())
+		require.Len(t, tracker.candidates(ServiceManagement, []string{"a", "b"}), 2)
+	}
+}
+
+func TestHostTrackerPrune(t *testing.T) {
+	tracker := newHostTracker(HostHealthOptions{})
+
+	tracker.candidates(ServiceManagement, []string{"a", "b"})
+	tracker.candidates(ServiceQuery, []string{"c"})
+
+	// Hosts with in-flight requests should be retained until the requests complete
+	tracker.begin("b")
+
+	tracker.candidates(ServiceManagement, []string{"a"})
+	require.Len(t, tracker.snapshot(), 3)
+
+	tracker.end("b", false, 0)
+
+	tracker.candidates(ServiceManagement, []string{"a"})
+
+	stats := tracker.snapshot()
+	require.Len(t, stats, 2)
+	require.Equal(t, "a", stats[0].Host)
+	require.Equal(t, "c", stats[1].Host)
+
+	tracker.candidates(ServiceQuery, nil)
+
+	stats = tracker.snapshot()
+	require.Len(t, stats, 1)
+	require.Equal(t, "a", stats[0].Host)
+}
+
+func TestClientHostStats(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, "/test", NewTestHandler(t, http.StatusServiceUnavailable, make([]byte, 0)))
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := NewClient(ClientOptions{
+		ConnectionString: cluster.URL(),
+		DisableCCP:       true,
+		Provider:         &aprov.Static{Username: username, Password: password, UserAgent: userAgent},
+		HostSelector:     &RoundRobinHostSelector{},
+		HostHealth:       HostHealthOptions{FailureThreshold: 2},
+	})
+	require.NoError(t, err)
+
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           "/test",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	// The only host will be ejected, however, requests should still be dispatched to it
+	var retriesExhausted *RetriesExhaustedError
+
+	_, err = client.Execute(request)
+	require.ErrorAs(t, err, &retriesExhausted)
+
+	stats := client.HostStats()
+	require.Len(t, stats, 1)
+	require.Equal(t, cluster.URL(), stats[0].Host)
+	require.Equal(t, uint64(3), stats[0].Failures)
+	require.True(t, stats[0].Ejected())
+	require.Zero(t, stats[0].Outstanding)
+}
@@ -0,0 +1,219 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/couchbase/tools-common/cbvalue"
+)
+
+// CollectionSettings encapsulates the settings which may be supplied when creating a collection.
+type CollectionSettings struct {
+	// Name is the name of the collection.
+	//
+	// NOTE: This attribute is required.
+	Name string
+
+	// MaxTTL is the maximum time-to-live (in seconds) for documents in the collection, where zero indicates that the
+	// bucket TTL should be used and -1 indicates that documents should never expire (Couchbase Server 7.6+).
+	MaxTTL int32
+}
+
+// GetManifest returns the collections manifest for the given bucket.
+func (c *Client) GetManifest(ctx context.Context, bucket string) (*cbvalue.Manifest, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucketManifest.Format(bucket),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleManifestError(bucket, "", "", response, err)
+	}
+
+	var manifest *cbvalue.Manifest
+
+	err = json.Unmarshal(response.Body, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// CreateScope creates a new scope in the given bucket, returning the UID of the resulting manifest.
+func (c *Client) CreateScope(ctx context.Context, bucket, scope string) (cbvalue.UID, error) {
+	request := &Request{
+		Body:               []byte(url.Values{"name": {scope}}.Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucketManifest.Format(bucket),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	return c.executeManifestUpdate(ctx, request, bucket, scope, "")
+}
+
+// DropScope drops the given scope (and all of its collections), returning the UID of the resulting manifest.
+func (c *Client) DropScope(ctx context.Context, bucket, scope string) (cbvalue.UID, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucketScope.Format(bucket, scope),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodDelete,
+		Service:            ServiceManagement,
+	}
+
+	return c.executeManifestUpdate(ctx, request, bucket, scope, "")
+}
+
+// CreateCollection creates a new collection in the given scope, returning the UID of the resulting manifest.
+func (c *Client) CreateCollection(ctx context.Context, bucket, scope string,
+	settings CollectionSettings,
+) (cbvalue.UID, error) {
+	values := url.Values{"name": {settings.Name}}
+
+	if settings.MaxTTL != 0 {
+		values.Set("maxTTL", strconv.FormatInt(int64(settings.MaxTTL), 10))
+	}
+
+	request := &Request{
+		Body:               []byte(values.Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucketCollections.Format(bucket, scope),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	return c.executeManifestUpdate(ctx, request, bucket, scope, settings.Name)
+}
+
+// DropCollection drops the given collection, returning the UID of the resulting manifest.
+func (c *Client) DropCollection(ctx context.Context, bucket, scope, collection string) (cbvalue.UID, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucketCollection.Format(bucket, scope, collection),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodDelete,
+		Service:            ServiceManagement,
+	}
+
+	return c.executeManifestUpdate(ctx, request, bucket, scope, collection)
+}
+
+// WaitForManifestUID blocks until all the nodes in the cluster have a manifest with (at least) the given UID, for
+// example the UID returned after creating a collection; a 'ManifestNotPropagatedError' is returned if this doesn't
+// happen within the poll timeout.
+func (c *Client) WaitForManifestUID(ctx context.Context, bucket string, uid cbvalue.UID) error {
+	ctx, cancelFunc := context.WithTimeout(ctx, c.pollTimeout)
+	defer cancelFunc()
+
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucketEnsureManifest.Format(bucket, uid.String()),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+		// A gateway timeout indicates the manifest hasn't been propagated yet, we'll handle this via polling
+		NoRetryOnStatusCodes: []int{http.StatusGatewayTimeout},
+		Idempotent:           true,
+	}
+
+	timeout, err := c.PollRequestsWithContext(ctx, func(attempt int) (bool, error) {
+		response, err := c.ExecuteWithContext(ctx, request)
+		if err == nil {
+			return true, nil
+		}
+
+		if response != nil && response.StatusCode == http.StatusGatewayTimeout {
+			return false, nil
+		}
+
+		return false, handleManifestError(bucket, "", "", response, err)
+	})
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	if timeout {
+		return &ManifestNotPropagatedError{bucket: bucket, uid: uid}
+	}
+
+	return nil
+}
+
+// executeManifestUpdate executes the given request which will update the manifest for the given bucket, returning the
+// UID of the resulting manifest.
+func (c *Client) executeManifestUpdate(ctx context.Context, request *Request, bucket, scope,
+	collection string,
+) (cbvalue.UID, error) {
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return 0, handleManifestError(bucket, scope, collection, response, err)
+	}
+
+	type overlay struct {
+		UID cbvalue.UID `json:"uid"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return decoded.UID, nil
+}
+
+// handleManifestError converts the error returned by a collections management request into a typed error where
+// possible, using the response body returned by 'ns_server'.
+func handleManifestError(bucket, scope, collection string, response *Response, err error) error {
+	if response == nil ||
+		(response.StatusCode != http.StatusNotFound && response.StatusCode != http.StatusBadRequest) {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	type overlay struct {
+		Errors map[string]string `json:"errors"`
+	}
+
+	var decoded overlay
+
+	// Purposefully ignored, the body will not be JSON in the event that the bucket does not exist
+	_ = json.Unmarshal(response.Body, &decoded)
+
+	msg := strings.ToLower(decoded.Errors["_"])
+
+	var (
+		isScope      = strings.HasPrefix(msg, "scope")
+		isCollection = strings.HasPrefix(msg, "collection")
+		notFound     = strings.Contains(msg, "not found")
+		exists       = strings.Contains(msg, "already exists")
+	)
+
+	switch {
+	case isScope && notFound:
+		return &ScopeNotFoundError{bucket: bucket, scope: scope}
+	case isScope && exists:
+		return &ScopeExistsError{bucket: bucket, scope: scope}
+	case isCollection && notFound:
+		return &CollectionNotFoundError{bucket: bucket, scope: scope, collection: collection}
+	case isCollection && exists:
+		return &CollectionExistsError{bucket: bucket, scope: scope, collection: collection}
+	case response.StatusCode == http.StatusNotFound && len(decoded.Errors) == 0:
+		return &BucketNotFoundError{name: bucket}
+	}
+
+	return fmt.Errorf("failed to execute request: %w", err)
+}
@@ -0,0 +1,198 @@
+package cbrest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/cbvalue"
+)
+
+func TestClientManifestManagement(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Buckets: TestBuckets{"default": {}}})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	before, err := client.GetManifest(ctx, "default")
+	require.NoError(t, err)
+
+	uid, err := client.CreateScope(ctx, "default", "scope")
+	require.NoError(t, err)
+	require.Equal(t, cbvalue.UID(1), uid)
+
+	uid, err = client.CreateCollection(ctx, "default", "scope", CollectionSettings{Name: "collection", MaxTTL: 60})
+	require.NoError(t, err)
+	require.Equal(t, cbvalue.UID(2), uid)
+
+	_, err = client.CreateCollection(ctx, "default", cbvalue.DefaultScope, CollectionSettings{Name: "dropped"})
+	require.NoError(t, err)
+
+	uid, err = client.DropCollection(ctx, "default", cbvalue.DefaultScope, "dropped")
+	require.NoError(t, err)
+	require.Equal(t, cbvalue.UID(4), uid)
+
+	require.NoError(t, client.WaitForManifestUID(ctx, "default", uid))
+
+	after, err := client.GetManifest(ctx, "default")
+	require.NoError(t, err)
+
+	expected := &cbvalue.Manifest{
+		UID: 4,
+		Scopes: []*cbvalue.Scope{
+			{Name: "_default", Collections: []*cbvalue.Collection{{Name: "_default"}}},
+			{Name: "scope", UID: 8, Collections: []*cbvalue.Collection{{Name: "collection", UID: 9, MaxTTL: 60}}},
+		},
+	}
+
+	require.Equal(t, expected, after)
+
+	diff := cbvalue.DiffManifests(before, after)
+	require.Equal(t, []*cbvalue.Scope{after.Scopes[1]}, diff.AddedScopes)
+
+	uid, err = client.DropScope(ctx, "default", "scope")
+	require.NoError(t, err)
+	require.Equal(t, cbvalue.UID(5), uid)
+}
+
+func TestClientManifestManagementErrors(t *testing.T) {
+	type test struct {
+		name  string
+		fn    func(client *Client) error
+		check func(err error) bool
+	}
+
+	tests := []*test{
+		{
+			name: "GetBucketNotFound",
+			fn: func(client *Client) error {
+				_, err := client.GetManifest(context.Background(), "missing")
+				return err
+			},
+			check: IsBucketNotFound,
+		},
+		{
+			name: "CreateScopeExists",
+			fn: func(client *Client) error {
+				_, err := client.CreateScope(context.Background(), "default", cbvalue.DefaultScope)
+				return err
+			},
+			check: IsScopeExists,
+		},
+		{
+			name: "DropScopeNotFound",
+			fn: func(client *Client) error {
+				_, err := client.DropScope(context.Background(), "default", "missing")
+				return err
+			},
+			check: IsScopeNotFound,
+		},
+		{
+			name: "CreateCollectionScopeNotFound",
+			fn: func(client *Client) error {
+				_, err := client.CreateCollection(context.Background(), "default", "missing",
+					CollectionSettings{Name: "collection"})
+
+				return err
+			},
+			check: IsScopeNotFound,
+		},
+		{
+			name: "CreateCollectionExists",
+			fn: func(client *Client) error {
+				_, err := client.CreateCollection(context.Background(), "default", cbvalue.DefaultScope,
+					CollectionSettings{Name: cbvalue.DefaultCollection})
+
+				return err
+			},
+			check: IsCollectionExists,
+		},
+		{
+			name: "DropCollectionNotFound",
+			fn: func(client *Client) error {
+				_, err := client.DropCollection(context.Background(), "default", cbvalue.DefaultScope, "missing")
+				return err
+			},
+			check: IsCollectionNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := NewTestCluster(t, TestClusterOptions{Buckets: TestBuckets{"default": {}}})
+			defer cluster.Close()
+
+			client, err := newTestClient(cluster, true)
+			require.NoError(t, err)
+
+			err = test.fn(client)
+			require.Error(t, err)
+			require.True(t, test.check(err))
+		})
+	}
+}
+
+func TestClientWaitForManifestUIDTimeout(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodPost, string(EndpointBucketEnsureManifest.Format("default", "8")),
+		NewTestHandler(t, http.StatusGatewayTimeout, nil))
+
+	cluster := NewTestCluster(t, TestClusterOptions{Buckets: TestBuckets{"default": {}}, Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	client.pollTimeout = 50 * time.Millisecond
+
+	var propagationErr *ManifestNotPropagatedError
+	require.ErrorAs(t, client.WaitForManifestUID(context.Background(), "default", 8), &propagationErr)
+}
+
+func TestClientWaitForManifestUIDHex(t *testing.T) {
+	// The UID must be sent in hex, a decimal UID would be waiting for a different manifest
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodPost, string(EndpointBucketEnsureManifest.Format("default", "1a")),
+		NewTestHandler(t, http.StatusOK, nil))
+	handlers.Add(http.MethodPost, string(EndpointBucketEnsureManifest.Format("default", "26")),
+		NewTestHandler(t, http.StatusGatewayTimeout, nil))
+
+	cluster := NewTestCluster(t, TestClusterOptions{Buckets: TestBuckets{"default": {}}, Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	client.pollTimeout = 50 * time.Millisecond
+
+	require.NoError(t, client.WaitForManifestUID(context.Background(), "default", 0x1a))
+
+	// The default handler should also accept hex UIDs
+	require.NoError(t, client.WaitForManifestUID(context.Background(), "default", 0xff))
+}
+
+func TestClientCreateCollectionNoExpiry(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Buckets: TestBuckets{"default": {}}})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = client.CreateCollection(ctx, "default", cbvalue.DefaultScope, CollectionSettings{Name: "never", MaxTTL: -1})
+	require.NoError(t, err)
+
+	manifest, err := client.GetManifest(ctx, "default")
+	require.NoError(t, err)
+
+	collection := manifest.Scope(cbvalue.DefaultScope).Collection("never")
+	require.NotNil(t, collection)
+	require.Equal(t, int32(-1), collection.MaxTTL)
+}
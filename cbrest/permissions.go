@@ -0,0 +1,114 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Permission represents an RBAC permission in the form '<resource>!<action>' e.g.
+// 'cluster.bucket[default].data.docs!read'.
+type Permission string
+
+// BucketPermission returns the permission to perform the given action on a resource within a bucket, scope or
+// collection, the scope/collection may be empty to create a bucket/scope level permission.
+//
+// For example, 'BucketPermission("default", "s1", "", "data.docs", "read")' returns
+// 'cluster.scope[default:s1].data.docs!read'.
+func BucketPermission(bucket, scope, collection, resource, action string) Permission {
+	level, qualifiers := "bucket", []string{bucket}
+
+	if scope != "" {
+		level, qualifiers = "scope", append(qualifiers, scope)
+	}
+
+	if scope != "" && collection != "" {
+		level, qualifiers = "collection", append(qualifiers, collection)
+	}
+
+	return Permission(fmt.Sprintf("cluster.%s[%s].%s!%s", level, strings.Join(qualifiers, ":"), resource, action))
+}
+
+// PermissionsCheck is the result of checking whether the user has been granted a list of permissions.
+type PermissionsCheck struct {
+	// Granted/Missing are the permissions which have/have not been granted to the user, in the order they were
+	// requested.
+	Granted []Permission
+	Missing []Permission
+}
+
+// OK returns a boolean indicating whether all the permissions have been granted.
+func (p *PermissionsCheck) OK() bool {
+	return len(p.Missing) == 0
+}
+
+// CheckPermissions determines which of the given permissions have been granted to the user associated with the
+// credentials being used by the client, all the permissions are checked using a single request.
+func (c *Client) CheckPermissions(ctx context.Context, permissions ...Permission) (*PermissionsCheck, error) {
+	check := &PermissionsCheck{Granted: make([]Permission, 0), Missing: make([]Permission, 0)}
+
+	if len(permissions) == 0 {
+		return check, nil
+	}
+
+	encoded := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		encoded = append(encoded, string(permission))
+	}
+
+	request := &Request{
+		Body:               []byte(strings.Join(encoded, ",")),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointCheckPermissions,
+		ExpectedStatusCode: http.StatusOK,
+		Idempotent:         true,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var granted map[Permission]bool
+
+	err = json.Unmarshal(response.Body, &granted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	for _, permission := range permissions {
+		if slices.Contains(check.Granted, permission) || slices.Contains(check.Missing, permission) {
+			continue
+		}
+
+		if granted[permission] {
+			check.Granted = append(check.Granted, permission)
+		} else {
+			check.Missing = append(check.Missing, permission)
+		}
+	}
+
+	return check, nil
+}
+
+// RequirePermissions returns a 'MissingPermissionsError' if the user associated with the credentials being used by the
+// client has not been granted all the given permissions; this allows tools to declare the permissions they require
+// upfront, refusing to start an operation which they won't be able to complete.
+func (c *Client) RequirePermissions(ctx context.Context, permissions ...Permission) error {
+	check, err := c.CheckPermissions(ctx, permissions...)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+
+	if !check.OK() {
+		return &MissingPermissionsError{missing: check.Missing}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package cbrest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketPermission(t *testing.T) {
+	type test struct {
+		name       string
+		scope      string
+		collection string
+		expected   Permission
+	}
+
+	tests := []*test{
+		{
+			name:     "Bucket",
+			expected: "cluster.bucket[default].data.docs!read",
+		},
+		{
+			name:     "Scope",
+			scope:    "s1",
+			expected: "cluster.scope[default:s1].data.docs!read",
+		},
+		{
+			name:       "Collection",
+			scope:      "s1",
+			collection: "c1",
+			expected:   "cluster.collection[default:s1:c1].data.docs!read",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, BucketPermission("default", test.scope, test.collection, "data.docs", "read"))
+		})
+	}
+}
+
+func TestClientCheckPermissions(t *testing.T) {
+	var (
+		read   = BucketPermission("default", "", "", "data.docs", "read")
+		write  = BucketPermission("default", "", "", "data.docs", "write")
+		stats  = Permission("cluster.stats!read")
+		backup = BucketPermission("default", "", "", "data.backup", "read")
+	)
+
+	cluster := NewTestCluster(t, TestClusterOptions{
+		Users: TestUsers{username: {Password: password, Permissions: []Permission{read, stats}}},
+	})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	check, err := client.CheckPermissions(context.Background(), write, read, stats, backup, read)
+	require.NoError(t, err)
+	require.False(t, check.OK())
+	require.Equal(t, []Permission{read, stats}, check.Granted)
+	require.Equal(t, []Permission{write, backup}, check.Missing)
+
+	check, err = client.CheckPermissions(context.Background())
+	require.NoError(t, err)
+	require.True(t, check.OK())
+
+	require.NoError(t, client.RequirePermissions(context.Background(), read, stats))
+
+	err = client.RequirePermissions(context.Background(), read, backup)
+	require.True(t, IsMissingPermissions(err))
+
+	var missingErr *MissingPermissionsError
+	require.ErrorAs(t, err, &missingErr)
+	require.Equal(t, []Permission{backup}, missingErr.Missing())
+	require.EqualError(t, err, "missing required permissions: cluster.bucket[default].data.backup!read")
+}
+
+func TestClientCheckPermissionsAdmin(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	require.NoError(t, client.RequirePermissions(context.Background(), "cluster.admin.security!write"))
+}
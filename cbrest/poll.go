@@ -44,3 +44,19 @@ func (c *Client) PollWithContext(ctx context.Context, poll func(attempt int) (bo
 		<-ticker.C
 	}
 }
+
+// PollRequestsWithContext is a wrapper around 'PollWithContext' for polling functions which dispatch requests using the
+// given context; errors returned once the context has expired (e.g. because we timed out whilst executing a request)
+// are ignored, so that they're reported as a timeout by the poller.
+func (c *Client) PollRequestsWithContext(ctx context.Context,
+	poll func(attempt int) (bool, error),
+) (bool, error) {
+	return c.PollWithContext(ctx, func(attempt int) (bool, error) {
+		done, err := poll(attempt)
+		if err != nil && ctx.Err() != nil {
+			return false, nil
+		}
+
+		return done, err
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -44,3 +45,20 @@ func TestPollWithContextCancel(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, timeout)
 }
+
+func TestPollRequestsWithContext(t *testing.T) {
+	client := &Client{}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelFunc()
+
+	_, err := client.PollRequestsWithContext(ctx, func(attempt int) (bool, error) { return false, assert.AnError })
+	require.ErrorIs(t, err, assert.AnError)
+
+	cancelFunc()
+
+	// Errors returned once the context has expired should be reported as a timeout
+	timeout, err := client.PollRequestsWithContext(ctx, func(attempt int) (bool, error) { return false, assert.AnError })
+	require.NoError(t, err)
+	require.True(t, timeout)
+}
@@ -0,0 +1,608 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AuthDomain represents the domain in which a user is authenticated.
+type AuthDomain string
+
+const (
+	// AuthDomainLocal is a user which is authenticated by the cluster.
+	AuthDomainLocal AuthDomain = "local"
+
+	// AuthDomainExternal is a user which is authenticated by an external service e.g. LDAP, PAM or SAML.
+	AuthDomainExternal AuthDomain = "external"
+
+	// AuthDomainAdmin is the built-in full administrator, which can't be managed using the RBAC endpoints.
+	AuthDomainAdmin AuthDomain = "admin"
+)
+
+// Role represents an RBAC role, which may be qualified by a bucket, scope and collection.
+//
+// NOTE: An empty (or '*') qualifier grants the role for all the buckets/scopes/collections at that level.
+type Role struct {
+	Name       string `json:"role"`
+	Bucket     string `json:"bucket_name,omitempty"`
+	Scope      string `json:"scope_name,omitempty"`
+	Collection string `json:"collection_name,omitempty"`
+}
+
+// ParseRole parses a role in the form accepted by the cluster e.g. 'admin' or 'data_reader[bucket:scope:collection]'.
+func ParseRole(role string) (Role, error) {
+	name, qualifiers, ok := strings.Cut(role, "[")
+	if !ok {
+		return Role{Name: role}, nil
+	}
+
+	if name == "" || !strings.HasSuffix(qualifiers, "]") {
+		return Role{}, fmt.Errorf("invalid role '%s'", role)
+	}
+
+	parts := strings.Split(strings.TrimSuffix(qualifiers, "]"), ":")
+	if len(parts) > 3 || parts[0] == "" {
+		return Role{}, fmt.Errorf("invalid role '%s'", role)
+	}
+
+	parsed := Role{Name: name, Bucket: parts[0]}
+
+	if len(parts) > 1 {
+		parsed.Scope = parts[1]
+	}
+
+	if len(parts) > 2 {
+		parsed.Collection = parts[2]
+	}
+
+	return parsed, nil
+}
+
+// String returns the role in the form accepted by the cluster e.g. 'data_reader[bucket:scope:collection]'.
+func (r Role) String() string {
+	if r.Bucket == "" {
+		return r.Name
+	}
+
+	qualifiers := []string{r.Bucket}
+
+	if r.Scope != "" {
+		qualifiers = append(qualifiers, r.Scope)
+	}
+
+	if r.Scope != "" && r.Collection != "" {
+		qualifiers = append(qualifiers, r.Collection)
+	}
+
+	return fmt.Sprintf("%s[%s]", r.Name, strings.Join(qualifiers, ":"))
+}
+
+// Covers returns a boolean indicating whether this role grants (at least) the given role, for example, a bucket level
+// role covers the same role for every scope/collection in that bucket.
+func (r Role) Covers(role Role) bool {
+	covers := func(have, want string) bool { return have == "" || have == "*" || have == want }
+
+	return r.Name == role.Name &&
+		covers(r.Bucket, role.Bucket) &&
+		covers(r.Scope, role.Scope) &&
+		covers(r.Collection, role.Collection)
+}
+
+// joinRoles returns the given roles as a comma separated list in the form accepted by the cluster.
+func joinRoles(roles []Role) string {
+	encoded := make([]string, 0, len(roles))
+	for _, role := range roles {
+		encoded = append(encoded, role.String())
+	}
+
+	return strings.Join(encoded, ",")
+}
+
+// RoleDefinition describes a role which is supported by the cluster.
+type RoleDefinition struct {
+	// Role is the role itself, the qualifiers which are supported by the role are reported as '*'.
+	Role
+
+	DisplayName string `json:"name"`
+	Description string `json:"desc"`
+}
+
+// User represents a local or external user, as reported by the cluster.
+type User struct {
+	ID     string
+	Domain AuthDomain
+	Name   string
+
+	// Roles are the roles which have been assigned directly to the user, roles inherited from groups are not included.
+	Roles []Role
+
+	Groups         []string
+	ExternalGroups []string
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, filtering out the roles which are inherited from groups.
+func (u *User) UnmarshalJSON(data []byte) error {
+	type overlay struct {
+		ID     string     `json:"id"`
+		Domain AuthDomain `json:"domain"`
+		Name   string     `json:"name"`
+		Roles  []struct {
+			Role
+			Origins []struct {
+				Type string `json:"type"`
+			} `json:"origins"`
+		} `json:"roles"`
+		Groups         []string `json:"groups"`
+		ExternalGroups []string `json:"external_groups"`
+	}
+
+	var decoded overlay
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	*u = User{
+		ID:             decoded.ID,
+		Domain:         decoded.Domain,
+		Name:           decoded.Name,
+		Roles:          make([]Role, 0, len(decoded.Roles)),
+		Groups:         decoded.Groups,
+		ExternalGroups: decoded.ExternalGroups,
+	}
+
+	for _, role := range decoded.Roles {
+		// Older versions of Couchbase Server don't report the origins, in which case all roles are assigned directly
+		direct := len(role.Origins) == 0
+
+		for _, origin := range role.Origins {
+			direct = direct || origin.Type == "user"
+		}
+
+		if direct {
+			u.Roles = append(u.Roles, role.Role)
+		}
+	}
+
+	return nil
+}
+
+// UserSettings encapsulates the settings which may be supplied when creating/updating a user.
+//
+// NOTE: Updating a user replaces its name, roles and groups, therefore, all the settings should be supplied.
+type UserSettings struct {
+	// Domain is the domain of the user, defaults to local.
+	Domain AuthDomain
+
+	// ID is the username of the user.
+	//
+	// NOTE: This attribute is required.
+	ID string
+
+	Name string
+
+	// Password is the password for the user, this is required when creating a local user and ignored for external
+	// users; when updating a local user, the existing password will be retained if it's not provided.
+	Password string
+
+	Roles  []Role
+	Groups []string
+}
+
+// domain returns the domain of the user, defaulting to local.
+func (u UserSettings) domain() AuthDomain {
+	if u.Domain == "" {
+		return AuthDomainLocal
+	}
+
+	return u.Domain
+}
+
+// values returns the settings encoded as form values.
+func (u UserSettings) values() url.Values {
+	values := url.Values{
+		"roles":  {joinRoles(u.Roles)},
+		"groups": {strings.Join(u.Groups, ",")},
+	}
+
+	if u.Name != "" {
+		values.Set("name", u.Name)
+	}
+
+	if u.Password != "" && u.domain() == AuthDomainLocal {
+		values.Set("password", u.Password)
+	}
+
+	return values
+}
+
+// Group represents a user group, as reported by the cluster.
+type Group struct {
+	Name               string `json:"id"`
+	Description        string `json:"description"`
+	Roles              []Role `json:"roles"`
+	LDAPGroupReference string `json:"ldap_group_ref"`
+}
+
+// GroupSettings encapsulates the settings which may be supplied when creating/updating a group.
+//
+// NOTE: Updating a group replaces all of its settings, therefore, all the settings should be supplied.
+type GroupSettings struct {
+	// Name is the name of the group.
+	//
+	// NOTE: This attribute is required.
+	Name string
+
+	Description string
+	Roles       []Role
+
+	// LDAPGroupReference is the LDAP group which is mapped to this group.
+	LDAPGroupReference string
+}
+
+// values returns the settings encoded as form values.
+func (g GroupSettings) values() url.Values {
+	values := url.Values{"roles": {joinRoles(g.Roles)}}
+
+	if g.Description != "" {
+		values.Set("description", g.Description)
+	}
+
+	if g.LDAPGroupReference != "" {
+		values.Set("ldap_group_ref", g.LDAPGroupReference)
+	}
+
+	return values
+}
+
+// Identity represents the user associated with the credentials being used by the client.
+type Identity struct {
+	ID     string     `json:"id"`
+	Domain AuthDomain `json:"domain"`
+	Name   string     `json:"name"`
+
+	// Roles are the effective roles for the user, including those inherited from groups.
+	Roles []Role `json:"roles"`
+}
+
+// HasRole returns a boolean indicating whether the user has been granted the given role, either directly or via a
+// role which covers it e.g. a bucket level role.
+//
+// NOTE: This doesn't account for roles which imply other roles e.g. 'admin', use 'CheckPermissions' to determine
+// whether the user is allowed to perform a specific action.
+func (i *Identity) HasRole(role Role) bool {
+	for _, granted := range i.Roles {
+		if granted.Covers(role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WhoAmI returns the identity and effective roles of the user associated with the credentials being used by the
+// client, this may be used to fail fast before starting long running operations.
+func (c *Client) WhoAmI(ctx context.Context) (*Identity, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointWhoAmI,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var identity *Identity
+
+	err = json.Unmarshal(response.Body, &identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return identity, nil
+}
+
+// ListRoles returns the roles which are supported by the cluster.
+func (c *Client) ListRoles(ctx context.Context) ([]*RoleDefinition, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRBACRoles,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var roles []*RoleDefinition
+
+	err = json.Unmarshal(response.Body, &roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return roles, nil
+}
+
+// ListUsers returns all the local and external users.
+func (c *Client) ListUsers(ctx context.Context) ([]*User, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRBACUsers,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var users []*User
+
+	err = json.Unmarshal(response.Body, &users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetUser returns the given user, a 'UserNotFoundError' is returned if the user does not exist.
+func (c *Client) GetUser(ctx context.Context, domain AuthDomain, id string) (*User, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRBACUser.Format(string(domain), id),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleUserError(domain, id, response, err)
+	}
+
+	var user *User
+
+	err = json.Unmarshal(response.Body, &user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return user, nil
+}
+
+// CreateUser creates a new user, a 'UserExistsError' is returned if the user already exists.
+//
+// NOTE: The cluster doesn't distinguish between creating/updating users, the existence check is not atomic.
+func (c *Client) CreateUser(ctx context.Context, settings UserSettings) error {
+	_, err := c.GetUser(ctx, settings.domain(), settings.ID)
+	if err == nil {
+		return &UserExistsError{domain: settings.domain(), id: settings.ID}
+	}
+
+	if !IsUserNotFound(err) {
+		return err // Purposefully not wrapped
+	}
+
+	return c.upsertUser(ctx, settings)
+}
+
+// UpdateUser updates an existing user, a 'UserNotFoundError' is returned if the user does not exist.
+//
+// NOTE: The cluster doesn't distinguish between creating/updating users, the existence check is not atomic.
+func (c *Client) UpdateUser(ctx context.Context, settings UserSettings) error {
+	_, err := c.GetUser(ctx, settings.domain(), settings.ID)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	return c.upsertUser(ctx, settings)
+}
+
+// upsertUser creates/updates the given user.
+func (c *Client) upsertUser(ctx context.Context, settings UserSettings) error {
+	request := &Request{
+		Body:               []byte(settings.values().Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRBACUser.Format(string(settings.domain()), settings.ID),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPut,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleUserError(settings.domain(), settings.ID, response, err)
+	}
+
+	return nil
+}
+
+// DeleteUser deletes the given user, a 'UserNotFoundError' is returned if the user does not exist.
+func (c *Client) DeleteUser(ctx context.Context, domain AuthDomain, id string) error {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRBACUser.Format(string(domain), id),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodDelete,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleUserError(domain, id, response, err)
+	}
+
+	return nil
+}
+
+// ListGroups returns all the user groups.
+func (c *Client) ListGroups(ctx context.Context) ([]*Group, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRBACGroups,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var groups []*Group
+
+	err = json.Unmarshal(response.Body, &groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return groups, nil
+}
+
+// GetGroup returns the given group, a 'GroupNotFoundError' is returned if the group does not exist.
+func (c *Client) GetGroup(ctx context.Context, name string) (*Group, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRBACGroup.Format(name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleGroupError(name, response, err)
+	}
+
+	var group *Group
+
+	err = json.Unmarshal(response.Body, &group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return group, nil
+}
+
+// CreateGroup creates a new group, a 'GroupExistsError' is returned if the group already exists.
+//
+// NOTE: The cluster doesn't distinguish between creating/updating groups, the existence check is not atomic.
+func (c *Client) CreateGroup(ctx context.Context, settings GroupSettings) error {
+	_, err := c.GetGroup(ctx, settings.Name)
+	if err == nil {
+		return &GroupExistsError{name: settings.Name}
+	}
+
+	if !IsGroupNotFound(err) {
+		return err // Purposefully not wrapped
+	}
+
+	return c.upsertGroup(ctx, settings)
+}
+
+// UpdateGroup updates an existing group, a 'GroupNotFoundError' is returned if the group does not exist.
+//
+// NOTE: The cluster doesn't distinguish between creating/updating groups, the existence check is not atomic.
+func (c *Client) UpdateGroup(ctx context.Context, settings GroupSettings) error {
+	_, err := c.GetGroup(ctx, settings.Name)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	return c.upsertGroup(ctx, settings)
+}
+
+// upsertGroup creates/updates the given group.
+func (c *Client) upsertGroup(ctx context.Context, settings GroupSettings) error {
+	request := &Request{
+		Body:               []byte(settings.values().Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRBACGroup.Format(settings.Name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPut,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleGroupError(settings.Name, response, err)
+	}
+
+	return nil
+}
+
+// DeleteGroup deletes the given group, a 'GroupNotFoundError' is returned if the group does not exist.
+func (c *Client) DeleteGroup(ctx context.Context, name string) error {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRBACGroup.Format(name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodDelete,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleGroupError(name, response, err)
+	}
+
+	return nil
+}
+
+// handleUserError converts the error returned by a user management request into a typed error where possible.
+func handleUserError(domain AuthDomain, id string, response *Response, err error) error {
+	if response != nil && response.StatusCode == http.StatusNotFound {
+		return &UserNotFoundError{domain: domain, id: id}
+	}
+
+	return handleRBACError(fmt.Sprintf("user '%s/%s'", domain, id), response, err)
+}
+
+// handleGroupError converts the error returned by a group management request into a typed error where possible.
+func handleGroupError(name string, response *Response, err error) error {
+	if response != nil && response.StatusCode == http.StatusNotFound {
+		return &GroupNotFoundError{name: name}
+	}
+
+	return handleRBACError(fmt.Sprintf("group '%s'", name), response, err)
+}
+
+// handleRBACError converts the validation errors returned by 'ns_server' when creating/updating a user/group into an
+// 'RBACSettingsError'.
+func handleRBACError(what string, response *Response, err error) error {
+	if response == nil || response.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	type overlay struct {
+		Errors map[string]string `json:"errors"`
+	}
+
+	var decoded overlay
+
+	// Purposefully ignored, in the event that the body isn't in the expected format, we'll return the original error
+	_ = json.Unmarshal(response.Body, &decoded)
+
+	if len(decoded.Errors) != 0 {
+		return &RBACSettingsError{what: what, errors: decoded.Errors}
+	}
+
+	return fmt.Errorf("failed to execute request: %w", err)
+}
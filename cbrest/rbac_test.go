@@ -0,0 +1,354 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRole(t *testing.T) {
+	type test struct {
+		name     string
+		role     string
+		expected Role
+		valid    bool
+	}
+
+	tests := []*test{
+		{
+			name:     "Unqualified",
+			role:     "admin",
+			expected: Role{Name: "admin"},
+			valid:    true,
+		},
+		{
+			name:     "Bucket",
+			role:     "bucket_admin[default]",
+			expected: Role{Name: "bucket_admin", Bucket: "default"},
+			valid:    true,
+		},
+		{
+			name:     "Collection",
+			role:     "data_reader[default:s1:c1]",
+			expected: Role{Name: "data_reader", Bucket: "default", Scope: "s1", Collection: "c1"},
+			valid:    true,
+		},
+		{
+			name:     "Wildcard",
+			role:     "data_reader[*]",
+			expected: Role{Name: "data_reader", Bucket: "*"},
+			valid:    true,
+		},
+		{
+			name: "MissingBracket",
+			role: "data_reader[default",
+		},
+		{
+			name: "EmptyBucket",
+			role: "data_reader[]",
+		},
+		{
+			name: "TooManyQualifiers",
+			role: "data_reader[a:b:c:d]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			role, err := ParseRole(test.role)
+			if !test.valid {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, role)
+			require.Equal(t, test.role, role.String())
+		})
+	}
+}
+
+func TestRoleCovers(t *testing.T) {
+	collection := Role{Name: "data_reader", Bucket: "default", Scope: "s1", Collection: "c1"}
+
+	require.True(t, collection.Covers(collection))
+	require.True(t, Role{Name: "data_reader", Bucket: "default"}.Covers(collection))
+	require.True(t, Role{Name: "data_reader", Bucket: "*", Scope: "*", Collection: "*"}.Covers(collection))
+	require.False(t, Role{Name: "data_reader", Bucket: "other"}.Covers(collection))
+	require.False(t, Role{Name: "data_writer", Bucket: "default"}.Covers(collection))
+	require.False(t, collection.Covers(Role{Name: "data_reader", Bucket: "default"}))
+}
+
+func TestUserUnmarshalJSON(t *testing.T) {
+	data := []byte(`{
+  "id": "user",
+  "domain": "local",
+  "name": "User",
+  "roles": [
+    {"role": "admin", "origins": [{"type": "user"}]},
+    {"role": "data_reader", "bucket_name": "default", "origins": [{"type": "group", "name": "readers"}]},
+    {"role": "bucket_admin", "bucket_name": "default", "origins": [{"type": "user"}, {"type": "group", "name": "g"}]}
+  ],
+  "groups": ["readers", "g"],
+  "external_groups": [],
+  "password_change_date": "2022-11-01T12:00:00.000Z"
+}`)
+
+	var user *User
+
+	require.NoError(t, json.Unmarshal(data, &user))
+
+	expected := &User{
+		ID:             "user",
+		Domain:         AuthDomainLocal,
+		Name:           "User",
+		Roles:          []Role{{Name: "admin"}, {Name: "bucket_admin", Bucket: "default"}},
+		Groups:         []string{"readers", "g"},
+		ExternalGroups: []string{},
+	}
+
+	require.Equal(t, expected, user)
+}
+
+func TestUserSettingsValues(t *testing.T) {
+	settings := UserSettings{
+		ID:       "user",
+		Name:     "User",
+		Password: "password",
+		Roles:    []Role{{Name: "admin"}, {Name: "data_reader", Bucket: "default", Scope: "s1"}},
+		Groups:   []string{"g1", "g2"},
+	}
+
+	require.Equal(t, "groups=g1%2Cg2&name=User&password=password&roles=admin%2Cdata_reader%5Bdefault%3As1%5D",
+		settings.values().Encode())
+
+	settings.Domain = AuthDomainExternal
+
+	require.Equal(t, "groups=g1%2Cg2&name=User&roles=admin%2Cdata_reader%5Bdefault%3As1%5D",
+		settings.values().Encode())
+}
+
+func TestClientWhoAmI(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{
+		Users: TestUsers{
+			username: {
+				Password: password,
+				Roles:    []Role{{Name: "data_backup", Bucket: "default"}},
+				Groups:   []string{"readers"},
+			},
+		},
+		Groups: TestGroups{"readers": {Roles: []Role{{Name: "data_reader", Bucket: "*", Scope: "*", Collection: "*"}}}},
+	})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	identity, err := client.WhoAmI(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, username, identity.ID)
+	require.Equal(t, AuthDomainLocal, identity.Domain)
+
+	require.True(t, identity.HasRole(Role{Name: "data_backup", Bucket: "default"}))
+	require.True(t, identity.HasRole(Role{Name: "data_reader", Bucket: "other", Scope: "s1", Collection: "c1"}))
+	require.False(t, identity.HasRole(Role{Name: "data_backup", Bucket: "other"}))
+	require.False(t, identity.HasRole(Role{Name: "admin"}))
+}
+
+func TestClientWhoAmIAdmin(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	identity, err := client.WhoAmI(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &Identity{ID: username, Domain: AuthDomainAdmin, Roles: []Role{{Name: "admin"}}}, identity)
+}
+
+func TestClientListRoles(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	roles, err := client.ListRoles(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, testRoles, roles)
+}
+
+func TestClientUserManagement(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	users, err := client.ListUsers(ctx)
+	require.NoError(t, err)
+	require.Empty(t, users)
+
+	err = client.CreateGroup(ctx, GroupSettings{
+		Name:        "readers",
+		Description: "Readers",
+		Roles:       []Role{{Name: "data_reader", Bucket: "default", Scope: "s1", Collection: "c1"}},
+	})
+	require.NoError(t, err)
+
+	err = client.CreateUser(ctx, UserSettings{
+		ID:       "user",
+		Name:     "User",
+		Password: "password",
+		Roles:    []Role{{Name: "bucket_admin", Bucket: "default"}},
+		Groups:   []string{"readers"},
+	})
+	require.NoError(t, err)
+
+	err = client.CreateUser(ctx, UserSettings{Domain: AuthDomainExternal, ID: "ldap", Roles: []Role{{Name: "ro_admin"}}})
+	require.NoError(t, err)
+
+	user, err := client.GetUser(ctx, AuthDomainLocal, "user")
+	require.NoError(t, err)
+
+	expected := &User{
+		ID:     "user",
+		Domain: AuthDomainLocal,
+		Name:   "User",
+		Roles:  []Role{{Name: "bucket_admin", Bucket: "default"}},
+		Groups: []string{"readers"},
+	}
+
+	require.Equal(t, expected, user)
+
+	err = client.UpdateUser(ctx, UserSettings{ID: "user", Name: "Updated", Roles: []Role{{Name: "admin"}}})
+	require.NoError(t, err)
+
+	// The password should be retained when it's not supplied
+	require.Equal(t, "password", cluster.options.Users["user"].Password)
+
+	users, err = client.ListUsers(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	require.Equal(t, AuthDomainExternal, users[0].Domain)
+	require.Equal(t, "Updated", users[1].Name)
+	require.Equal(t, []Role{{Name: "admin"}}, users[1].Roles)
+	require.Empty(t, users[1].Groups)
+
+	group, err := client.GetGroup(ctx, "readers")
+	require.NoError(t, err)
+	require.Equal(t, &Group{
+		Name:        "readers",
+		Description: "Readers",
+		Roles:       []Role{{Name: "data_reader", Bucket: "default", Scope: "s1", Collection: "c1"}},
+	}, group)
+
+	err = client.UpdateGroup(ctx, GroupSettings{Name: "readers", LDAPGroupReference: "cn=readers"})
+	require.NoError(t, err)
+
+	groups, err := client.ListGroups(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*Group{{Name: "readers", Roles: []Role{}, LDAPGroupReference: "cn=readers"}}, groups)
+
+	require.NoError(t, client.DeleteUser(ctx, AuthDomainLocal, "user"))
+	require.NoError(t, client.DeleteGroup(ctx, "readers"))
+
+	_, err = client.GetUser(ctx, AuthDomainLocal, "user")
+	require.True(t, IsUserNotFound(err))
+
+	_, err = client.GetGroup(ctx, "readers")
+	require.True(t, IsGroupNotFound(err))
+}
+
+func TestClientUserManagementErrors(t *testing.T) {
+	type test struct {
+		name  string
+		fn    func(client *Client) error
+		check func(t *testing.T, err error)
+	}
+
+	tests := []*test{
+		{
+			name: "CreateUserExists",
+			fn: func(client *Client) error {
+				return client.CreateUser(context.Background(), UserSettings{ID: "user", Password: "password"})
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsUserExists(err)) },
+		},
+		{
+			name: "CreateUserInvalidSettings",
+			fn: func(client *Client) error {
+				return client.CreateUser(context.Background(), UserSettings{
+					ID:     "other",
+					Roles:  []Role{{Name: "unknown"}},
+					Groups: []string{"missing"},
+				})
+			},
+			check: func(t *testing.T, err error) {
+				var settingsErr *RBACSettingsError
+				require.ErrorAs(t, err, &settingsErr)
+				require.Equal(t, map[string]string{
+					"groups":   "Groups do not exist: [missing]",
+					"password": "The password must be at least 6 characters long.",
+					"roles":    "Unknown roles: [unknown]",
+				}, settingsErr.Errors())
+			},
+		},
+		{
+			name: "UpdateUserNotFound",
+			fn: func(client *Client) error {
+				return client.UpdateUser(context.Background(), UserSettings{Domain: AuthDomainExternal, ID: "user"})
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsUserNotFound(err)) },
+		},
+		{
+			name:  "DeleteUserNotFound",
+			fn:    func(client *Client) error { return client.DeleteUser(context.Background(), AuthDomainLocal, "other") },
+			check: func(t *testing.T, err error) { require.True(t, IsUserNotFound(err)) },
+		},
+		{
+			name:  "CreateGroupExists",
+			fn:    func(client *Client) error { return client.CreateGroup(context.Background(), GroupSettings{Name: "g"}) },
+			check: func(t *testing.T, err error) { require.True(t, IsGroupExists(err)) },
+		},
+		{
+			name: "UpdateGroupInvalidRole",
+			fn: func(client *Client) error {
+				return client.UpdateGroup(context.Background(), GroupSettings{
+					Name:  "g",
+					Roles: []Role{{Name: "admin", Bucket: "default"}},
+				})
+			},
+			check: func(t *testing.T, err error) {
+				var settingsErr *RBACSettingsError
+				require.ErrorAs(t, err, &settingsErr)
+				require.Equal(t, map[string]string{"roles": "Unknown roles: [admin[default]]"}, settingsErr.Errors())
+			},
+		},
+		{
+			name:  "DeleteGroupNotFound",
+			fn:    func(client *Client) error { return client.DeleteGroup(context.Background(), "other") },
+			check: func(t *testing.T, err error) { require.True(t, IsGroupNotFound(err)) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := NewTestCluster(t, TestClusterOptions{
+				Users:  TestUsers{"user": {Password: "password"}},
+				Groups: TestGroups{"g": {}},
+			})
+			defer cluster.Close()
+
+			client, err := newTestClient(cluster, true)
+			require.NoError(t, err)
+
+			test.check(t, test.fn(client))
+		})
+	}
+}
@@ -0,0 +1,490 @@
+package cbrest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// redacted is the value used in place of redacted values, a fixed length is used to avoid revealing any details about
+// the original value.
+const redacted = "*****"
+
+// DefaultMaxBodySize is the maximum size of a response body recorded by a recorder, when no size is provided.
+const DefaultMaxBodySize = 1024 * 1024
+
+// userDataPathRegex matches the path segments which contain user data e.g. bucket/user/document names, capturing the
+// preceding portion of the path and the user data.
+var userDataPathRegex = regexp.MustCompile(
+	`(/(?:buckets|bs|scopes|collections|docs|groups|users/(?:local|external)|link|index)/)([^/@][^/]*)`,
+)
+
+var (
+	// DefaultHeadersToMask are the headers whose values are redacted by a recorder, when none are provided.
+	DefaultHeadersToMask = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+	// DefaultFieldsToMask are the query parameters, form values and JSON attributes whose values are redacted by a
+	// recorder, when none are provided; these are credentials e.g. passwords, private keys, certificates and SAS
+	// signatures.
+	DefaultFieldsToMask = []string{
+		"password", "passphrase", "secret", "token", "key", "certificate", "cert", "sig", "signature", "credentials",
+	}
+
+	// DefaultFieldsToTag are the query parameters, form values and JSON attributes whose (string) values are user data
+	// and are surrounded by <ud></ud> tags by a recorder, when none are provided.
+	DefaultFieldsToTag = []string{"username", "user", "name", "bucket", "scope", "collection", "group"}
+)
+
+// RecorderOptions encapsulates the options which may be supplied when creating a recorder.
+type RecorderOptions struct {
+	// HeadersToMask are the (case insensitive) names of the headers whose values should be redacted, defaults to
+	// 'DefaultHeadersToMask'.
+	HeadersToMask []string
+
+	// FieldsToMask are the names of the query parameters, form values and JSON attributes whose values should be
+	// redacted, defaults to 'DefaultFieldsToMask'. Names are case insensitive, and also match fields where the name is
+	// the last word of a camelCase/snake_case name e.g. "key" matches "clientKey" and "secret_key".
+	FieldsToMask []string
+
+	// FieldsToTag are the names of the query parameters, form values and JSON attributes whose values are user data,
+	// defaults to 'DefaultFieldsToTag'; names are matched in the same way as 'FieldsToMask'.
+	FieldsToTag []string
+
+	// MaxBodySize is the maximum number of bytes of each response body which will be buffered, defaults to
+	// 'DefaultMaxBodySize'. Streaming responses (e.g. the cluster config stream) may be read for the lifetime of the
+	// client, so larger bodies are not recorded, see 'RecordedResponse.Truncated'.
+	MaxBodySize int
+}
+
+// Interaction is a single recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest   `json:"request"`
+	Response *RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded (and redacted) HTTP request.
+type RecordedRequest struct {
+	Method string       `json:"method"`
+	Path   string       `json:"path"`
+	Query  url.Values   `json:"query,omitempty"`
+	Header http.Header  `json:"header,omitempty"`
+	Body   RecordedBody `json:"body,omitempty"`
+}
+
+// RecordedResponse is a recorded (and redacted) HTTP response.
+type RecordedResponse struct {
+	StatusCode int          `json:"status_code"`
+	Header     http.Header  `json:"header,omitempty"`
+	Body       RecordedBody `json:"body,omitempty"`
+
+	// Truncated indicates that the body exceeded the maximum recorded size, the body is omitted entirely since a partial
+	// body can't be reliably redacted.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// RecordedBody is a recorded request/response body, JSON bodies are stored as JSON so that fixtures remain readable;
+// all other bodies are stored as strings.
+type RecordedBody []byte
+
+func (r RecordedBody) MarshalJSON() ([]byte, error) {
+	// Bodies which are JSON strings are encoded as a string, so that they're correctly decoded by 'UnmarshalJSON'
+	if json.Valid(r) && !bytes.HasPrefix(bytes.TrimSpace(r), []byte(`"`)) {
+		var buffer bytes.Buffer
+
+		err := json.Compact(&buffer, r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compact body: %w", err)
+		}
+
+		return buffer.Bytes(), nil
+	}
+
+	return json.Marshal(string(r))
+}
+
+func (r *RecordedBody) UnmarshalJSON(data []byte) error {
+	// JSON bodies may have been indented when the fixture was written, so are compacted to match the original body
+	if !bytes.HasPrefix(data, []byte(`"`)) {
+		var buffer bytes.Buffer
+
+		err := json.Compact(&buffer, data)
+		if err != nil {
+			return fmt.Errorf("failed to compact body: %w", err)
+		}
+
+		*r = buffer.Bytes()
+
+		return nil
+	}
+
+	var decoded string
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	*r = RecordedBody(decoded)
+
+	return nil
+}
+
+// Recorder records the requests dispatched by a REST client along with their responses, redacting any credentials and
+// tagging any user data (in the same way as the 'log' package), so that they can be saved as a fixture and replayed
+// using 'NewReplayHandlers'.
+type Recorder struct {
+	options RecorderOptions
+
+	lock         sync.Mutex
+	interactions []*Interaction
+}
+
+// NewRecorder creates a new recorder, populating any missing options with their defaults.
+func NewRecorder(options RecorderOptions) *Recorder {
+	if options.HeadersToMask == nil {
+		options.HeadersToMask = DefaultHeadersToMask
+	}
+
+	if options.FieldsToMask == nil {
+		options.FieldsToMask = DefaultFieldsToMask
+	}
+
+	if options.FieldsToTag == nil {
+		options.FieldsToTag = DefaultFieldsToTag
+	}
+
+	if options.MaxBodySize == 0 {
+		options.MaxBodySize = DefaultMaxBodySize
+	}
+
+	return &Recorder{options: options}
+}
+
+// Transport returns a transport which records the requests/responses sent/received using the given transport.
+func (r *Recorder) Transport(transport http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{recorder: r, transport: transport}
+}
+
+// Interactions returns the recorded interactions, in the order the requests were dispatched.
+//
+// NOTE: Interactions are only complete once the response body has been closed, incomplete interactions are omitted.
+func (r *Recorder) Interactions() []*Interaction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	interactions := make([]*Interaction, 0, len(r.interactions))
+
+	for _, interaction := range r.interactions {
+		if interaction.Response != nil {
+			interactions = append(interactions, interaction)
+		}
+	}
+
+	return interactions
+}
+
+// Save writes the recorded interactions to the given fixture file.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Interactions(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal interactions: %w", err)
+	}
+
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	return nil
+}
+
+// LoadInteractions loads the interactions from the given fixture file, which should have been created by a recorder.
+func LoadInteractions(path string) ([]*Interaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var interactions []*Interaction
+
+	err = json.Unmarshal(data, &interactions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal interactions: %w", err)
+	}
+
+	return interactions, nil
+}
+
+// record begins recording the given request, returning the interaction which should be completed with the response.
+func (r *Recorder) record(req *http.Request, body []byte) *Interaction {
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   tagUserDataPath(req.URL.Path),
+			Query:  r.redactValues(req.URL.Query()),
+			Header: r.redactHeader(req.Header),
+			Body:   r.redactBody(req.Header.Get("Content-Type"), body),
+		},
+	}
+
+	if len(interaction.Request.Query) == 0 {
+		interaction.Request.Query = nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.interactions = append(r.interactions, interaction)
+
+	return interaction
+}
+
+// complete completes the given interaction, recording the given response; the body is omitted if it was truncated.
+func (r *Recorder) complete(interaction *Interaction, resp *http.Response, body []byte, truncated bool) {
+	response := &RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     r.redactHeader(resp.Header),
+		Truncated:  truncated,
+	}
+
+	if !truncated {
+		response.Body = r.redactBody(resp.Header.Get("Content-Type"), body)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	interaction.Response = response
+}
+
+// redactHeader returns a copy of the given header, with the values of any sensitive headers redacted.
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	redactedHeader := header.Clone()
+
+	for _, name := range r.options.HeadersToMask {
+		if redactedHeader.Get(name) != "" {
+			redactedHeader.Set(name, redacted)
+		}
+	}
+
+	return redactedHeader
+}
+
+// redactValues redacts the sensitive query parameters/form values in the given values, and tags any user data.
+func (r *Recorder) redactValues(values url.Values) url.Values {
+	for key, value := range values {
+		switch {
+		case fieldMatches(key, r.options.FieldsToMask):
+			values[key] = []string{redacted}
+		case fieldMatches(key, r.options.FieldsToTag):
+			for index := range value {
+				value[index] = tagUserData(value[index])
+			}
+		}
+	}
+
+	return values
+}
+
+// redactBody returns a copy of the given body, redacting any sensitive values and tagging any user data in form/JSON
+// encoded bodies.
+//
+// NOTE: Bodies which fail to parse are recorded as is.
+func (r *Recorder) redactBody(contentType string, body []byte) RecordedBody {
+	if len(body) == 0 {
+		return nil
+	}
+
+	if strings.HasPrefix(contentType, string(ContentTypeURLEncoded)) {
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			return RecordedBody(r.redactValues(values).Encode())
+		}
+	}
+
+	var decoded any
+	if json.Unmarshal(body, &decoded) != nil {
+		return append(RecordedBody(nil), body...)
+	}
+
+	encoded, err := json.Marshal(r.redactJSON(decoded))
+	if err != nil {
+		return append(RecordedBody(nil), body...)
+	}
+
+	return encoded
+}
+
+// redactJSON recursively redacts the values of any sensitive attributes in the given decoded JSON value, and tags any
+// user data.
+func (r *Recorder) redactJSON(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, nested := range typed {
+			str, isString := nested.(string)
+
+			switch {
+			case fieldMatches(key, r.options.FieldsToMask):
+				typed[key] = redacted
+			case isString && fieldMatches(key, r.options.FieldsToTag):
+				typed[key] = tagUserData(str)
+			default:
+				typed[key] = r.redactJSON(nested)
+			}
+		}
+	case []any:
+		for index, nested := range typed {
+			typed[index] = r.redactJSON(nested)
+		}
+	}
+
+	return value
+}
+
+// fieldMatches returns a boolean indicating whether the given field matches any of the given (case insensitive) names,
+// either exactly or where the name is the last word of a camelCase/snake_case/kebab-case field.
+func fieldMatches(field string, names []string) bool {
+	for _, name := range names {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+
+		if len(field) <= len(name) || !strings.EqualFold(field[len(field)-len(name):], name) {
+			continue
+		}
+
+		var (
+			prev  = rune(field[len(field)-len(name)-1])
+			first = rune(field[len(field)-len(name)])
+		)
+
+		if prev == '_' || prev == '-' || (unicode.IsLower(prev) && unicode.IsUpper(first)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tagUserData surrounds the given user data with <ud></ud> tags, in the same way as the 'log' package.
+func tagUserData(data string) string {
+	if data == "" {
+		return data
+	}
+
+	return "<ud>" + data + "</ud>"
+}
+
+// tagUserDataPath tags the segments of the given path which contain user data e.g. bucket/user/document names.
+func tagUserDataPath(path string) string {
+	return userDataPathRegex.ReplaceAllString(path, "${1}<ud>${2}</ud>")
+}
+
+// untagUserData removes any <ud></ud> tags from the given data, returning the original data.
+//
+// NOTE: JSON bodies are HTML escaped when they're marshalled, so the escaped tags are also removed.
+func untagUserData(data string) string {
+	return strings.NewReplacer("<ud>", "", "</ud>", "", `\u003cud\u003e`, "", `\u003c/ud\u003e`, "").Replace(data)
+}
+
+// recordingTransport is a transport which records requests/responses using a recorder.
+type recordingTransport struct {
+	recorder  *Recorder
+	transport http.RoundTripper
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	interaction := r.recorder.record(req, body)
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	// The response body is recorded as it's read, rather than upfront, so that streaming responses are not blocked
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		max:        r.recorder.options.MaxBodySize,
+		complete: func(body []byte, truncated bool) {
+			r.recorder.complete(interaction, resp, body, truncated)
+		},
+	}
+
+	return resp, nil
+}
+
+// readRequestBody returns a copy of the body of the given request, without consuming the body itself.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	// Requests created by the client always have 'GetBody' set, however, we fallback to buffering the body otherwise
+	if req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err // Purposefully not wrapped
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		return body, nil
+	}
+
+	reader, err := req.GetBody()
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// recordingBody is a response body which records the data read from it, completing the interaction once closed.
+//
+// NOTE: At most 'max' bytes are buffered, once exceeded the buffered data is discarded and the body is truncated.
+type recordingBody struct {
+	io.ReadCloser
+
+	max       int
+	buffer    bytes.Buffer
+	truncated bool
+	once      sync.Once
+	complete  func(body []byte, truncated bool)
+}
+
+func (r *recordingBody) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+
+	if !r.truncated && r.buffer.Len()+n > r.max {
+		r.buffer, r.truncated = bytes.Buffer{}, true
+	}
+
+	if !r.truncated {
+		r.buffer.Write(p[:n])
+	}
+
+	return n, err
+}
+
+func (r *recordingBody) Close() error {
+	r.once.Do(func() { r.complete(r.buffer.Bytes(), r.truncated) })
+	return r.ReadCloser.Close()
+}
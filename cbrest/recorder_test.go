@@ -0,0 +1,204 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/aprov"
+	"github.com/couchbase/tools-common/testutil"
+)
+
+func TestRecordedBodyMarshalJSON(t *testing.T) {
+	type test struct {
+		name     string
+		body     RecordedBody
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name:     "JSON",
+			body:     RecordedBody(`{ "key": "value" }`),
+			expected: `{"body":{"key":"value"}}`,
+		},
+		{
+			name:     "Text",
+			body:     RecordedBody("key=value"),
+			expected: `{"body":"key=value"}`,
+		},
+		{
+			name:     "JSONString",
+			body:     RecordedBody(`""`),
+			expected: `{"body":"\"\""}`,
+		},
+		{
+			name:     "Empty",
+			expected: `{}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			type overlay struct {
+				Body RecordedBody `json:"body,omitempty"`
+			}
+
+			data, err := json.Marshal(overlay{Body: test.body})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(data))
+
+			var decoded overlay
+
+			require.NoError(t, json.Unmarshal(data, &decoded))
+
+			if len(test.body) == 0 {
+				require.Empty(t, decoded.Body)
+				return
+			}
+
+			if test.name == "JSON" {
+				require.JSONEq(t, string(test.body), string(decoded.Body))
+				return
+			}
+
+			require.Equal(t, test.body, decoded.Body)
+		})
+	}
+}
+
+func TestRecorderRedaction(t *testing.T) {
+	recorder := NewRecorder(RecorderOptions{})
+
+	header := http.Header{"Authorization": {"Basic abc"}, "User-Agent": {"agent"}}
+	require.Equal(t, http.Header{"Authorization": {"*****"}, "User-Agent": {"agent"}}, recorder.redactHeader(header))
+	require.Equal(t, "Basic abc", header.Get("Authorization"))
+
+	require.Equal(t, url.Values{"name": {"<ud>user</ud>"}, "password": {"*****"}, "sig": {"*****"}, "sv": {"2020"}},
+		recorder.redactValues(url.Values{
+			"name": {"user"}, "password": {"secret"}, "sig": {"signature"}, "sv": {"2020"},
+		}))
+
+	require.Equal(t,
+		RecordedBody("certificate=%2A%2A%2A%2A%2A&clientKey=%2A%2A%2A%2A%2A&username=%3Cud%3Euser%3C%2Fud%3E"),
+		recorder.redactBody(string(ContentTypeURLEncoded), []byte("username=user&clientKey=pem&certificate=pem")))
+
+	require.JSONEq(t, `{"nodes":[{"hostname":"h","secretKey":"*****"}],"Token":"*****","monkey":"m",`+
+		`"bucket":"<ud>b</ud>","key":"*****","scope":{"uid":"1"}}`,
+		string(recorder.redactBody(string(ContentTypeJSON),
+			[]byte(`{"nodes":[{"hostname":"h","secretKey":"abc"}],"Token":"abc","monkey":"m","bucket":"b",`+
+				`"key":"abc","scope":{"uid":"1"}}`))))
+
+	require.Equal(t, "/pools/default/buckets/<ud>b</ud>/scopes/<ud>s</ud>/collections/<ud>c</ud>",
+		tagUserDataPath("/pools/default/buckets/b/scopes/s/collections/c"))
+	require.Equal(t, "/settings/rbac/users/local/<ud>u</ud>", tagUserDataPath("/settings/rbac/users/local/u"))
+	require.Equal(t, "/pools/default/buckets/<ud>b</ud>/scopes/@ensureManifest/1",
+		tagUserDataPath("/pools/default/buckets/b/scopes/@ensureManifest/1"))
+
+	require.Equal(t, RecordedBody("not json"), recorder.redactBody("", []byte("not json")))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodPost, "/test/buckets/b", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", string(ContentTypeJSON))
+		testutil.Write(t, writer, []byte(`{"token":"abc","value":1,"bucket":"b"}`))
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	recorder := NewRecorder(RecorderOptions{})
+
+	client, err := NewClient(ClientOptions{
+		ConnectionString: cluster.URL(),
+		DisableCCP:       true,
+		Provider:         &aprov.Static{Username: username, Password: password, UserAgent: userAgent},
+		Recorder:         recorder,
+	})
+	require.NoError(t, err)
+
+	request := &Request{
+		Body:               []byte("password=secret"),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           "/test/buckets/b",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	_, err = client.ExecuteWithContext(context.Background(), request)
+	require.NoError(t, err)
+
+	fixture := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, recorder.Save(fixture))
+
+	// Credentials/user data should not be written to the fixture
+	data, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret")
+	require.NotContains(t, string(data), `"abc"`)
+	require.NotContains(t, string(data), "Basic")
+
+	interactions, err := LoadInteractions(fixture)
+	require.NoError(t, err)
+	require.Equal(t, recorder.Interactions(), interactions)
+
+	last := interactions[len(interactions)-1]
+	require.Equal(t, "/test/buckets/<ud>b</ud>", last.Request.Path)
+	require.Equal(t, RecordedBody("password=%2A%2A%2A%2A%2A"), last.Request.Body)
+
+	replayHandlers := NewReplayHandlers(t, fixture)
+
+	// The topology endpoints should not be replayed, they're handled by the test cluster
+	require.NotContains(t, replayHandlers, "GET:/pools/default/nodeServices")
+	require.Contains(t, replayHandlers, "GET:/pools")
+
+	replay := NewTestCluster(t, TestClusterOptions{Handlers: replayHandlers})
+	defer replay.Close()
+
+	client, err = newTestClient(replay, true)
+	require.NoError(t, err)
+
+	response, err := client.ExecuteWithContext(context.Background(), request)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"token":"*****","value":1,"bucket":"b"}`, string(response.Body))
+}
+
+func TestRecordStreamingResponse(t *testing.T) {
+	server := httptest.NewServer(NewTestHandlerWithStream(t, 100, []byte(`{"rev":1,"password":"secret"}`)))
+	defer server.Close()
+
+	recorder := NewRecorder(RecorderOptions{MaxBodySize: 64})
+
+	client := &http.Client{Transport: recorder.Transport(http.DefaultTransport)}
+
+	resp, err := client.Get(server.URL + "/pools/default/nodeServicesStreaming")
+	require.NoError(t, err)
+
+	body, ok := resp.Body.(*recordingBody)
+	require.True(t, ok)
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+
+	// The stream should not be buffered once it exceeds the maximum body size
+	require.True(t, body.truncated)
+	require.Zero(t, body.buffer.Len())
+
+	require.NoError(t, resp.Body.Close())
+
+	interactions := recorder.Interactions()
+	require.Len(t, interactions, 1)
+	require.Equal(t, http.StatusOK, interactions[0].Response.StatusCode)
+	require.True(t, interactions[0].Response.Truncated)
+	require.Nil(t, interactions[0].Response.Body)
+}
@@ -72,6 +72,10 @@ type Request struct {
 
 	// NoRetryOnStatusCodes is a list of status codes which will explicitly not be retried.
 	NoRetryOnStatusCodes []int
+
+	// host is the host which the request should be dispatched to, when empty, the host is chosen by the client's host
+	// selector.
+	host string
 }
 
 // IsIdempotent returns a boolean indicating whether this request is idempotent and may be retried.
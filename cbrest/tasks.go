@@ -0,0 +1,440 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// TaskType represents the type of a long running task reported by the cluster.
+//
+// NOTE: This is not an exhaustive list, tasks of other types are decoded using the attributes common to all tasks.
+type TaskType string
+
+const (
+	// TaskTypeRebalance is a rebalance or graceful failover.
+	TaskTypeRebalance TaskType = "rebalance"
+
+	// TaskTypeXDCR is an XDCR replication.
+	TaskTypeXDCR TaskType = "xdcr"
+
+	// TaskTypeBucketCompaction is the compaction of the data files for a bucket.
+	TaskTypeBucketCompaction TaskType = "bucket_compaction"
+
+	// TaskTypeViewCompaction is the compaction of the view index files for a design document.
+	TaskTypeViewCompaction TaskType = "view_compaction"
+
+	// TaskTypeLoadingSampleBucket is the loading of a sample bucket.
+	TaskTypeLoadingSampleBucket TaskType = "loadingSampleBucket"
+
+	// TaskTypeBackup is a Backup Service task e.g. a backup or merge, the subtype is the type of the task.
+	//
+	// NOTE: These tasks are not reported by 'ns_server', they're reported by the task history of the repository they
+	// were run against, see 'ListBackupTasks'.
+	TaskTypeBackup TaskType = "backup"
+)
+
+// TaskStatus represents the status of a long running task.
+type TaskStatus string
+
+const (
+	// TaskStatusRunning indicates that the task is running.
+	TaskStatusRunning TaskStatus = "running"
+
+	// TaskStatusNotRunning indicates that the task is not running, for example, a rebalance which has completed.
+	TaskStatusNotRunning TaskStatus = "notRunning"
+
+	// TaskStatusPaused indicates that the task has been paused, for example, a paused XDCR replication.
+	TaskStatusPaused TaskStatus = "paused"
+)
+
+// Task represents a long running task reported by the cluster, attributes which aren't relevant to the task type will
+// have their zero value.
+type Task struct {
+	// ID is the unique identifier for the task, this is not reported for all task types e.g. compaction.
+	ID      string
+	Type    TaskType
+	Subtype string
+	Status  TaskStatus
+
+	// Progress is the percentage (0-100) of the task which has been completed.
+	Progress float64
+
+	// PerNode is the percentage (0-100) of the task which has been completed on each node; this is only reported for
+	// rebalances (keyed by the OTP name of the node) and backup tasks (keyed by the Backup Service node id).
+	PerNode map[string]float64
+
+	// Bucket is the bucket the task relates to, for XDCR this is the source bucket.
+	Bucket string
+
+	// The following attributes are only reported for XDCR replications.
+	Target      string
+	ChangesLeft uint64
+	DocsChecked uint64
+	DocsWritten uint64
+	Errors      []string
+
+	// ErrorMessage is populated when a rebalance or backup task has failed.
+	ErrorMessage string
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, normalizing the differences between the attributes
+// reported for each type of task.
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type overlay struct {
+		StatusID string     `json:"statusId"`
+		ID       string     `json:"id"`
+		Type     TaskType   `json:"type"`
+		Subtype  string     `json:"subtype"`
+		Status   TaskStatus `json:"status"`
+		Progress float64    `json:"progress"`
+		PerNode  map[string]struct {
+			Progress float64 `json:"progress"`
+		} `json:"perNode"`
+		Bucket       string            `json:"bucket"`
+		Source       string            `json:"source"`
+		Target       string            `json:"target"`
+		ChangesLeft  uint64            `json:"changesLeft"`
+		DocsChecked  uint64            `json:"docsChecked"`
+		DocsWritten  uint64            `json:"docsWritten"`
+		Errors       []json.RawMessage `json:"errors"`
+		ErrorMessage string            `json:"errorMessage"`
+	}
+
+	var decoded overlay
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	*t = Task{
+		ID:           decoded.ID,
+		Type:         decoded.Type,
+		Subtype:      decoded.Subtype,
+		Status:       decoded.Status,
+		Progress:     decoded.Progress,
+		Bucket:       decoded.Bucket,
+		Target:       decoded.Target,
+		ChangesLeft:  decoded.ChangesLeft,
+		DocsChecked:  decoded.DocsChecked,
+		DocsWritten:  decoded.DocsWritten,
+		ErrorMessage: decoded.ErrorMessage,
+	}
+
+	if t.ID == "" {
+		t.ID = decoded.StatusID
+	}
+
+	if t.Bucket == "" {
+		t.Bucket = decoded.Source
+	}
+
+	if len(decoded.PerNode) != 0 {
+		t.PerNode = make(map[string]float64, len(decoded.PerNode))
+	}
+
+	for node, progress := range decoded.PerNode {
+		t.PerNode[node] = progress.Progress
+	}
+
+	for _, raw := range decoded.Errors {
+		t.Errors = append(t.Errors, decodeTaskError(raw))
+	}
+
+	return nil
+}
+
+// decodeTaskError decodes an XDCR error, older versions of Couchbase Server report errors as strings, whilst newer
+// versions report them as objects which include the time the error occurred.
+func decodeTaskError(raw json.RawMessage) string {
+	var msg string
+	if json.Unmarshal(raw, &msg) == nil {
+		return msg
+	}
+
+	var decoded struct {
+		Message string `json:"errorMsg"`
+	}
+
+	// Purposefully ignored, fallback to returning the raw error if it's not in a known format
+	if json.Unmarshal(raw, &decoded) == nil && decoded.Message != "" {
+		return decoded.Message
+	}
+
+	return string(raw)
+}
+
+// Running returns a boolean indicating whether the task is running.
+func (t *Task) Running() bool {
+	return t.Status == TaskStatusRunning
+}
+
+// Paused returns a boolean indicating whether the task has been paused, paused tasks are not running but also haven't
+// completed.
+func (t *Task) Paused() bool {
+	return t.Status == TaskStatusPaused
+}
+
+// TaskMatcher is used to select a single task from those reported by the cluster.
+type TaskMatcher func(task *Task) bool
+
+// MatchTaskType returns a matcher which selects the first task with the given type.
+func MatchTaskType(taskType TaskType) TaskMatcher {
+	return func(task *Task) bool { return task.Type == taskType }
+}
+
+// MatchTaskID returns a matcher which selects the task with the given type/id.
+func MatchTaskID(taskType TaskType, id string) TaskMatcher {
+	return func(task *Task) bool { return task.Type == taskType && task.ID == id }
+}
+
+// MatchTaskBucket returns a matcher which selects the first task with the given type for the given bucket.
+func MatchTaskBucket(taskType TaskType, bucket string) TaskMatcher {
+	return func(task *Task) bool { return task.Type == taskType && task.Bucket == bucket }
+}
+
+// WaitForTaskOptions encapsulates the options which may be supplied when waiting for a task to complete.
+type WaitForTaskOptions struct {
+	// ProgressFunc is called with the current state of the task each time it's polled.
+	ProgressFunc func(task *Task)
+
+	// Timeout is the maximum time to wait for the task to complete, where zero indicates that the client poll timeout
+	// should be used and a negative value indicates that only the given context should be used to control the timeout.
+	Timeout time.Duration
+
+	// BackupRepository is the active Backup Service repository whose tasks should be waited on, when provided, tasks are
+	// selected from those returned by 'ListBackupTasks' rather than 'ListTasks'.
+	BackupRepository string
+}
+
+// ListTasks returns the long running tasks reported by the cluster.
+func (c *Client) ListTasks(ctx context.Context) ([]*Task, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointTasks,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var tasks []*Task
+
+	err = json.Unmarshal(response.Body, &tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ListBackupTasks returns the tasks in the task history of the given active Backup Service repository, the tasks will
+// be of type 'TaskTypeBackup'.
+func (c *Client) ListBackupTasks(ctx context.Context, repository string) ([]*Task, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBackupTaskHistory.Format(repository),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceBackup,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var decoded []backupTask
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	tasks := make([]*Task, 0, len(decoded))
+
+	for _, task := range decoded {
+		tasks = append(tasks, task.task())
+	}
+
+	return tasks, nil
+}
+
+// GetTask returns the first task selected by the given matcher, or <nil> if no tasks match.
+func (c *Client) GetTask(ctx context.Context, match TaskMatcher) (*Task, error) {
+	tasks, err := c.ListTasks(ctx)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	return matchTask(tasks, match), nil
+}
+
+// GetBackupTask returns the first task from the task history of the given active Backup Service repository which is
+// selected by the given matcher, or <nil> if no tasks match.
+func (c *Client) GetBackupTask(ctx context.Context, repository string, match TaskMatcher) (*Task, error) {
+	tasks, err := c.ListBackupTasks(ctx, repository)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	return matchTask(tasks, match), nil
+}
+
+// matchTask returns the first of the given tasks selected by the given matcher, or <nil> if no tasks match.
+func matchTask(tasks []*Task, match TaskMatcher) *Task {
+	idx := slices.IndexFunc(tasks, match)
+	if idx == -1 {
+		return nil
+	}
+
+	return tasks[idx]
+}
+
+// WaitForTask blocks until the task selected by the given matcher is no longer running, returning its final state; a
+// 'TaskFailedError' is returned if the task reports a failure, a 'TaskPausedError' if the task is paused, and a
+// 'TaskTimeoutError' if it doesn't complete in time.
+//
+// NOTE: Some tasks (e.g. compaction) are no longer reported once they complete, therefore, a task which is no longer
+// reported after previously being reported is considered complete and the returned task will be <nil>. Tasks which have
+// not been reported yet (e.g. a task which has only just been started) are waited on until they're reported.
+func (c *Client) WaitForTask(ctx context.Context, match TaskMatcher, options WaitForTaskOptions) (*Task, error) {
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = c.pollTimeout
+	}
+
+	if timeout > 0 {
+		var cancelFunc context.CancelFunc
+
+		ctx, cancelFunc = context.WithTimeout(ctx, timeout)
+		defer cancelFunc()
+	}
+
+	var (
+		last *Task
+		seen bool
+	)
+
+	getTask := func(ctx context.Context) (*Task, error) {
+		if options.BackupRepository != "" {
+			return c.GetBackupTask(ctx, options.BackupRepository, match)
+		}
+
+		return c.GetTask(ctx, match)
+	}
+
+	timedOut, err := c.PollRequestsWithContext(ctx, func(attempt int) (bool, error) {
+		task, err := getTask(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		if task == nil && !seen {
+			return false, nil
+		}
+
+		if task == nil {
+			last = nil
+			return true, nil
+		}
+
+		last, seen = task, true
+
+		if options.ProgressFunc != nil {
+			options.ProgressFunc(task)
+		}
+
+		if task.Running() {
+			return false, nil
+		}
+
+		if task.Paused() {
+			return false, &TaskPausedError{task: task}
+		}
+
+		if task.ErrorMessage != "" {
+			return false, &TaskFailedError{task: task}
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	if timedOut {
+		return nil, &TaskTimeoutError{task: last}
+	}
+
+	return last, nil
+}
+
+// backupTaskStatus represents the status of a task reported by the Backup Service.
+type backupTaskStatus string
+
+const (
+	backupTaskStatusWaiting backupTaskStatus = "waiting"
+	backupTaskStatusRunning backupTaskStatus = "running"
+	backupTaskStatusFailed  backupTaskStatus = "failed"
+)
+
+// backupTask represents a task in the task history of a Backup Service repository.
+type backupTask struct {
+	Name      string           `json:"task_name"`
+	Type      string           `json:"type"`
+	Status    backupTaskStatus `json:"status"`
+	Error     string           `json:"error"`
+	ErrorCode int              `json:"error_code"`
+	NodeRuns  []struct {
+		NodeID   string  `json:"node_id"`
+		Progress float64 `json:"progress"`
+	} `json:"node_runs"`
+}
+
+// task converts the Backup Service task into a task of type 'TaskTypeBackup', where the progress is the average
+// progress of the task across all the nodes it's being run on.
+func (b backupTask) task() *Task {
+	task := &Task{
+		ID:      b.Name,
+		Type:    TaskTypeBackup,
+		Subtype: strings.ToLower(b.Type),
+		Status:  TaskStatusNotRunning,
+	}
+
+	if b.Status == backupTaskStatusWaiting || b.Status == backupTaskStatusRunning {
+		task.Status = TaskStatusRunning
+	}
+
+	if len(b.NodeRuns) != 0 {
+		task.PerNode = make(map[string]float64, len(b.NodeRuns))
+	}
+
+	for _, run := range b.NodeRuns {
+		task.PerNode[run.NodeID] = run.Progress
+		task.Progress += run.Progress / float64(len(b.NodeRuns))
+	}
+
+	if b.Status != backupTaskStatusFailed {
+		return task
+	}
+
+	task.ErrorMessage = b.Error
+
+	// Ensure the failure is reported, even if the Backup Service didn't provide a reason
+	if task.ErrorMessage == "" {
+		task.ErrorMessage = fmt.Sprintf("task failed with error code %d", b.ErrorCode)
+	}
+
+	return task
+}
@@ -0,0 +1,394 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/testutil"
+)
+
+func TestTaskUnmarshalJSON(t *testing.T) {
+	type test struct {
+		name     string
+		data     string
+		expected *Task
+	}
+
+	tests := []*test{
+		{
+			name: "Rebalance",
+			data: `{"statusId":"id","type":"rebalance","subtype":"rebalance","recommendedRefreshPeriod":0.25,` +
+				`"status":"running","progress":37.5,"perNode":{"ns_1@172.20.1.1":{"progress":25},` +
+				`"ns_1@172.20.1.2":{"progress":50}},"detailedProgress":{}}`,
+			expected: &Task{
+				ID:       "id",
+				Type:     TaskTypeRebalance,
+				Subtype:  "rebalance",
+				Status:   TaskStatusRunning,
+				Progress: 37.5,
+				PerNode:  map[string]float64{"ns_1@172.20.1.1": 25, "ns_1@172.20.1.2": 50},
+			},
+		},
+		{
+			name: "RebalanceFailed",
+			data: `{"type":"rebalance","status":"notRunning","statusIsStale":false,"masterRequestTimedOut":false,` +
+				`"errorMessage":"Rebalance failed. See logs for detailed reason. You can try again."}`,
+			expected: &Task{
+				Type:         TaskTypeRebalance,
+				Status:       TaskStatusNotRunning,
+				ErrorMessage: "Rebalance failed. See logs for detailed reason. You can try again.",
+			},
+		},
+		{
+			name: "XDCR",
+			data: `{"id":"uuid/source/target","type":"xdcr","status":"running","source":"source",` +
+				`"target":"/remoteClusters/uuid/buckets/target","changesLeft":10,"docsChecked":20,"docsWritten":30,` +
+				`"errors":["legacy error",{"time":"2022-11-01T12:00:00Z","errorMsg":"error"}]}`,
+			expected: &Task{
+				ID:          "uuid/source/target",
+				Type:        TaskTypeXDCR,
+				Status:      TaskStatusRunning,
+				Bucket:      "source",
+				Target:      "/remoteClusters/uuid/buckets/target",
+				ChangesLeft: 10,
+				DocsChecked: 20,
+				DocsWritten: 30,
+				Errors:      []string{"legacy error", "error"},
+			},
+		},
+		{
+			name: "BucketCompaction",
+			data: `{"type":"bucket_compaction","status":"running","bucket":"default","progress":50,` +
+				`"cancelURI":"/pools/default/buckets/default/controller/cancelBucketCompaction"}`,
+			expected: &Task{
+				Type:     TaskTypeBucketCompaction,
+				Status:   TaskStatusRunning,
+				Progress: 50,
+				Bucket:   "default",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var task *Task
+
+			require.NoError(t, json.Unmarshal([]byte(test.data), &task))
+			require.Equal(t, test.expected, task)
+		})
+	}
+}
+
+func TestClientGetTask(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointTasks), NewTestHandler(t, http.StatusOK, []byte(`[
+  {"type":"rebalance","status":"notRunning"},
+  {"type":"bucket_compaction","status":"running","bucket":"default","progress":50},
+  {"type":"bucket_compaction","status":"running","bucket":"other","progress":25}
+]`)))
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	tasks, err := client.ListTasks(context.Background())
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+
+	task, err := client.GetTask(context.Background(), MatchTaskBucket(TaskTypeBucketCompaction, "other"))
+	require.NoError(t, err)
+	require.Equal(t, &Task{Type: TaskTypeBucketCompaction, Status: TaskStatusRunning, Progress: 25, Bucket: "other"},
+		task)
+
+	task, err = client.GetTask(context.Background(), MatchTaskType(TaskTypeXDCR))
+	require.NoError(t, err)
+	require.Nil(t, task)
+}
+
+func TestClientWaitForTask(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{}, {}}, RebalancePolls: 2})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	require.NoError(t, client.StartRebalance(context.Background(), "n_1@127.0.0.1"))
+
+	var progress []float64
+
+	task, err := client.WaitForTask(context.Background(), MatchTaskType(TaskTypeRebalance), WaitForTaskOptions{
+		ProgressFunc: func(task *Task) { progress = append(progress, task.Progress) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, TaskStatusNotRunning, task.Status)
+	require.Equal(t, []float64{50, 0}, progress)
+
+	require.Len(t, cluster.Nodes(), 1)
+}
+
+func TestClientWaitForTaskFailed(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{}, {}}, RebalanceError: "Rebalance failed."})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	require.NoError(t, client.StartRebalance(context.Background(), "n_1@127.0.0.1"))
+
+	_, err = client.WaitForTask(context.Background(), MatchTaskType(TaskTypeRebalance), WaitForTaskOptions{})
+	require.True(t, IsTaskFailed(err))
+
+	var failed *TaskFailedError
+	require.ErrorAs(t, err, &failed)
+	require.Equal(t, "Rebalance failed.", failed.Task().ErrorMessage)
+}
+
+func TestClientWaitForTaskNoLongerReported(t *testing.T) {
+	var polls int
+
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointTasks), func(writer http.ResponseWriter, request *http.Request) {
+		defer func() { polls++ }()
+
+		if polls > 0 {
+			testutil.Write(t, writer, []byte(`[]`))
+			return
+		}
+
+		testutil.Write(t, writer, []byte(`[{"type":"bucket_compaction","status":"running","bucket":"default"}]`))
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	task, err := client.WaitForTask(context.Background(), MatchTaskBucket(TaskTypeBucketCompaction, "default"),
+		WaitForTaskOptions{})
+	require.NoError(t, err)
+	require.Nil(t, task)
+	require.Equal(t, 2, polls)
+}
+
+func TestClientWaitForTaskNotYetReported(t *testing.T) {
+	var polls int
+
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointTasks), func(writer http.ResponseWriter, request *http.Request) {
+		defer func() { polls++ }()
+
+		switch polls {
+		case 0:
+			testutil.Write(t, writer, []byte(`[]`))
+		case 1:
+			testutil.Write(t, writer, []byte(`[{"type":"rebalance","status":"running","progress":50}]`))
+		default:
+			testutil.Write(t, writer, []byte(`[{"type":"rebalance","status":"notRunning"}]`))
+		}
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	task, err := client.WaitForTask(context.Background(), MatchTaskType(TaskTypeRebalance), WaitForTaskOptions{})
+	require.NoError(t, err)
+	require.Equal(t, TaskStatusNotRunning, task.Status)
+	require.Equal(t, 3, polls)
+}
+
+func TestClientWaitForTaskNeverReported(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointTasks), NewTestHandler(t, http.StatusOK, []byte(`[]`)))
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	_, err = client.WaitForTask(context.Background(), MatchTaskType(TaskTypeRebalance), WaitForTaskOptions{
+		Timeout: 100 * time.Millisecond,
+	})
+	require.True(t, IsTaskTimeout(err))
+
+	var timeout *TaskTimeoutError
+	require.ErrorAs(t, err, &timeout)
+	require.Nil(t, timeout.Task())
+}
+
+func TestClientWaitForTaskPaused(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointTasks), NewTestHandler(t, http.StatusOK,
+		[]byte(`[{"type":"xdcr","id":"replication","status":"paused","source":"default","changesLeft":10}]`)))
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	_, err = client.WaitForTask(context.Background(), MatchTaskID(TaskTypeXDCR, "replication"), WaitForTaskOptions{})
+	require.True(t, IsTaskPaused(err))
+
+	var paused *TaskPausedError
+	require.ErrorAs(t, err, &paused)
+	require.Equal(t, uint64(10), paused.Task().ChangesLeft)
+}
+
+func TestClientWaitForTaskTimeout(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointTasks), NewTestHandler(t, http.StatusOK,
+		[]byte(`[{"type":"bucket_compaction","status":"running","bucket":"default","progress":50}]`)))
+
+	cluster := NewTestCluster(t, TestClusterOptions{Handlers: handlers})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	_, err = client.WaitForTask(context.Background(), MatchTaskType(TaskTypeBucketCompaction), WaitForTaskOptions{
+		Timeout: 100 * time.Millisecond,
+	})
+	require.True(t, IsTaskTimeout(err))
+
+	var timeout *TaskTimeoutError
+	require.ErrorAs(t, err, &timeout)
+	require.Equal(t, 50.0, timeout.Task().Progress)
+}
+
+func TestClientListBackupTasks(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointBackupTaskHistory.Format("repo")), NewTestHandler(t, http.StatusOK,
+		[]byte(`[
+  {"task_name":"backup-1","status":"running","type":"BACKUP","node_runs":[{"node_id":"a","progress":25},`+
+			`{"node_id":"b","progress":75}]},
+  {"task_name":"merge-1","status":"failed","type":"MERGE","error":"merge failed","error_code":2},
+  {"task_name":"backup-0","status":"done","type":"BACKUP","node_runs":[{"node_id":"a","progress":100}]}
+]`)))
+
+	cluster := NewTestCluster(t, TestClusterOptions{
+		Nodes:    TestNodes{{Services: []Service{ServiceBackup}}},
+		Handlers: handlers,
+	})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	tasks, err := client.ListBackupTasks(context.Background(), "repo")
+	require.NoError(t, err)
+
+	expected := []*Task{
+		{
+			ID:       "backup-1",
+			Type:     TaskTypeBackup,
+			Subtype:  "backup",
+			Status:   TaskStatusRunning,
+			Progress: 50,
+			PerNode:  map[string]float64{"a": 25, "b": 75},
+		},
+		{
+			ID:           "merge-1",
+			Type:         TaskTypeBackup,
+			Subtype:      "merge",
+			Status:       TaskStatusNotRunning,
+			ErrorMessage: "merge failed",
+		},
+		{
+			ID:       "backup-0",
+			Type:     TaskTypeBackup,
+			Subtype:  "backup",
+			Status:   TaskStatusNotRunning,
+			Progress: 100,
+			PerNode:  map[string]float64{"a": 100},
+		},
+	}
+
+	require.Equal(t, expected, tasks)
+
+	task, err := client.GetBackupTask(context.Background(), "repo", MatchTaskID(TaskTypeBackup, "backup-0"))
+	require.NoError(t, err)
+	require.Equal(t, expected[2], task)
+}
+
+func TestClientWaitForBackupTask(t *testing.T) {
+	type test struct {
+		name   string
+		final  string
+		failed bool
+	}
+
+	tests := []*test{
+		{
+			name:  "Done",
+			final: `[{"task_name":"backup","status":"done","type":"BACKUP","node_runs":[{"node_id":"a","progress":100}]}]`,
+		},
+		{
+			name:   "Failed",
+			final:  `[{"task_name":"backup","status":"failed","type":"BACKUP","error_code":1}]`,
+			failed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var polls int
+
+			handlers := make(TestHandlers)
+			handlers.Add(http.MethodGet, string(EndpointBackupTaskHistory.Format("repo")),
+				func(writer http.ResponseWriter, request *http.Request) {
+					defer func() { polls++ }()
+
+					if polls > 0 {
+						testutil.Write(t, writer, []byte(test.final))
+						return
+					}
+
+					testutil.Write(t, writer, []byte(
+						`[{"task_name":"backup","status":"running","type":"BACKUP",`+
+							`"node_runs":[{"node_id":"a","progress":50}]}]`,
+					))
+				})
+
+			cluster := NewTestCluster(t, TestClusterOptions{
+				Nodes:    TestNodes{{Services: []Service{ServiceBackup}}},
+				Handlers: handlers,
+			})
+			defer cluster.Close()
+
+			client, err := newTestClient(cluster, true)
+			require.NoError(t, err)
+
+			var progress []float64
+
+			task, err := client.WaitForTask(context.Background(), MatchTaskID(TaskTypeBackup, "backup"),
+				WaitForTaskOptions{
+					ProgressFunc:     func(task *Task) { progress = append(progress, task.Progress) },
+					BackupRepository: "repo",
+				})
+
+			if test.failed {
+				var failed *TaskFailedError
+				require.ErrorAs(t, err, &failed)
+				require.Equal(t, "task failed with error code 1", failed.Task().ErrorMessage)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, TaskStatusNotRunning, task.Status)
+			require.Equal(t, []float64{50, 100}, progress)
+		})
+	}
+}
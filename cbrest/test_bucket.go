@@ -1,6 +1,6 @@
 package cbrest
 
-import "encoding/json"
+import "github.com/couchbase/tools-common/cbvalue"
 
 // TestBuckets is a readbility alias around a map of buckets.
 type TestBuckets map[string]*TestBucket
@@ -10,6 +10,20 @@ type TestBuckets map[string]*TestBucket
 type TestBucket struct {
 	UUID        string
 	NumVBuckets uint16
-	// Manifest should be a '*collections.Manifest' from 'backup'.
-	Manifest json.Marshaler
+	// Manifest is the collections manifest for the bucket, a manifest containing only the default scope/collection
+	// is used when <nil>.
+	Manifest *cbvalue.Manifest
+
+	// The bucket settings, these may be modified using the bucket management endpoints. The zero value for the type
+	// is a Couchbase bucket.
+	Type               BucketType
+	RAMQuotaMiB        uint64
+	NumReplicas        int
+	EvictionPolicy     EvictionPolicy
+	DurabilityMinLevel DurabilityLevel
+	StorageBackend     StorageBackend
+	FlushEnabled       bool
+
+	// Flushes is the number of times the bucket has been flushed.
+	Flushes int
 }
@@ -10,14 +10,34 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/google/uuid"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/couchbase/tools-common/aprov"
+	"github.com/couchbase/tools-common/cbvalue"
 	"github.com/couchbase/tools-common/netutil"
 	"github.com/couchbase/tools-common/testutil"
 
 	"github.com/stretchr/testify/require"
 )
 
+// bucketEndpointRegex matches the bucket endpoints which contain a variable portion, capturing the bucket name and
+// the (optional) flush controller.
+var bucketEndpointRegex = regexp.MustCompile(`^/pools/default/buckets/([^/]+)(/controller/doFlush)?$`)
+
+// bucketStreamingEndpointRegex matches the terse bucket streaming endpoint, capturing the bucket name.
+var bucketStreamingEndpointRegex = regexp.MustCompile(`^/pools/default/bs/([^/]+)$`)
+
+// manifestEndpointRegex matches the collections management endpoints, capturing the bucket, scope and collection names
+// where present.
+var manifestEndpointRegex = regexp.MustCompile(
+	`^/pools/default/buckets/([^/]+)/scopes(?:/([^/@][^/]*)(?:/collections(?:/([^/]+))?)?)?$`,
+)
+
 // TestClusterOptions encapsulates the options which can be passed when creating a new test cluster. These options
 // configure the behavior/setup of the cluster.
 type TestClusterOptions struct {
@@ -31,11 +51,32 @@ type TestClusterOptions struct {
 
 	Buckets TestBuckets
 
+	// The users/groups which may be managed using the RBAC endpoints; the /whoami endpoint reports the user matching the
+	// credentials supplied with the request, or the built-in full administrator if there isn't one.
+	Users  TestUsers
+	Groups TestGroups
+
 	// Additional handler functions which are run to handle a REST request dispatched to the cluster
 	Handlers TestHandlers
 
 	// A non-nil TLS config indicates that the cluster should use TLS
 	TLSConfig *tls.Config
+
+	// SeparateNodeAddresses causes each node to be served on its own port, rather than every node sharing the address
+	// of the cluster; this allows requests to individual nodes to be distinguished, for example, to simulate node
+	// failures using 'FailNode'/'HangNode'.
+	SeparateNodeAddresses bool
+
+	// Auth is the authentication enforced by the cluster, by default requests are not authenticated.
+	Auth *TestAuth
+
+	// RebalancePolls is the number of times the rebalance progress must be polled before a simulated rebalance (or
+	// graceful failover) completes, by default rebalances complete immediately.
+	RebalancePolls int
+
+	// RebalanceError is the error message reported when a simulated rebalance completes, a non-empty message indicates
+	// that the rebalance should fail without changing the cluster topology.
+	RebalanceError string
 }
 
 // TestCluster is a mock Couchbase cluster used for unit testing functionaility which relies on the REST client.
@@ -44,6 +85,30 @@ type TestCluster struct {
 	revision int64
 	server   *httptest.Server
 	options  TestClusterOptions
+
+	// lock guards the nodes/buckets/users/groups/revision/rebalance/auth, which may be modified whilst the cluster is
+	// running.
+	lock sync.Mutex
+
+	// nodeServers are the servers started for nodes when using separate node addresses, they're closed along with the
+	// cluster.
+	nodeServers []*httptest.Server
+
+	// rebalance is the currently running simulated rebalance, <nil> if there isn't one running.
+	rebalance *testRebalance
+
+	// rebalanceError is the error message reported by the last simulated rebalance.
+	rebalanceError string
+
+	// nextNode is used to generate unique OTP names for nodes added to the cluster.
+	nextNode int
+
+	// changed is closed (and recreated) each time the cluster config revision is bumped, waking any active cluster
+	// config streams.
+	changed chan struct{}
+
+	// closed is closed when the cluster is closed, terminating any active streams.
+	closed chan struct{}
 }
 
 // NewTestCluster creates a new test cluster using the provided options.
@@ -60,9 +125,23 @@ func NewTestCluster(t *testing.T, options TestClusterOptions) *TestCluster {
 		options.Handlers = make(TestHandlers)
 	}
 
+	if options.Buckets == nil {
+		options.Buckets = make(TestBuckets)
+	}
+
+	if options.Users == nil {
+		options.Users = make(TestUsers)
+	}
+
+	if options.Groups == nil {
+		options.Groups = make(TestGroups)
+	}
+
 	cluster := &TestCluster{
 		t:       t,
 		options: options,
+		changed: make(chan struct{}),
+		closed:  make(chan struct{}),
 	}
 
 	// def will set the provided endpoint in the handlers if there isn't already a definition.
@@ -80,24 +159,75 @@ func NewTestCluster(t *testing.T, options TestClusterOptions) *TestCluster {
 	def(http.MethodGet, EndpointPools, cluster.Pools)
 	def(http.MethodGet, EndpointPoolsDefault, cluster.PoolsDefault)
 	def(http.MethodGet, EndpointNodesServices, cluster.NodeServices)
+	def(http.MethodGet, EndpointNodesServicesStreaming, cluster.NodeServicesStreaming)
 	def(http.MethodGet, EndpointBuckets, cluster.Buckets)
+	def(http.MethodPost, EndpointBuckets, cluster.CreateBucket)
 
 	for name := range options.Buckets {
 		def(http.MethodGet, EndpointBucket.Format(name), cluster.Bucket(name))
 		def(http.MethodGet, EndpointBucketManifest.Format(name), cluster.BucketManifest(name))
 	}
 
-	if options.TLSConfig != nil {
-		cluster.server = httptest.NewUnstartedServer(http.HandlerFunc(cluster.Handler))
-		cluster.server.TLS = options.TLSConfig
-		cluster.server.StartTLS()
-	} else {
-		cluster.server = httptest.NewServer(http.HandlerFunc(cluster.Handler))
+	def(http.MethodPost, EndpointAddNode, cluster.AddNode)
+	def(http.MethodPost, EndpointFailover, cluster.Failover)
+	def(http.MethodPost, EndpointGracefulFailover, cluster.GracefulFailover)
+	def(http.MethodPost, EndpointSetRecoveryType, cluster.SetRecoveryType)
+	def(http.MethodPost, EndpointRebalance, cluster.Rebalance)
+	def(http.MethodPost, EndpointStopRebalance, cluster.StopRebalance)
+	def(http.MethodGet, EndpointRebalanceProgress, cluster.RebalanceProgress)
+	def(http.MethodGet, EndpointTasks, cluster.Tasks)
+	def(http.MethodGet, EndpointWhoAmI, cluster.WhoAmI)
+	def(http.MethodGet, EndpointRBACRoles, cluster.RBACRoles)
+	def(http.MethodGet, EndpointRBACUsers, cluster.RBACUsers)
+	def(http.MethodGet, EndpointRBACGroups, cluster.RBACGroups)
+	def(http.MethodPost, EndpointCheckPermissions, cluster.CheckPermissions)
+
+	cluster.server = cluster.newServer(nil)
+
+	for _, node := range options.Nodes {
+		if node.OTPNode == "" {
+			node.OTPNode = cluster.nextOTPNode()
+		}
+
+		cluster.startNode(node)
 	}
 
 	return cluster
 }
 
+// NewTestClusterClient creates a new test cluster with the given nodes/handlers, returning a client which has been
+// bootstrapped against it. The cluster is closed once the test completes.
+//
+// NOTE: The client uses static credentials and has CCP disabled, this should be used by packages which build upon the
+// REST client and only need to exercise their own endpoints.
+func NewTestClusterClient(t *testing.T, nodes TestNodes, handlers TestHandlers) *Client {
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: nodes, Handlers: handlers})
+	t.Cleanup(cluster.Close)
+
+	client, err := NewClient(ClientOptions{
+		ConnectionString: cluster.URL(),
+		DisableCCP:       true,
+		Provider:         &aprov.Static{},
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+// newServer creates and starts a new server which serves requests for the given node, or the cluster as a whole if the
+// node is <nil>.
+func (t *TestCluster) newServer(node *TestNode) *httptest.Server {
+	if t.options.TLSConfig == nil {
+		return httptest.NewServer(t.serve(node))
+	}
+
+	server := httptest.NewUnstartedServer(t.serve(node))
+	server.TLS = t.options.TLSConfig
+	server.StartTLS()
+
+	return server
+}
+
 // URL returns the fully qualified URL which can be used to connect to the cluster.
 func (t *TestCluster) URL() string {
 	return t.server.URL
@@ -118,7 +248,12 @@ func (t *TestCluster) Address() string {
 //
 // NOTE: This port is randomly selected at runtime and will therefore vary.
 func (t *TestCluster) Port() uint16 {
-	url, err := url.Parse(t.server.URL)
+	return t.serverPort(t.server)
+}
+
+// serverPort returns the port the given server is listening on.
+func (t *TestCluster) serverPort(server *httptest.Server) uint16 {
+	url, err := url.Parse(server.URL)
 	require.NoError(t.t, err)
 
 	parsed, err := strconv.Atoi(url.Port())
@@ -144,10 +279,38 @@ func (t *TestCluster) Handler(writer http.ResponseWriter, request *http.Request)
 		return
 	}
 
+	// Buckets may be created at runtime, so the bucket management endpoints are handled dynamically; they can still be
+	// overridden via a test handler if required.
+	if matches := bucketEndpointRegex.FindStringSubmatch(request.URL.Path); matches != nil {
+		t.handleBucket(matches[1], matches[2] != "", writer, request)
+		return
+	}
+
+	if matches := bucketStreamingEndpointRegex.FindStringSubmatch(request.URL.Path); matches != nil {
+		t.BucketStreaming(matches[1])(writer, request)
+		return
+	}
+
+	if matches := manifestEndpointRegex.FindStringSubmatch(request.URL.Path); matches != nil {
+		t.handleManifest(matches[1], matches[2], matches[3], writer, request)
+		return
+	}
+
+	// Users/groups may also be created at runtime, so they're handled in the same fashion as buckets
+	if matches := rbacUserEndpointRegex.FindStringSubmatch(request.URL.Path); matches != nil {
+		t.handleRBACUser(AuthDomain(matches[1]), matches[2], writer, request)
+		return
+	}
+
+	if matches := rbacGroupEndpointRegex.FindStringSubmatch(request.URL.Path); matches != nil {
+		t.handleRBACGroup(matches[1], writer, request)
+		return
+	}
+
 	// This is a status endpoint which contains a variable portion, for the time being we'll always respond indicating
 	// that the test cluster has the provided manifest id. Note that this endpoint can still be overridden via a test
 	// handler if required; this is just a default fallback.
-	if regexp.MustCompile(`^\/pools/default\/buckets/\S+/scopes/@ensureManifest/\d+$`).MatchString(request.URL.Path) {
+	if regexp.MustCompile(`^\/pools/default\/buckets/\S+/scopes/@ensureManifest/[0-9a-f]+$`).MatchString(request.URL.Path) {
 		writer.WriteHeader(http.StatusOK)
 		testutil.Write(t.t, writer, make([]byte, 0))
 
@@ -173,6 +336,9 @@ func (t *TestCluster) Pools(writer http.ResponseWriter, request *http.Request) {
 // PoolsDefault implements the /pools/default endpoint, values can be modified by modifying the nodes in the cluster
 // using the cluster options.
 func (t *TestCluster) PoolsDefault(writer http.ResponseWriter, request *http.Request) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	testutil.EncodeJSON(t.t, writer, struct {
 		Nodes []node `json:"nodes"`
 	}{
@@ -183,14 +349,15 @@ func (t *TestCluster) PoolsDefault(writer http.ResponseWriter, request *http.Req
 // Buckets implements the /pools/default/buckets endpoint, values can be modified by modifying the buckets in the
 // cluster using the cluster options.
 func (t *TestCluster) Buckets(writer http.ResponseWriter, request *http.Request) {
-	buckets := make([]bucket, 0, len(t.options.Buckets))
-	for n, b := range t.options.Buckets {
-		buckets = append(buckets, bucket{
-			Name:             n,
-			UUID:             b.UUID,
-			VBucketServerMap: vbsm{VBucketMap: make([][2]int, b.NumVBuckets)},
-			Nodes:            createNodeList(t.options.Nodes),
-		})
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	names := maps.Keys(t.options.Buckets)
+	slices.Sort(names)
+
+	buckets := make([]bucket, 0, len(names))
+	for _, name := range names {
+		buckets = append(buckets, t.createBucket(name, t.options.Buckets[name]))
 	}
 
 	testutil.EncodeJSON(t.t, writer, buckets)
@@ -200,57 +367,442 @@ func (t *TestCluster) Buckets(writer http.ResponseWriter, request *http.Request)
 // the cluster using the cluster options.
 func (t *TestCluster) Bucket(name string) func(writer http.ResponseWriter, request *http.Request) {
 	return func(writer http.ResponseWriter, request *http.Request) {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
 		b, ok := t.options.Buckets[name]
-		require.True(t.t, ok)
+		if !ok {
+			writeBucketNotFound(t.t, writer)
+			return
+		}
 
-		testutil.EncodeJSON(t.t, writer, bucket{
-			Name:             name,
-			UUID:             b.UUID,
-			VBucketServerMap: vbsm{VBucketMap: make([][2]int, b.NumVBuckets)},
-			Nodes:            createNodeList(t.options.Nodes),
-		})
+		testutil.EncodeJSON(t.t, writer, t.createBucket(name, b))
 	}
 }
 
+// CreateBucket implements the POST /pools/default/buckets endpoint, validating the settings in a similar fashion to
+// 'ns_server' before adding the bucket to the cluster.
+func (t *TestCluster) CreateBucket(writer http.ResponseWriter, request *http.Request) {
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	name := request.PostForm.Get("name")
+
+	if name == "" {
+		writeBucketErrors(t.t, writer, map[string]string{"name": "Bucket name needs to be specified"})
+		return
+	}
+
+	if _, ok := t.options.Buckets[name]; ok {
+		writeBucketErrors(t.t, writer, map[string]string{"name": "Bucket with given name already exists"})
+		return
+	}
+
+	b := &TestBucket{
+		UUID:               strings.ReplaceAll(uuid.NewString(), "-", ""),
+		NumVBuckets:        1024,
+		Type:               BucketType(request.PostForm.Get("bucketType")),
+		NumReplicas:        1,
+		DurabilityMinLevel: DurabilityLevelNone,
+		StorageBackend:     StorageBackendCouchstore,
+	}
+
+	if b.Type == "" || b.Type == "membase" {
+		b.Type = BucketTypeCouchbase
+	}
+
+	if backend := request.PostForm.Get("storageBackend"); backend != "" {
+		b.StorageBackend = StorageBackend(backend)
+	}
+
+	b.EvictionPolicy = EvictionPolicyValueOnly
+	if b.Type == BucketTypeEphemeral {
+		b.EvictionPolicy = EvictionPolicyNoEviction
+	}
+
+	if !t.applyBucketSettings(b, request, writer) {
+		return
+	}
+
+	if b.RAMQuotaMiB == 0 {
+		writeBucketErrors(t.t, writer, map[string]string{"ramQuota": "RAM quota must be specified"})
+		return
+	}
+
+	t.options.Buckets[name] = b
+
+	writer.WriteHeader(http.StatusAccepted)
+}
+
 // BucketManifest implements the /pools/default/buckets/<bucket>/scopes endpoint. The returned manifest may be set using
-// the cluster options.
+// the cluster options, a manifest containing only the default scope/collection is returned by default.
 func (t *TestCluster) BucketManifest(name string) func(writer http.ResponseWriter, request *http.Request) {
 	return func(writer http.ResponseWriter, request *http.Request) {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
 		b, ok := t.options.Buckets[name]
-		require.True(t.t, ok)
+		if !ok {
+			writeBucketNotFound(t.t, writer)
+			return
+		}
+
+		if b.Manifest == nil {
+			b.Manifest = newDefaultManifest()
+		}
 
-		require.NotNil(t.t, writer, b.Manifest)
 		testutil.EncodeJSON(t.t, writer, b.Manifest)
 	}
 }
 
+// handleManifest handles the collections management endpoints for the given bucket/scope/collection.
+func (t *TestCluster) handleManifest(bucket, scope, collection string, writer http.ResponseWriter,
+	request *http.Request,
+) {
+	if request.Method == http.MethodGet && scope == "" {
+		t.BucketManifest(bucket)(writer, request)
+		return
+	}
+
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	b, ok := t.options.Buckets[bucket]
+	if !ok {
+		writeBucketNotFound(t.t, writer)
+		return
+	}
+
+	if b.Manifest == nil {
+		b.Manifest = newDefaultManifest()
+	}
+
+	manifest := b.Manifest
+
+	var (
+		name = request.PostForm.Get("name")
+		msg  string
+	)
+
+	switch {
+	case request.Method == http.MethodPost && scope == "":
+		msg = createScope(manifest, name)
+	case request.Method == http.MethodDelete && scope != "" && !strings.HasSuffix(request.URL.Path, "/collections"):
+		msg = dropScopeOrCollection(manifest, scope, collection)
+	case request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/collections"):
+		msg = createCollection(manifest, scope, name, request.PostForm.Get("maxTTL"))
+	default:
+		t.t.Fatalf("Endpoint '%s' does not have a handler for method '%s'", request.URL.Path, request.Method)
+	}
+
+	if msg != "" {
+		status := http.StatusBadRequest
+		if strings.Contains(msg, "not found") {
+			status = http.StatusNotFound
+		}
+
+		writer.WriteHeader(status)
+		testutil.EncodeJSON(t.t, writer, map[string]map[string]string{"errors": {"_": msg}})
+
+		return
+	}
+
+	testutil.EncodeJSON(t.t, writer, map[string]cbvalue.UID{"uid": manifest.UID})
+}
+
+// handleBucket handles the bucket management endpoints for the given bucket.
+func (t *TestCluster) handleBucket(name string, flush bool, writer http.ResponseWriter, request *http.Request) {
+	if request.Method == http.MethodGet && !flush {
+		t.Bucket(name)(writer, request)
+		return
+	}
+
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	b, ok := t.options.Buckets[name]
+	if !ok {
+		writeBucketNotFound(t.t, writer)
+		return
+	}
+
+	switch {
+	case flush && request.Method == http.MethodPost:
+		if !b.FlushEnabled {
+			writer.WriteHeader(http.StatusBadRequest)
+			testutil.EncodeJSON(t.t, writer, map[string]string{"_": "Flush is disabled for the bucket"})
+
+			return
+		}
+
+		b.Flushes++
+	case !flush && request.Method == http.MethodPost:
+		updated := *b
+
+		if !t.applyBucketSettings(&updated, request, writer) {
+			return
+		}
+
+		*b = updated
+	case !flush && request.Method == http.MethodDelete:
+		delete(t.options.Buckets, name)
+	default:
+		t.t.Fatalf("Endpoint '%s' does not have a handler for method '%s'", request.URL.Path, request.Method)
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// applyBucketSettings applies the mutable settings from the given request form to the bucket, returning a boolean
+// indicating whether the settings were valid; a 400 response is written when they are not.
+func (t *TestCluster) applyBucketSettings(b *TestBucket, request *http.Request, writer http.ResponseWriter) bool {
+	errs := make(map[string]string)
+
+	if value := request.PostForm.Get("ramQuota"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+
+		switch {
+		case err != nil:
+			errs["ramQuota"] = "The RAM Quota must be specified and must be a positive integer."
+		case parsed < 100:
+			errs["ramQuota"] = "RAM quota cannot be less than 100 MiB"
+		default:
+			b.RAMQuotaMiB = parsed
+		}
+	}
+
+	if value := request.PostForm.Get("replicaNumber"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 3 {
+			errs["replicaNumber"] = "Replica number must be in the range 0 to 3."
+		} else {
+			b.NumReplicas = parsed
+		}
+	}
+
+	if value := request.PostForm.Get("evictionPolicy"); value != "" {
+		b.EvictionPolicy = EvictionPolicy(value)
+	}
+
+	if value := request.PostForm.Get("durabilityMinLevel"); value != "" {
+		b.DurabilityMinLevel = DurabilityLevel(value)
+	}
+
+	if value := request.PostForm.Get("flushEnabled"); value != "" {
+		b.FlushEnabled = value == "1"
+	}
+
+	if len(errs) == 0 {
+		return true
+	}
+
+	writeBucketErrors(t.t, writer, errs)
+
+	return false
+}
+
+// createBucket creates the structure used when marshalling bucket information for the given test bucket.
+func (t *TestCluster) createBucket(name string, b *TestBucket) bucket {
+	bucketType := string(b.Type)
+	if b.Type == "" || b.Type == BucketTypeCouchbase {
+		bucketType = "membase"
+	}
+
+	encoded := bucket{
+		Name:               name,
+		UUID:               b.UUID,
+		Nodes:              createNodeList(t.activeNodes()),
+		Type:               bucketType,
+		NumReplicas:        b.NumReplicas,
+		EvictionPolicy:     b.EvictionPolicy,
+		DurabilityMinLevel: b.DurabilityMinLevel,
+		StorageBackend:     b.StorageBackend,
+		Quota: quota{
+			RAM:    b.RAMQuotaMiB * 1024 * 1024 * uint64(len(t.activeNodes())),
+			RawRAM: b.RAMQuotaMiB * 1024 * 1024,
+		},
+	}
+
+	// Memcached buckets don't have vBuckets
+	if b.Type != BucketTypeMemcached {
+		encoded.VBucketServerMap = t.createVBucketServerMap(b)
+	}
+
+	if b.FlushEnabled {
+		encoded.Controllers.Flush = string(EndpointBucketFlush.Format(name))
+	}
+
+	return encoded
+}
+
+// createVBucketServerMap creates a vBucket server map for the given bucket, where the active/replica vBuckets are
+// distributed evenly (round-robin) across the nodes in the cluster.
+func (t *TestCluster) createVBucketServerMap(b *TestBucket) *vbsm {
+	// All the test nodes share an address, so each node is given a unique (fake) memcached port to distinguish them
+	active := t.activeNodes()
+
+	servers := make([]string, 0, len(active))
+	for idx := range active {
+		servers = append(servers, fmt.Sprintf("%s:%d", t.Address(), 11210+idx))
+	}
+
+	vbMap := make([][]int, b.NumVBuckets)
+
+	for vb := range vbMap {
+		vbMap[vb] = make([]int, b.NumReplicas+1)
+
+		for position := range vbMap[vb] {
+			vbMap[vb][position] = -1
+
+			// Like Couchbase Server, we can't have more than one copy of a vBucket on a single node
+			if position < len(servers) {
+				vbMap[vb][position] = (vb + position) % len(servers)
+			}
+		}
+	}
+
+	return &vbsm{HashAlgorithm: "CRC", NumReplicas: b.NumReplicas, ServerList: servers, VBucketMap: vbMap}
+}
+
 // NodeServices implements the /pools/default/nodeServices endpoint, values can be modified by modifying the nodes in
 // the cluster using the cluster options.
 func (t *TestCluster) NodeServices(writer http.ResponseWriter, request *http.Request) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	defer func() { t.revision++ }()
 
+	t.encodeClusterConfig(writer)
+}
+
+// NodeServicesStreaming implements the /pools/default/nodeServicesStreaming endpoint, the current cluster config is
+// sent when the stream is opened, and then again each time the revision is bumped.
+func (t *TestCluster) NodeServicesStreaming(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Transfer-Encoding", "chunked")
+	writer.WriteHeader(http.StatusOK)
+
+	flusher, ok := writer.(http.Flusher)
+	require.True(t.t, ok)
+
+	for {
+		t.lock.Lock()
+		t.encodeClusterConfig(writer)
+		changed := t.changed
+		t.lock.Unlock()
+
+		// Mimic the behavior of 'ns_server' by writing quadruple newlines between payloads
+		testutil.Write(t.t, writer, []byte("\n\n\n\n"))
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-request.Context().Done():
+			return
+		case <-t.closed:
+			return
+		}
+	}
+}
+
+// BucketStreaming implements the /pools/default/bs/<bucket> endpoint, for the time being this streams the same payload
+// as the /pools/default/nodeServicesStreaming endpoint.
+func (t *TestCluster) BucketStreaming(name string) func(writer http.ResponseWriter, request *http.Request) {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		t.lock.Lock()
+		_, ok := t.options.Buckets[name]
+		t.lock.Unlock()
+
+		if !ok {
+			writeBucketNotFound(t.t, writer)
+			return
+		}
+
+		t.NodeServicesStreaming(writer, request)
+	}
+}
+
+// encodeClusterConfig writes the current cluster config to the given writer.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) encodeClusterConfig(writer http.ResponseWriter) {
 	testutil.EncodeJSON(t.t, writer, struct {
 		Revision int64 `json:"rev"`
 		Nodes    Nodes `json:"nodesExt"`
 	}{
 		Revision: t.revision,
-		Nodes:    t.Nodes(),
+		Nodes:    t.nodes(),
 	})
 }
 
-// Nodes returns the list of nodes in the cluster, generated using the test nodes provided in the cluster options.
+// BumpRevision increments the cluster config revision, waking any active cluster config streams.
+func (t *TestCluster) BumpRevision() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.incrementRevision()
+}
+
+// incrementRevision increments the cluster config revision, waking any active cluster config streams.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) incrementRevision() {
+	t.revision++
+
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// Nodes returns the list of active nodes in the cluster, generated using the test nodes provided in the cluster
+// options.
 func (t *TestCluster) Nodes() Nodes {
-	nodes := make([]*Node, 0, len(t.options.Nodes))
-	for _, node := range t.options.Nodes {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.nodes()
+}
+
+// nodes returns the list of active nodes in the cluster.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) nodes() Nodes {
+	active := t.activeNodes()
+
+	nodes := make([]*Node, 0, len(active))
+	for _, node := range active {
 		nodes = append(nodes, t.createNode(node))
 	}
 
 	return nodes
 }
 
+// activeNodes returns the test nodes which are active members of the cluster i.e. excluding nodes which have been
+// added/failed over but not yet rebalanced in/out of the cluster.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) activeNodes() TestNodes {
+	active := make(TestNodes, 0, len(t.options.Nodes))
+
+	for _, node := range t.options.Nodes {
+		if node.active() {
+			active = append(active, node)
+		}
+	}
+
+	return active
+}
+
 // createNode creates a new node using the test node options to determine the specific setup.
 func (t *TestCluster) createNode(n *TestNode) *Node {
 	port := t.Port()
+	if n.server != nil {
+		port = t.serverPort(n.server)
+	}
 
 	node := &Node{
 		Hostname: t.Address(),
@@ -281,14 +833,153 @@ func (t *TestCluster) createNode(n *TestNode) *Node {
 
 // Close stops the server releasing any held resources.
 func (t *TestCluster) Close() {
+	select {
+	case <-t.closed:
+	default:
+		close(t.closed)
+	}
+
 	t.server.Close()
+
+	t.lock.Lock()
+	servers := t.nodeServers
+	t.lock.Unlock()
+
+	for _, server := range servers {
+		server.Close()
+	}
 }
 
-// createNodeList is a utility function to create the basic node list which contains the node version/status.
+// newDefaultManifest returns the manifest for a newly created bucket, which only contains the default
+// scope/collection.
+func newDefaultManifest() *cbvalue.Manifest {
+	return &cbvalue.Manifest{
+		Scopes: []*cbvalue.Scope{{
+			Name:        cbvalue.DefaultScope,
+			Collections: []*cbvalue.Collection{{Name: cbvalue.DefaultCollection}},
+		}},
+	}
+}
+
+// nextManifestUID bumps the manifest UID, returning the UID which should be used for the new scope/collection; note
+// that, like 'ns_server', the UIDs 0-7 are reserved.
+func nextManifestUID(manifest *cbvalue.Manifest) cbvalue.UID {
+	manifest.UID++
+	return manifest.UID + 7
+}
+
+// createScope creates the given scope in the manifest, returning an 'ns_server' style error message on failure.
+func createScope(manifest *cbvalue.Manifest, scope string) string {
+	if manifest.Scope(scope) != nil {
+		return fmt.Sprintf("Scope with name \"%s\" already exists", scope)
+	}
+
+	manifest.Scopes = append(manifest.Scopes, &cbvalue.Scope{
+		Name:        scope,
+		UID:         nextManifestUID(manifest),
+		Collections: make([]*cbvalue.Collection, 0),
+	})
+
+	return ""
+}
+
+// createCollection creates the given collection in the manifest, returning an 'ns_server' style error message on
+// failure.
+func createCollection(manifest *cbvalue.Manifest, scope, collection, maxTTL string) string {
+	s := manifest.Scope(scope)
+	if s == nil {
+		return fmt.Sprintf("Scope with name \"%s\" is not found", scope)
+	}
+
+	if s.Collection(collection) != nil {
+		return fmt.Sprintf("Collection with name \"%s\" in scope \"%s\" already exists", collection, scope)
+	}
+
+	ttl, _ := strconv.ParseInt(maxTTL, 10, 32)
+
+	s.Collections = append(s.Collections, &cbvalue.Collection{
+		Name:   collection,
+		UID:    nextManifestUID(manifest),
+		MaxTTL: int32(ttl),
+	})
+
+	return ""
+}
+
+// dropScopeOrCollection drops the given scope (or collection if non-empty) from the manifest, returning an 'ns_server'
+// style error message on failure.
+func dropScopeOrCollection(manifest *cbvalue.Manifest, scope, collection string) string {
+	s := manifest.Scope(scope)
+	if s == nil {
+		return fmt.Sprintf("Scope with name \"%s\" is not found", scope)
+	}
+
+	if collection == "" {
+		idx := slices.Index(manifest.Scopes, s)
+		manifest.Scopes = slices.Delete(manifest.Scopes, idx, idx+1)
+		manifest.UID++
+
+		return ""
+	}
+
+	c := s.Collection(collection)
+	if c == nil {
+		return fmt.Sprintf("Collection with name \"%s\" in scope \"%s\" is not found", collection, scope)
+	}
+
+	idx := slices.Index(s.Collections, c)
+	s.Collections = slices.Delete(s.Collections, idx, idx+1)
+	manifest.UID++
+
+	return ""
+}
+
+// writeBucketNotFound writes the response returned by 'ns_server' when a bucket does not exist.
+func writeBucketNotFound(t *testing.T, writer http.ResponseWriter) {
+	writer.WriteHeader(http.StatusNotFound)
+	testutil.Write(t, writer, []byte("Requested resource not found.\r\n"))
+}
+
+// writeBucketErrors writes the response returned by 'ns_server' when the provided bucket settings are invalid.
+func writeBucketErrors(t *testing.T, writer http.ResponseWriter, errs map[string]string) {
+	writer.WriteHeader(http.StatusBadRequest)
+	testutil.EncodeJSON(t, writer, struct {
+		Errors map[string]string `json:"errors"`
+	}{
+		Errors: errs,
+	})
+}
+
+// createNodeList is a utility function to create the basic node list which contains the node version/status and
+// membership information.
 func createNodeList(nodes []*TestNode) []node {
 	list := make([]node, 0, len(nodes))
+
 	for _, n := range nodes {
-		list = append(list, node{Version: n.Version, Status: n.Status})
+		encoded := node{
+			Version:      n.Version,
+			Status:       n.Status,
+			OTPNode:      n.OTPNode,
+			Services:     make([]string, 0, len(n.Services)),
+			Membership:   n.Membership,
+			RecoveryType: n.RecoveryType,
+		}
+
+		if encoded.Membership == "" {
+			encoded.Membership = ClusterMembershipActive
+		}
+
+		if encoded.RecoveryType == "" {
+			encoded.RecoveryType = RecoveryTypeNone
+		}
+
+		for _, service := range n.Services {
+			if name, ok := serviceNames[service]; ok {
+				encoded.Services = append(encoded.Services, name)
+			}
+		}
+
+		list = append(list, encoded)
 	}
 
 	return list
@@ -0,0 +1,214 @@
+package cbrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuth is the authentication enforced by a test cluster, requests which fail to authenticate receive a 401.
+type TestAuth struct {
+	// Username/Password are the credentials of the built-in full administrator, users in the cluster may also
+	// authenticate using their username/password.
+	Username string
+	Password string
+
+	// ClientCertificates allows requests to authenticate using a client certificate, where the common name of the
+	// certificate is the administrator or one of the users in the cluster.
+	//
+	// NOTE: The cluster must be using a TLS config which requests client certificates e.g. 'tls.RequestClientCert'.
+	ClientCertificates bool
+}
+
+// serve returns the handler for requests dispatched to the given node (or the cluster address if <nil>), any faults
+// injected into the node and the cluster authentication are applied before the request is handled.
+func (t *TestCluster) serve(node *TestNode) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		t.lock.Lock()
+
+		var (
+			failStatus    int
+			hang          bool
+			authenticated = t.authenticated(request)
+		)
+
+		if node != nil {
+			failStatus, hang = node.failStatus, node.hang
+		}
+
+		t.lock.Unlock()
+
+		if hang {
+			select {
+			case <-request.Context().Done():
+			case <-t.closed:
+			}
+
+			return
+		}
+
+		if failStatus != 0 {
+			writer.WriteHeader(failStatus)
+			return
+		}
+
+		if !authenticated {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		t.Handler(writer, request)
+	}
+}
+
+// authenticated returns a boolean indicating whether the given request has valid credentials, all requests are valid
+// when the cluster isn't enforcing authentication.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) authenticated(request *http.Request) bool {
+	auth := t.options.Auth
+	if auth == nil {
+		return true
+	}
+
+	if auth.ClientCertificates && request.TLS != nil && len(request.TLS.PeerCertificates) != 0 {
+		name := request.TLS.PeerCertificates[0].Subject.CommonName
+		_, ok := t.options.Users[name]
+
+		return name == auth.Username || ok
+	}
+
+	username, password, ok := request.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	if username == auth.Username && password == auth.Password {
+		return true
+	}
+
+	user, ok := t.options.Users[username]
+
+	return ok && user.domain() == AuthDomainLocal && user.Password == password
+}
+
+// SetAuth updates the authentication enforced by the cluster, for example, to simulate credentials being rotated; a
+// <nil> value disables authentication.
+func (t *TestCluster) SetAuth(auth *TestAuth) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.options.Auth = auth
+}
+
+// AddTestNodes adds the given nodes to the cluster as active members, bumping the cluster config revision; unlike the
+// /controller/addNode endpoint, a rebalance is not required.
+func (t *TestCluster) AddTestNodes(nodes ...*TestNode) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, node := range nodes {
+		if node.OTPNode == "" {
+			node.OTPNode = t.nextOTPNode()
+		}
+
+		t.startNode(node)
+	}
+
+	t.options.Nodes = append(t.options.Nodes, nodes...)
+
+	t.incrementRevision()
+}
+
+// RemoveTestNodes removes the nodes with the given OTP names from the cluster, bumping the cluster config revision;
+// when using separate node addresses, the nodes are also stopped, simulating the nodes disappearing.
+func (t *TestCluster) RemoveTestNodes(names ...string) {
+	servers := make([]*httptest.Server, 0, len(names))
+
+	t.lock.Lock()
+
+	for _, name := range names {
+		node := t.findNode(name)
+		require.NotNil(t.t, node, "Node '%s' is not part of the cluster", name)
+
+		idx := slices.Index(t.options.Nodes, node)
+		t.options.Nodes = slices.Delete(t.options.Nodes, idx, idx+1)
+
+		if node.server != nil {
+			servers = append(servers, node.server)
+		}
+	}
+
+	t.incrementRevision()
+
+	t.lock.Unlock()
+
+	// Closing the servers must be done without holding the lock, since it blocks until all in-flight requests complete
+	for _, server := range servers {
+		server.CloseClientConnections()
+		server.Close()
+	}
+}
+
+// UpdateTestNode updates the node with the given OTP name using the provided function, bumping the cluster config
+// revision; this may be used to simulate nodes changing e.g. alternate addresses being enabled.
+func (t *TestCluster) UpdateTestNode(name string, fn func(node *TestNode)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	node := t.findNode(name)
+	require.NotNil(t.t, node, "Node '%s' is not part of the cluster", name)
+
+	fn(node)
+
+	t.incrementRevision()
+}
+
+// FailNode causes all requests to the node with the given OTP name to fail with the given status code.
+//
+// NOTE: The cluster must be using separate node addresses.
+func (t *TestCluster) FailNode(name string, status int) {
+	t.injectFault(name, func(node *TestNode) { node.failStatus = status })
+}
+
+// HangNode causes all requests to the node with the given OTP name to hang, until the request is cancelled or the
+// cluster is closed.
+//
+// NOTE: The cluster must be using separate node addresses.
+func (t *TestCluster) HangNode(name string) {
+	t.injectFault(name, func(node *TestNode) { node.hang = true })
+}
+
+// RecoverNode removes any faults injected into the node with the given OTP name.
+func (t *TestCluster) RecoverNode(name string) {
+	t.injectFault(name, func(node *TestNode) { node.failStatus, node.hang = 0, false })
+}
+
+// injectFault modifies the faults for the node with the given OTP name.
+func (t *TestCluster) injectFault(name string, fn func(node *TestNode)) {
+	require.True(t.t, t.options.SeparateNodeAddresses, "Node faults require separate node addresses")
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	node := t.findNode(name)
+	require.NotNil(t.t, node, "Node '%s' is not part of the cluster", name)
+
+	fn(node)
+}
+
+// startNode starts the server for the given node, when the cluster is using separate node addresses.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) startNode(node *TestNode) {
+	if !t.options.SeparateNodeAddresses || node.server != nil {
+		return
+	}
+
+	node.server = t.newServer(node)
+
+	t.nodeServers = append(t.nodeServers, node.server)
+}
@@ -0,0 +1,250 @@
+package cbrest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/aprov"
+	"github.com/couchbase/tools-common/testutil"
+)
+
+// waitForConfig waits until a config matching the given condition is received from the given channel.
+func waitForConfig(t *testing.T, configs <-chan *ClusterConfig, condition func(config *ClusterConfig) bool) {
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case config := <-configs:
+			if condition(config) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for cluster config")
+		}
+	}
+}
+
+func TestTestClusterAddUpdateRemoveNodes(t *testing.T) {
+	os.Setenv("CB_REST_CC_MAX_AGE", "50ms")
+	defer os.Unsetenv("CB_REST_CC_MAX_AGE")
+
+	cluster := NewTestCluster(t, TestClusterOptions{SeparateNodeAddresses: true, Nodes: TestNodes{{}}})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, false)
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	configs, unsubscribe := client.SubscribeClusterConfig()
+	defer unsubscribe()
+
+	node := &TestNode{}
+
+	cluster.AddTestNodes(node)
+	require.NotEmpty(t, node.OTPNode)
+
+	waitForConfig(t, configs, func(config *ClusterConfig) bool {
+		return len(config.Nodes) == 2 && config.Nodes[0].Services.Management != config.Nodes[1].Services.Management
+	})
+
+	cluster.UpdateTestNode(node.OTPNode, func(node *TestNode) { node.Services = []Service{ServiceQuery} })
+
+	waitForConfig(t, configs, func(config *ClusterConfig) bool {
+		return len(config.Nodes) == 2 && config.Nodes[1].Services.N1QL != 0
+	})
+
+	cluster.RemoveTestNodes(node.OTPNode)
+
+	waitForConfig(t, configs, func(config *ClusterConfig) bool { return len(config.Nodes) == 1 })
+}
+
+func TestTestClusterFailNode(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, "/test", func(writer http.ResponseWriter, request *http.Request) {
+		testutil.Write(t, writer, []byte("body"))
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{
+		SeparateNodeAddresses: true,
+		Nodes:                 TestNodes{{}, {}},
+		Handlers:              handlers,
+	})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	hosts, err := client.GetAllServiceHosts(ServiceManagement)
+	require.NoError(t, err)
+	require.Len(t, hosts, 2)
+
+	cluster.FailNode(cluster.options.Nodes[0].OTPNode, http.StatusServiceUnavailable)
+
+	request := &Request{
+		Endpoint:           "/test",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	// The request should be retried against the healthy node
+	response, err := client.Execute(request)
+	require.NoError(t, err)
+	require.Equal(t, []byte("body"), response.Body)
+
+	for _, stats := range client.HostStats() {
+		if stats.Host == hosts[0] {
+			require.NotZero(t, stats.Failures)
+		} else {
+			require.Zero(t, stats.Failures)
+		}
+	}
+
+	// Requests pinned to the failed node should fail, until it's recovered
+	results, err := client.ExecuteOnAllNodes(context.Background(), request, ExecuteOnAllNodesOptions{})
+	require.NoError(t, err)
+	require.Len(t, results.Failed(), 1)
+	require.Equal(t, hosts[0], results.Failed()[0].Host)
+
+	cluster.RecoverNode(cluster.options.Nodes[0].OTPNode)
+
+	results, err = client.ExecuteOnAllNodes(context.Background(), request, ExecuteOnAllNodesOptions{})
+	require.NoError(t, err)
+	require.Empty(t, results.Failed())
+}
+
+func TestTestClusterHangNode(t *testing.T) {
+	handlers := make(TestHandlers)
+	handlers.Add(http.MethodGet, "/test", func(writer http.ResponseWriter, request *http.Request) {
+		testutil.Write(t, writer, []byte("body"))
+	})
+
+	cluster := NewTestCluster(t, TestClusterOptions{
+		SeparateNodeAddresses: true,
+		Nodes:                 TestNodes{{}},
+		Handlers:              handlers,
+	})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	request := &Request{
+		Endpoint:           "/test",
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	cluster.HangNode(cluster.options.Nodes[0].OTPNode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = client.ExecuteWithContext(ctx, request)
+	require.Error(t, err)
+
+	cluster.RecoverNode(cluster.options.Nodes[0].OTPNode)
+
+	response, err := client.Execute(request)
+	require.NoError(t, err)
+	require.Equal(t, []byte("body"), response.Body)
+}
+
+func TestTestClusterAuth(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{
+		Users: TestUsers{"user": {Password: "asdasd"}},
+		Auth:  &TestAuth{Username: username, Password: password},
+	})
+	defer cluster.Close()
+
+	newClient := func(username, password string) (*Client, error) {
+		return NewClient(ClientOptions{
+			ConnectionString: cluster.URL(),
+			DisableCCP:       true,
+			Provider:         &aprov.Static{Username: username, Password: password, UserAgent: userAgent},
+		})
+	}
+
+	client, err := newClient(username, password)
+	require.NoError(t, err)
+
+	_, err = newClient("user", "asdasd")
+	require.NoError(t, err)
+
+	var bootstrapFailure *BootstrapFailureError
+
+	_, err = newClient(username, "wrong")
+	require.ErrorAs(t, err, &bootstrapFailure)
+	require.NotNil(t, bootstrapFailure.ErrAuthentication)
+
+	// Simulate the credentials being rotated, existing clients should start failing to authenticate
+	cluster.SetAuth(&TestAuth{Username: username, Password: "rotated"})
+
+	_, err = client.Execute(&Request{
+		Endpoint:           EndpointPools,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	})
+
+	var authentication *AuthenticationError
+
+	require.ErrorAs(t, err, &authentication)
+}
+
+func TestTestClusterAuthClientCertificates(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{
+		Nodes:     TestNodes{{SSL: true}},
+		Users:     TestUsers{"user": {}},
+		TLSConfig: &tls.Config{ClientAuth: tls.RequestClientCert},
+		Auth:      &TestAuth{Username: username, ClientCertificates: true},
+	})
+	defer cluster.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cluster.Certificate())
+
+	newClient := func(commonName string) (*Client, error) {
+		var (
+			dir      = t.TempDir()
+			certFile = filepath.Join(dir, "cert.pem")
+			keyFile  = filepath.Join(dir, "key.pem")
+		)
+
+		cert, key := testutil.GenerateCertificate(t, commonName)
+		require.NoError(t, os.WriteFile(certFile, cert, 0o600))
+		require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+
+		provider, err := aprov.NewCertificate(aprov.CertificateOptions{CertFile: certFile, KeyFile: keyFile})
+		require.NoError(t, err)
+
+		return NewClient(ClientOptions{
+			ConnectionString: cluster.URL(),
+			DisableCCP:       true,
+			Provider:         provider,
+			TLSConfig:        &tls.Config{RootCAs: pool},
+		})
+	}
+
+	_, err := newClient(username)
+	require.NoError(t, err)
+
+	_, err = newClient("user")
+	require.NoError(t, err)
+
+	var bootstrapFailure *BootstrapFailureError
+
+	_, err = newClient("unknown")
+	require.ErrorAs(t, err, &bootstrapFailure)
+	require.NotNil(t, bootstrapFailure.ErrAuthentication)
+}
@@ -4,13 +4,20 @@ import "github.com/couchbase/tools-common/cbvalue"
 
 // node is the structure used when marshalling basic node information.
 type node struct {
-	Version cbvalue.Version `json:"version"`
-	Status  string          `json:"status"`
+	Version      cbvalue.Version   `json:"version"`
+	Status       string            `json:"status"`
+	OTPNode      string            `json:"otpNode,omitempty"`
+	Services     []string          `json:"services"`
+	Membership   ClusterMembership `json:"clusterMembership"`
+	RecoveryType RecoveryType      `json:"recoveryType"`
 }
 
-// vbsm represents the vBucketServerMap and is currently only used to indicate the number of vBuckets a bucket has.
+// vbsm represents the vBucketServerMap, vBuckets are distributed evenly across the nodes in the cluster.
 type vbsm struct {
-	VBucketMap [][2]int `json:"vBucketMap"`
+	HashAlgorithm string   `json:"hashAlgorithm"`
+	NumReplicas   int      `json:"numReplicas"`
+	ServerList    []string `json:"serverList"`
+	VBucketMap    [][]int  `json:"vBucketMap"`
 }
 
 // bucket is the structure used when marshalling basic bucket information some of which is configurable using the
@@ -20,6 +27,25 @@ type bucket struct {
 	UUID string `json:"uuid"`
 	// ACSettings should be false, otherwise cbbackupmgr will try to decode the autocompaction settings.
 	ACSettings       bool   `json:"autoCompactionSettings"`
-	VBucketServerMap vbsm   `json:"VBucketServerMap"`
+	VBucketServerMap *vbsm  `json:"vBucketServerMap,omitempty"`
 	Nodes            []node `json:"nodes"`
+
+	Type               string          `json:"bucketType"`
+	Quota              quota           `json:"quota"`
+	NumReplicas        int             `json:"replicaNumber"`
+	EvictionPolicy     EvictionPolicy  `json:"evictionPolicy,omitempty"`
+	DurabilityMinLevel DurabilityLevel `json:"durabilityMinLevel,omitempty"`
+	StorageBackend     StorageBackend  `json:"storageBackend,omitempty"`
+	Controllers        controllers     `json:"controllers"`
+}
+
+// quota represents the bucket memory quota in bytes, where 'RawRAM' is the per-node quota.
+type quota struct {
+	RAM    uint64 `json:"ram"`
+	RawRAM uint64 `json:"rawRAM"`
+}
+
+// controllers contains the bucket controller endpoints, the flush endpoint is only present when flush is enabled.
+type controllers struct {
+	Flush string `json:"flush,omitempty"`
 }
@@ -0,0 +1,391 @@
+package cbrest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/testutil"
+)
+
+// rbacUserEndpointRegex matches the endpoint for a specific user, capturing the domain and user id.
+var rbacUserEndpointRegex = regexp.MustCompile(`^/settings/rbac/users/(local|external)/([^/]+)$`)
+
+// rbacGroupEndpointRegex matches the endpoint for a specific user group, capturing the group name.
+var rbacGroupEndpointRegex = regexp.MustCompile(`^/settings/rbac/groups/([^/]+)$`)
+
+// testRoles are the roles supported by the test cluster, the qualifiers supported by each role are set to '*'.
+var testRoles = []*RoleDefinition{
+	{Role: Role{Name: "admin"}, DisplayName: "Full Admin"},
+	{Role: Role{Name: "ro_admin"}, DisplayName: "Read-Only Admin"},
+	{Role: Role{Name: "cluster_admin"}, DisplayName: "Cluster Admin"},
+	{Role: Role{Name: "bucket_admin", Bucket: "*"}, DisplayName: "Bucket Admin"},
+	{Role: Role{Name: "data_backup", Bucket: "*"}, DisplayName: "Data Backup & Restore"},
+	{Role: Role{Name: "data_reader", Bucket: "*", Scope: "*", Collection: "*"}, DisplayName: "Data Reader"},
+	{Role: Role{Name: "data_writer", Bucket: "*", Scope: "*", Collection: "*"}, DisplayName: "Data Writer"},
+}
+
+// TestUsers is a readability alias around a map of users, keyed by the user id.
+type TestUsers map[string]*TestUser
+
+// TestUser represents a user that will exist in the test cluster, users may be modified using the RBAC endpoints.
+type TestUser struct {
+	// Domain is the domain of the user, defaults to local.
+	Domain   AuthDomain
+	Name     string
+	Password string
+	Roles    []Role
+	Groups   []string
+
+	// Permissions are the permissions reported as granted by the /pools/default/checkPermissions endpoint, users with
+	// the 'admin' role are granted all permissions.
+	Permissions []Permission
+}
+
+// domain returns the domain of the user, defaulting to local.
+func (u *TestUser) domain() AuthDomain {
+	if u.Domain == "" {
+		return AuthDomainLocal
+	}
+
+	return u.Domain
+}
+
+// TestGroups is a readability alias around a map of user groups, keyed by the group name.
+type TestGroups map[string]*TestGroup
+
+// TestGroup represents a user group that will exist in the test cluster, groups may be modified using the RBAC
+// endpoints.
+type TestGroup struct {
+	Description        string
+	Roles              []Role
+	LDAPGroupReference string
+}
+
+// testRole is the structure used when marshalling the roles for a user, including where the role originates from.
+type testRole struct {
+	Role
+	Origins []map[string]string `json:"origins"`
+}
+
+// testUser is the structure used when marshalling a user.
+type testUser struct {
+	ID     string     `json:"id"`
+	Domain AuthDomain `json:"domain"`
+	Name   string     `json:"name"`
+	Roles  []testRole `json:"roles"`
+	Groups []string   `json:"groups"`
+}
+
+// WhoAmI implements the /whoami endpoint, reporting the user which matches the credentials supplied with the request;
+// unknown users are reported as the built-in full administrator.
+func (t *TestCluster) WhoAmI(writer http.ResponseWriter, request *http.Request) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	username, _, _ := request.BasicAuth()
+
+	user, ok := t.options.Users[username]
+	if !ok {
+		testutil.EncodeJSON(t.t, writer, Identity{ID: username, Domain: AuthDomainAdmin, Roles: []Role{{Name: "admin"}}})
+		return
+	}
+
+	encoded := t.createUser(username, user)
+
+	identity := Identity{ID: username, Domain: encoded.Domain, Name: encoded.Name, Roles: make([]Role, 0)}
+
+	for _, role := range encoded.Roles {
+		identity.Roles = append(identity.Roles, role.Role)
+	}
+
+	testutil.EncodeJSON(t.t, writer, identity)
+}
+
+// CheckPermissions implements the /pools/default/checkPermissions endpoint, reporting whether the user which matches
+// the credentials supplied with the request has each of the requested permissions; unknown users are treated as the
+// built-in full administrator.
+func (t *TestCluster) CheckPermissions(writer http.ResponseWriter, request *http.Request) {
+	body, err := io.ReadAll(request.Body)
+	require.NoError(t.t, err)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	username, _, _ := request.BasicAuth()
+
+	user, ok := t.options.Users[username]
+
+	admin := !ok || slices.Contains(user.Roles, Role{Name: "admin"})
+
+	granted := make(map[Permission]bool)
+
+	for _, permission := range strings.Split(string(body), ",") {
+		granted[Permission(permission)] = admin || slices.Contains(user.Permissions, Permission(permission))
+	}
+
+	testutil.EncodeJSON(t.t, writer, granted)
+}
+
+// RBACRoles implements the /settings/rbac/roles endpoint.
+func (t *TestCluster) RBACRoles(writer http.ResponseWriter, request *http.Request) {
+	testutil.EncodeJSON(t.t, writer, testRoles)
+}
+
+// RBACUsers implements the /settings/rbac/users endpoint, values can be modified by modifying the users in the cluster
+// using the cluster options.
+func (t *TestCluster) RBACUsers(writer http.ResponseWriter, request *http.Request) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ids := maps.Keys(t.options.Users)
+	slices.Sort(ids)
+
+	users := make([]testUser, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, t.createUser(id, t.options.Users[id]))
+	}
+
+	testutil.EncodeJSON(t.t, writer, users)
+}
+
+// RBACGroups implements the /settings/rbac/groups endpoint, values can be modified by modifying the groups in the
+// cluster using the cluster options.
+func (t *TestCluster) RBACGroups(writer http.ResponseWriter, request *http.Request) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	names := maps.Keys(t.options.Groups)
+	slices.Sort(names)
+
+	groups := make([]Group, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, t.createGroup(name, t.options.Groups[name]))
+	}
+
+	testutil.EncodeJSON(t.t, writer, groups)
+}
+
+// handleRBACUser handles the user management endpoints for the given user.
+func (t *TestCluster) handleRBACUser(domain AuthDomain, id string, writer http.ResponseWriter, request *http.Request) {
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	user, ok := t.options.Users[id]
+	if ok && user.domain() != domain {
+		user, ok = nil, false
+	}
+
+	switch request.Method {
+	case http.MethodGet:
+		if !ok {
+			writeRBACNotFound(t.t, writer, "Unknown user.")
+			return
+		}
+
+		testutil.EncodeJSON(t.t, writer, t.createUser(id, user))
+	case http.MethodPut:
+		updated := &TestUser{Domain: domain, Name: request.PostForm.Get("name")}
+
+		if ok {
+			updated.Password, updated.Permissions = user.Password, user.Permissions
+		}
+
+		errs := make(map[string]string)
+
+		if password := request.PostForm.Get("password"); password != "" {
+			updated.Password = password
+		}
+
+		if domain == AuthDomainLocal && len(updated.Password) < 6 {
+			errs["password"] = "The password must be at least 6 characters long."
+		}
+
+		updated.Roles = t.parseRoles(request.PostForm.Get("roles"), errs)
+
+		if groups := request.PostForm.Get("groups"); groups != "" {
+			updated.Groups = strings.Split(groups, ",")
+		}
+
+		var missing []string
+
+		for _, group := range updated.Groups {
+			if _, ok := t.options.Groups[group]; !ok {
+				missing = append(missing, group)
+			}
+		}
+
+		if len(missing) != 0 {
+			errs["groups"] = fmt.Sprintf("Groups do not exist: [%s]", strings.Join(missing, ","))
+		}
+
+		if len(errs) != 0 {
+			writeBucketErrors(t.t, writer, errs)
+			return
+		}
+
+		t.options.Users[id] = updated
+
+		testutil.Write(t.t, writer, []byte(`""`))
+	case http.MethodDelete:
+		if !ok {
+			writeRBACNotFound(t.t, writer, "User was not found.")
+			return
+		}
+
+		delete(t.options.Users, id)
+
+		testutil.Write(t.t, writer, []byte(`""`))
+	default:
+		t.t.Fatalf("Endpoint '%s' does not have a handler for method '%s'", request.URL.Path, request.Method)
+	}
+}
+
+// handleRBACGroup handles the group management endpoints for the given group.
+func (t *TestCluster) handleRBACGroup(name string, writer http.ResponseWriter, request *http.Request) {
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	group, ok := t.options.Groups[name]
+
+	switch request.Method {
+	case http.MethodGet:
+		if !ok {
+			writeRBACNotFound(t.t, writer, "Unknown group.")
+			return
+		}
+
+		testutil.EncodeJSON(t.t, writer, t.createGroup(name, group))
+	case http.MethodPut:
+		errs := make(map[string]string)
+
+		updated := &TestGroup{
+			Description:        request.PostForm.Get("description"),
+			Roles:              t.parseRoles(request.PostForm.Get("roles"), errs),
+			LDAPGroupReference: request.PostForm.Get("ldap_group_ref"),
+		}
+
+		if len(errs) != 0 {
+			writeBucketErrors(t.t, writer, errs)
+			return
+		}
+
+		t.options.Groups[name] = updated
+
+		testutil.Write(t.t, writer, []byte(`""`))
+	case http.MethodDelete:
+		if !ok {
+			writeRBACNotFound(t.t, writer, "Group was not found.")
+			return
+		}
+
+		delete(t.options.Groups, name)
+
+		testutil.Write(t.t, writer, []byte(`""`))
+	default:
+		t.t.Fatalf("Endpoint '%s' does not have a handler for method '%s'", request.URL.Path, request.Method)
+	}
+}
+
+// parseRoles parses the given comma separated list of roles, recording an error for any roles which are unknown.
+func (t *TestCluster) parseRoles(encoded string, errs map[string]string) []Role {
+	if encoded == "" {
+		return nil
+	}
+
+	var (
+		roles   = make([]Role, 0)
+		unknown = make([]string, 0)
+	)
+
+	for _, name := range strings.Split(encoded, ",") {
+		role, err := ParseRole(name)
+
+		known := slices.IndexFunc(testRoles, func(definition *RoleDefinition) bool {
+			return definition.Name == role.Name && (definition.Bucket == "") == (role.Bucket == "")
+		}) != -1
+
+		if err != nil || !known {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		roles = append(roles, role)
+	}
+
+	if len(unknown) != 0 {
+		errs["roles"] = fmt.Sprintf("Unknown roles: [%s]", strings.Join(unknown, ","))
+	}
+
+	return roles
+}
+
+// createUser creates the structure used when marshalling the given test user, this includes the roles inherited from
+// any groups.
+func (t *TestCluster) createUser(id string, user *TestUser) testUser {
+	encoded := testUser{
+		ID:     id,
+		Domain: user.domain(),
+		Name:   user.Name,
+		Roles:  make([]testRole, 0),
+		Groups: user.Groups,
+	}
+
+	if encoded.Groups == nil {
+		encoded.Groups = make([]string, 0)
+	}
+
+	for _, role := range user.Roles {
+		encoded.Roles = append(encoded.Roles, testRole{Role: role, Origins: []map[string]string{{"type": "user"}}})
+	}
+
+	for _, name := range user.Groups {
+		group, ok := t.options.Groups[name]
+		if !ok {
+			continue
+		}
+
+		for _, role := range group.Roles {
+			encoded.Roles = append(encoded.Roles, testRole{
+				Role:    role,
+				Origins: []map[string]string{{"type": "group", "name": name}},
+			})
+		}
+	}
+
+	return encoded
+}
+
+// createGroup creates the structure used when marshalling the given test group.
+func (t *TestCluster) createGroup(name string, group *TestGroup) Group {
+	encoded := Group{
+		Name:               name,
+		Description:        group.Description,
+		Roles:              group.Roles,
+		LDAPGroupReference: group.LDAPGroupReference,
+	}
+
+	if encoded.Roles == nil {
+		encoded.Roles = make([]Role, 0)
+	}
+
+	return encoded
+}
+
+// writeRBACNotFound writes the response returned by 'ns_server' when a user/group does not exist.
+func writeRBACNotFound(t *testing.T, writer http.ResponseWriter, msg string) {
+	writer.WriteHeader(http.StatusNotFound)
+	testutil.EncodeJSON(t, writer, msg)
+}
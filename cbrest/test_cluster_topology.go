@@ -0,0 +1,430 @@
+package cbrest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/testutil"
+)
+
+// testRebalance represents a simulated rebalance (or graceful failover) running in a test cluster.
+type testRebalance struct {
+	// id is the unique identifier reported for the rebalance task.
+	id string
+
+	// ejected are the OTP names of the nodes which will be removed from the cluster.
+	ejected []string
+
+	// failover are the OTP names of the nodes being gracefully failed over, this is empty for a normal rebalance.
+	failover []string
+
+	// polls is the number of times the rebalance progress has been polled.
+	polls int
+}
+
+// AddNode implements the /controller/addNode endpoint, adding a new node to the cluster which will become active
+// during the next rebalance.
+func (t *TestCluster) AddNode(writer http.ResponseWriter, request *http.Request) {
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if request.PostForm.Get("hostname") == "" {
+		writeTopologyErrors(t.t, writer, "Hostname is required.")
+		return
+	}
+
+	if t.rebalance != nil {
+		writeTopologyErrors(t.t, writer, "Node addition is disallowed while rebalance is in progress")
+		return
+	}
+
+	services := []Service{ServiceData}
+
+	if names := request.PostForm.Get("services"); names != "" {
+		services = make([]Service, 0)
+
+		for _, name := range strings.Split(names, ",") {
+			service, ok := serviceFromName(name)
+			if !ok {
+				writeTopologyErrors(t.t, writer, fmt.Sprintf("Unknown services: [\"%s\"]", name))
+				return
+			}
+
+			services = append(services, service)
+		}
+	}
+
+	node := &TestNode{
+		Version:    t.options.Nodes[0].Version,
+		Services:   services,
+		OTPNode:    t.nextOTPNode(),
+		Membership: ClusterMembershipInactiveAdded,
+	}
+
+	t.options.Nodes = append(t.options.Nodes, node)
+
+	t.startNode(node)
+
+	testutil.EncodeJSON(t.t, writer, map[string]string{"otpNode": node.OTPNode})
+}
+
+// Failover implements the /controller/failOver endpoint, immediately failing over the given nodes.
+func (t *TestCluster) Failover(writer http.ResponseWriter, request *http.Request) {
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	nodes, ok := t.validateFailover(writer, request.PostForm["otpNode"])
+	if !ok {
+		return
+	}
+
+	for _, node := range nodes {
+		node.Membership = ClusterMembershipInactiveFailed
+		node.RecoveryType = RecoveryTypeNone
+	}
+
+	t.incrementRevision()
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// GracefulFailover implements the /controller/startGracefulFailover endpoint, starting a simulated rebalance which
+// will fail over the given nodes once complete.
+func (t *TestCluster) GracefulFailover(writer http.ResponseWriter, request *http.Request) {
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	_, ok := t.validateFailover(writer, request.PostForm["otpNode"])
+	if !ok {
+		return
+	}
+
+	t.startRebalance(&testRebalance{failover: request.PostForm["otpNode"]})
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// SetRecoveryType implements the /controller/setRecoveryType endpoint, marking a failed over node for recovery.
+func (t *TestCluster) SetRecoveryType(writer http.ResponseWriter, request *http.Request) {
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	node := t.findNode(request.PostForm.Get("otpNode"))
+	if node == nil || node.Membership != ClusterMembershipInactiveFailed {
+		writeTopologyErrors(t.t, writer, "Unknown or not failed over server given.")
+		return
+	}
+
+	recoveryType := RecoveryType(request.PostForm.Get("recoveryType"))
+	if recoveryType != RecoveryTypeFull && recoveryType != RecoveryTypeDelta {
+		writeTopologyErrors(t.t, writer, "Recovery type must be either 'delta' or 'full'.")
+		return
+	}
+
+	node.RecoveryType = recoveryType
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Rebalance implements the /controller/rebalance endpoint, starting a simulated rebalance which will change the cluster
+// topology (and bump the cluster config revision) once complete.
+func (t *TestCluster) Rebalance(writer http.ResponseWriter, request *http.Request) {
+	require.NoError(t.t, request.ParseForm())
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.rebalance != nil {
+		writeTopologyErrors(t.t, writer, "Rebalance running.")
+		return
+	}
+
+	known := splitNodes(request.PostForm.Get("knownNodes"))
+	slices.Sort(known)
+
+	all := make([]string, 0, len(t.options.Nodes))
+	for _, node := range t.options.Nodes {
+		all = append(all, node.OTPNode)
+	}
+
+	slices.Sort(all)
+
+	if !slices.Equal(known, all) {
+		writer.WriteHeader(http.StatusBadRequest)
+		testutil.EncodeJSON(t.t, writer, map[string]int{"mismatch": 1})
+
+		return
+	}
+
+	ejected := splitNodes(request.PostForm.Get("ejectedNodes"))
+
+	for _, name := range ejected {
+		if t.findNode(name) == nil {
+			writeTopologyErrors(t.t, writer, fmt.Sprintf("Unknown ejected node '%s'", name))
+			return
+		}
+	}
+
+	t.startRebalance(&testRebalance{ejected: ejected})
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// StopRebalance implements the /controller/stopRebalance endpoint, stopping the running simulated rebalance without
+// changing the cluster topology.
+func (t *TestCluster) StopRebalance(writer http.ResponseWriter, request *http.Request) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.rebalance = nil
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// RebalanceProgress implements the /pools/default/rebalanceProgress endpoint, each time the progress is polled the
+// running simulated rebalance progresses; it completes once it has been polled 'RebalancePolls' times.
+func (t *TestCluster) RebalanceProgress(writer http.ResponseWriter, request *http.Request) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.advanceRebalance()
+
+	if t.rebalance == nil {
+		progress := map[string]string{"status": string(RebalanceStatusNone)}
+
+		if t.rebalanceError != "" {
+			progress["errorMessage"] = t.rebalanceError
+		}
+
+		testutil.EncodeJSON(t.t, writer, progress)
+
+		return
+	}
+
+	progress := map[string]any{"status": RebalanceStatusRunning}
+
+	for node, percentage := range t.rebalancePerNode() {
+		progress[node] = map[string]float64{"progress": percentage / 100}
+	}
+
+	testutil.EncodeJSON(t.t, writer, progress)
+}
+
+// Tasks implements the /pools/default/tasks endpoint, for the time being only the rebalance task is reported; like
+// the rebalance progress endpoint, the running simulated rebalance progresses each time the tasks are polled.
+func (t *TestCluster) Tasks(writer http.ResponseWriter, request *http.Request) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.advanceRebalance()
+
+	task := map[string]any{"type": TaskTypeRebalance, "status": TaskStatusNotRunning, "statusIsStale": false}
+
+	if t.rebalanceError != "" {
+		task["errorMessage"] = t.rebalanceError
+	}
+
+	if t.rebalance != nil {
+		perNode := make(map[string]map[string]float64)
+		for node, percentage := range t.rebalancePerNode() {
+			perNode[node] = map[string]float64{"progress": percentage}
+		}
+
+		subtype := "rebalance"
+		if len(t.rebalance.failover) != 0 {
+			subtype = "gracefulFailover"
+		}
+
+		task["statusId"] = t.rebalance.id
+		task["subtype"] = subtype
+		task["status"] = TaskStatusRunning
+		task["progress"] = t.rebalancePercentage()
+		task["perNode"] = perNode
+	}
+
+	testutil.EncodeJSON(t.t, writer, []any{task})
+}
+
+// advanceRebalance progresses the running simulated rebalance (if there is one), completing it once it has been
+// progressed 'RebalancePolls' times.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) advanceRebalance() {
+	if t.rebalance == nil {
+		return
+	}
+
+	t.rebalance.polls++
+
+	if t.rebalance.polls >= t.options.RebalancePolls {
+		t.completeRebalance()
+	}
+}
+
+// rebalancePercentage returns the percentage (0-100) of the running simulated rebalance which has been completed.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) rebalancePercentage() float64 {
+	return float64(t.rebalance.polls) / float64(t.options.RebalancePolls) * 100
+}
+
+// rebalancePerNode returns the percentage (0-100) of the running simulated rebalance which has been completed for each
+// node, which is the same for every node which isn't failed over.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) rebalancePerNode() map[string]float64 {
+	perNode := make(map[string]float64)
+
+	for _, node := range t.options.Nodes {
+		if node.Membership != ClusterMembershipInactiveFailed {
+			perNode[node.OTPNode] = t.rebalancePercentage()
+		}
+	}
+
+	return perNode
+}
+
+// validateFailover validates that the given nodes may be failed over, writing a 400 response if they may not.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) validateFailover(writer http.ResponseWriter, names []string) ([]*TestNode, bool) {
+	if t.rebalance != nil {
+		writeTopologyErrors(t.t, writer, "Rebalance running.")
+		return nil, false
+	}
+
+	if len(names) == 0 {
+		writeTopologyErrors(t.t, writer, "No server specified.")
+		return nil, false
+	}
+
+	nodes := make([]*TestNode, 0, len(names))
+
+	for _, name := range names {
+		node := t.findNode(name)
+		if node == nil || !node.active() {
+			writeTopologyErrors(t.t, writer, "Unknown server given.")
+			return nil, false
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, true
+}
+
+// startRebalance starts the given simulated rebalance, completing it immediately if the cluster has not been configured
+// to require the progress to be polled.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) startRebalance(rebalance *testRebalance) {
+	rebalance.id = uuid.NewString()
+
+	t.rebalance = rebalance
+	t.rebalanceError = ""
+
+	if t.options.RebalancePolls <= 0 {
+		t.completeRebalance()
+	}
+}
+
+// completeRebalance completes the running simulated rebalance, updating the cluster topology and bumping the cluster
+// config revision (unless the rebalance has been configured to fail).
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) completeRebalance() {
+	rebalance := t.rebalance
+	t.rebalance = nil
+
+	if t.options.RebalanceError != "" {
+		t.rebalanceError = t.options.RebalanceError
+		return
+	}
+
+	if len(rebalance.failover) != 0 {
+		for _, name := range rebalance.failover {
+			node := t.findNode(name)
+			node.Membership = ClusterMembershipInactiveFailed
+			node.RecoveryType = RecoveryTypeNone
+		}
+
+		t.incrementRevision()
+
+		return
+	}
+
+	nodes := make(TestNodes, 0, len(t.options.Nodes))
+
+	for _, node := range t.options.Nodes {
+		if slices.Contains(rebalance.ejected, node.OTPNode) {
+			continue
+		}
+
+		// Failed over nodes are removed from the cluster, unless they've been marked for recovery
+		if node.Membership == ClusterMembershipInactiveFailed &&
+			(node.RecoveryType == "" || node.RecoveryType == RecoveryTypeNone) {
+			continue
+		}
+
+		node.Membership = ClusterMembershipActive
+		node.RecoveryType = RecoveryTypeNone
+
+		nodes = append(nodes, node)
+	}
+
+	t.options.Nodes = nodes
+
+	t.incrementRevision()
+}
+
+// findNode returns the test node with the given OTP name, or <nil> if it's not part of the cluster.
+//
+// NOTE: The cluster lock must be held by the caller.
+func (t *TestCluster) findNode(name string) *TestNode {
+	idx := slices.IndexFunc(t.options.Nodes, func(node *TestNode) bool { return node.OTPNode == name })
+	if idx == -1 {
+		return nil
+	}
+
+	return t.options.Nodes[idx]
+}
+
+// nextOTPNode returns a unique OTP name for a node in the cluster, names are never reused even if a node is removed.
+func (t *TestCluster) nextOTPNode() string {
+	defer func() { t.nextNode++ }()
+	return fmt.Sprintf("n_%d@%s", t.nextNode, t.Address())
+}
+
+// splitNodes splits the given comma separated list of OTP names, ignoring empty names.
+func splitNodes(nodes string) []string {
+	split := make([]string, 0)
+
+	for _, node := range strings.Split(nodes, ",") {
+		if node != "" {
+			split = append(split, node)
+		}
+	}
+
+	return split
+}
+
+// writeTopologyErrors writes the response returned by 'ns_server' when a topology change is rejected.
+func writeTopologyErrors(t *testing.T, writer http.ResponseWriter, errs ...string) {
+	writer.WriteHeader(http.StatusBadRequest)
+	testutil.EncodeJSON(t, writer, errs)
+}
@@ -33,6 +33,18 @@ func (e TestHandlers) Handle(writer http.ResponseWriter, request *http.Request)
 	return true
 }
 
+// WriteTestJSON writes the given status code followed by the JSON encoded body; this may be used by handlers which
+// simulate the behavior of a service.
+func WriteTestJSON(t *testing.T, writer http.ResponseWriter, status int, body any) {
+	encoded, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	writer.WriteHeader(status)
+
+	_, err = writer.Write(encoded)
+	require.NoError(t, err)
+}
+
 // NewTestHandler creates the most basic type of handler which will respond with the provided status/body.
 func NewTestHandler(t *testing.T, status int, body []byte) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
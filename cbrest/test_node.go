@@ -1,6 +1,10 @@
 package cbrest
 
-import "github.com/couchbase/tools-common/cbvalue"
+import (
+	"net/http/httptest"
+
+	"github.com/couchbase/tools-common/cbvalue"
+)
 
 // TestNodes is a readbility wrapper around a slice of test nodes.
 type TestNodes []*TestNode
@@ -12,4 +16,24 @@ type TestNode struct {
 	Services   []Service
 	SSL        bool
 	AltAddress bool
+
+	// OTPNode is the unique name of the node in the cluster, a name will be generated if one is not provided.
+	OTPNode string
+
+	// Membership/RecoveryType are the membership state of the node, the node is active by default; these are updated by
+	// the test cluster when the topology is changed via the REST API.
+	Membership   ClusterMembership
+	RecoveryType RecoveryType
+
+	// server is the server for the node, only set when the cluster is using separate node addresses.
+	server *httptest.Server
+
+	// failStatus/hang are the faults injected into the node using 'FailNode'/'HangNode'.
+	failStatus int
+	hang       bool
+}
+
+// active returns a boolean indicating whether the node is an active member of the cluster.
+func (t *TestNode) active() bool {
+	return t.Membership == "" || t.Membership == ClusterMembershipActive
 }
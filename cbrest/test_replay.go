@@ -0,0 +1,83 @@
+package cbrest
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/maths"
+	"github.com/couchbase/tools-common/testutil"
+)
+
+// replayIgnoredEndpointRegex matches the endpoints which describe the topology of the recorded cluster, they're not
+// replayed because the test cluster must report its own address for the client to dispatch requests to it.
+var replayIgnoredEndpointRegex = regexp.MustCompile(`^/pools/default/(nodeServices|nodeServicesStreaming|bs/[^/]+)$`)
+
+// replayIgnoredHeaders are the response headers which are not replayed, because they're set by the test cluster.
+var replayIgnoredHeaders = []string{"Content-Length", "Date", "Transfer-Encoding"}
+
+// NewReplayHandlers creates handlers which replay the interactions in the given fixture file, which should have been
+// created using a 'Recorder'. The returned handlers may be supplemented with additional handlers before being used to
+// create a test cluster.
+//
+// NOTE: Responses for the same method/endpoint are replayed in the order they were recorded, with the final response
+// being repeated once they've been exhausted; query parameters and request bodies are not matched. User data tags are
+// removed from the paths/responses, redacted credentials are replayed as is; truncated responses are replayed without a
+// body.
+func NewReplayHandlers(t *testing.T, path string) TestHandlers {
+	interactions, err := LoadInteractions(path)
+	require.NoError(t, err)
+
+	type key struct {
+		method, path string
+	}
+
+	responses := make(map[key][]*RecordedResponse)
+
+	for _, interaction := range interactions {
+		if replayIgnoredEndpointRegex.MatchString(interaction.Request.Path) {
+			continue
+		}
+
+		k := key{method: interaction.Request.Method, path: untagUserData(interaction.Request.Path)}
+
+		responses[k] = append(responses[k], interaction.Response)
+	}
+
+	handlers := make(TestHandlers)
+
+	for k, recorded := range responses {
+		handlers.Add(k.method, k.path, newReplayHandler(t, recorded))
+	}
+
+	return handlers
+}
+
+// newReplayHandler creates a handler which replays the given responses in order, repeating the final response.
+func newReplayHandler(t *testing.T, responses []*RecordedResponse) http.HandlerFunc {
+	var (
+		lock sync.Mutex
+		next int
+	)
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		lock.Lock()
+		response := responses[maths.Min(next, len(responses)-1)]
+		next++
+		lock.Unlock()
+
+		for name, values := range response.Header {
+			writer.Header()[name] = values
+		}
+
+		for _, name := range replayIgnoredHeaders {
+			writer.Header().Del(name)
+		}
+
+		writer.WriteHeader(response.StatusCode)
+		testutil.Write(t, writer, []byte(untagUserData(string(response.Body))))
+	}
+}
@@ -0,0 +1,514 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ClusterMembership represents the membership state of a node in the cluster.
+type ClusterMembership string
+
+const (
+	// ClusterMembershipActive indicates that the node is an active member of the cluster.
+	ClusterMembershipActive ClusterMembership = "active"
+
+	// ClusterMembershipInactiveAdded indicates that the node has been added to the cluster, but will not become active
+	// until the cluster is rebalanced.
+	ClusterMembershipInactiveAdded ClusterMembership = "inactiveAdded"
+
+	// ClusterMembershipInactiveFailed indicates that the node has been failed over, it will be removed from the cluster
+	// during the next rebalance unless it's marked for recovery.
+	ClusterMembershipInactiveFailed ClusterMembership = "inactiveFailed"
+)
+
+// RecoveryType represents the type of recovery which will be performed for a failed over node.
+type RecoveryType string
+
+const (
+	// RecoveryTypeNone indicates that the node has not been marked for recovery.
+	RecoveryTypeNone RecoveryType = "none"
+
+	// RecoveryTypeFull indicates that all the data on the node will be removed, and rebuilt during the rebalance.
+	RecoveryTypeFull RecoveryType = "full"
+
+	// RecoveryTypeDelta indicates that the existing data on the node will be reused, only the mutations which occurred
+	// since the node was failed over will be streamed to the node during the rebalance.
+	RecoveryTypeDelta RecoveryType = "delta"
+)
+
+// RebalanceStatus represents the status of a rebalance.
+type RebalanceStatus string
+
+const (
+	// RebalanceStatusNone indicates that there's no rebalance running.
+	RebalanceStatusNone RebalanceStatus = "none"
+
+	// RebalanceStatusRunning indicates that a rebalance (or graceful failover) is running.
+	RebalanceStatusRunning RebalanceStatus = "running"
+)
+
+// serviceNames maps the services to the names used by 'ns_server' when adding nodes/listing the services running on
+// each node.
+var serviceNames = map[Service]string{
+	ServiceAnalytics: "cbas",
+	ServiceBackup:    "backup",
+	ServiceData:      "kv",
+	ServiceEventing:  "eventing",
+	ServiceGSI:       "index",
+	ServiceQuery:     "n1ql",
+	ServiceSearch:    "fts",
+}
+
+// serviceFromName returns the service with the given 'ns_server' name.
+func serviceFromName(name string) (Service, bool) {
+	for service, serviceName := range serviceNames {
+		if serviceName == name {
+			return service, true
+		}
+	}
+
+	return "", false
+}
+
+// ClusterNode represents the membership information for a single node in the cluster, as opposed to the addressing
+// information represented by a 'Node'.
+type ClusterNode struct {
+	// OTPNode is the unique name of the node in the cluster e.g. 'ns_1@172.20.1.1'; this is the name which should be
+	// used to identify the node when changing the cluster topology.
+	OTPNode      string
+	Hostname     string
+	Services     []Service
+	Membership   ClusterMembership
+	Status       string
+	RecoveryType RecoveryType
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, converting the 'ns_server' service names into services.
+//
+// NOTE: Services which aren't known by this package are ignored.
+func (c *ClusterNode) UnmarshalJSON(data []byte) error {
+	type overlay struct {
+		OTPNode      string            `json:"otpNode"`
+		Hostname     string            `json:"hostname"`
+		Services     []string          `json:"services"`
+		Membership   ClusterMembership `json:"clusterMembership"`
+		Status       string            `json:"status"`
+		RecoveryType RecoveryType      `json:"recoveryType"`
+	}
+
+	var decoded overlay
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	*c = ClusterNode{
+		OTPNode:      decoded.OTPNode,
+		Hostname:     decoded.Hostname,
+		Services:     make([]Service, 0, len(decoded.Services)),
+		Membership:   decoded.Membership,
+		Status:       decoded.Status,
+		RecoveryType: decoded.RecoveryType,
+	}
+
+	for _, name := range decoded.Services {
+		if service, ok := serviceFromName(name); ok {
+			c.Services = append(c.Services, service)
+		}
+	}
+
+	return nil
+}
+
+// AddNodeOptions encapsulates the options which may be supplied when adding a node to the cluster.
+type AddNodeOptions struct {
+	// Hostname is the address of the node to add, optionally including a scheme/port e.g. 'https://172.20.1.2:18091'.
+	//
+	// NOTE: This attribute is required.
+	Hostname string
+
+	// Username/Password are the credentials for the node being added, these may be omitted if the node is uninitialized.
+	Username string
+	Password string
+
+	// Services are the services which will run on the node, 'ns_server' will default to the Data Service if none are
+	// provided.
+	Services []Service
+}
+
+// values returns the options encoded as form values.
+func (a AddNodeOptions) values() url.Values {
+	values := url.Values{"hostname": {a.Hostname}}
+
+	if a.Username != "" {
+		values.Set("user", a.Username)
+		values.Set("password", a.Password)
+	}
+
+	if len(a.Services) != 0 {
+		names := make([]string, 0, len(a.Services))
+		for _, service := range a.Services {
+			names = append(names, serviceNames[service])
+		}
+
+		values.Set("services", strings.Join(names, ","))
+	}
+
+	return values
+}
+
+// FailoverOptions encapsulates the options which may be supplied when failing over nodes.
+type FailoverOptions struct {
+	// Nodes are the OTP names of the nodes to failover.
+	//
+	// NOTE: This attribute is required.
+	Nodes []string
+
+	// Graceful indicates that the active vBuckets on the nodes should be moved to other nodes before the nodes are
+	// failed over; this runs as a rebalance, use 'WaitForRebalance' to wait for it to complete.
+	Graceful bool
+
+	// AllowUnsafe allows a hard failover which may result in data loss e.g. failing over a majority of the nodes; this is
+	// not supported for graceful failover.
+	AllowUnsafe bool
+}
+
+// RebalanceProgress represents the progress of a rebalance (or graceful failover).
+type RebalanceProgress struct {
+	Status RebalanceStatus
+
+	// Nodes is the percentage (0-100) of the rebalance which has been completed for each node, keyed by the OTP name of
+	// the node; this will be empty if there's no rebalance running.
+	Nodes map[string]float64
+
+	// ErrorMessage is populated by 'ns_server' when the last rebalance failed.
+	ErrorMessage string
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, 'ns_server' returns the per-node progress as top level
+// attributes alongside the status.
+func (r *RebalanceProgress) UnmarshalJSON(data []byte) error {
+	var decoded map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	*r = RebalanceProgress{Nodes: make(map[string]float64)}
+
+	for key, value := range decoded {
+		switch key {
+		case "status":
+			err = json.Unmarshal(value, &r.Status)
+		case "errorMessage":
+			err = json.Unmarshal(value, &r.ErrorMessage)
+		default:
+			var progress struct {
+				Progress *float64 `json:"progress"`
+			}
+
+			// Purposefully ignored, 'ns_server' may return additional attributes which aren't per-node progress
+			if json.Unmarshal(value, &progress) == nil && progress.Progress != nil {
+				r.Nodes[key] = *progress.Progress * 100
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal '%s': %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// Percentage returns the overall percentage (0-100) of the rebalance which has been completed, this is the mean of the
+// per-node progress.
+func (r *RebalanceProgress) Percentage() float64 {
+	if len(r.Nodes) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, progress := range r.Nodes {
+		total += progress
+	}
+
+	return total / float64(len(r.Nodes))
+}
+
+// ListClusterNodes returns the membership information for all the nodes in the cluster, including nodes which have
+// been added/failed over but not yet rebalanced in/out of the cluster.
+func (c *Client) ListClusterNodes(ctx context.Context) ([]*ClusterNode, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointPoolsDefault,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	type overlay struct {
+		Nodes []*ClusterNode `json:"nodes"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return decoded.Nodes, nil
+}
+
+// AddNode adds a node to the cluster returning its OTP name, the node will not become active until the cluster is
+// rebalanced.
+func (c *Client) AddNode(ctx context.Context, options AddNodeOptions) (string, error) {
+	request := &Request{
+		Body:               []byte(options.values().Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointAddNode,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return "", handleTopologyError("add node", response, err)
+	}
+
+	type overlay struct {
+		OTPNode string `json:"otpNode"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return decoded.OTPNode, nil
+}
+
+// FailoverNodes fails over the given nodes.
+//
+// NOTE: Hard failover completes synchronously, whilst graceful failover runs in the background as a rebalance.
+func (c *Client) FailoverNodes(ctx context.Context, options FailoverOptions) error {
+	if len(options.Nodes) == 0 {
+		return ErrNodesRequired
+	}
+
+	values := url.Values{"otpNode": options.Nodes}
+
+	endpoint := EndpointFailover
+	if options.Graceful {
+		endpoint = EndpointGracefulFailover
+	}
+
+	if options.AllowUnsafe && !options.Graceful {
+		values.Set("allowUnsafe", "true")
+	}
+
+	request := &Request{
+		Body:               []byte(values.Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           endpoint,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleTopologyError("failover nodes", response, err)
+	}
+
+	return nil
+}
+
+// RecoverNode marks the given failed over node for recovery, the node will be recovered using the given recovery type
+// during the next rebalance.
+func (c *Client) RecoverNode(ctx context.Context, node string, recoveryType RecoveryType) error {
+	values := url.Values{"otpNode": {node}, "recoveryType": {string(recoveryType)}}
+
+	request := &Request{
+		Body:               []byte(values.Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointSetRecoveryType,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+		Idempotent:         true,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleTopologyError("recover node", response, err)
+	}
+
+	return nil
+}
+
+// StartRebalance starts a rebalance which will remove the given nodes (identified by their OTP names) from the cluster;
+// any added/recovered nodes will become active, and any failed over nodes will be removed.
+//
+// NOTE: The rebalance runs in the background, use 'WaitForRebalance' to wait for it to complete.
+func (c *Client) StartRebalance(ctx context.Context, ejected ...string) error {
+	nodes, err := c.ListClusterNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	known := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		known = append(known, node.OTPNode)
+	}
+
+	values := url.Values{"knownNodes": {strings.Join(known, ",")}, "ejectedNodes": {strings.Join(ejected, ",")}}
+
+	request := &Request{
+		Body:               []byte(values.Encode()),
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRebalance,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleTopologyError("start rebalance", response, err)
+	}
+
+	return nil
+}
+
+// StopRebalance stops the running rebalance (or graceful failover), this is a no-op if there's no rebalance running.
+func (c *Client) StopRebalance(ctx context.Context) error {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointStopRebalance,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            ServiceManagement,
+		Idempotent:         true,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleTopologyError("stop rebalance", response, err)
+	}
+
+	return nil
+}
+
+// GetRebalanceProgress returns the progress of the running rebalance (or graceful failover).
+func (c *Client) GetRebalanceProgress(ctx context.Context) (*RebalanceProgress, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointRebalanceProgress,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var progress *RebalanceProgress
+
+	err = json.Unmarshal(response.Body, &progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return progress, nil
+}
+
+// WaitForRebalance blocks until the running rebalance (or graceful failover) completes, calling the given function (if
+// non-nil) with the progress each time it's polled; a 'RebalanceFailedError' is returned if the rebalance fails. This
+// is a wrapper around 'WaitForTask' for the rebalance task.
+//
+// NOTE: Rebalances may take a long time, so unlike other polling functions, the poll timeout is not applied; the given
+// context should be used to control the timeout.
+func (c *Client) WaitForRebalance(ctx context.Context, fn func(progress *RebalanceProgress)) error {
+	options := WaitForTaskOptions{Timeout: -1}
+
+	if fn != nil {
+		options.ProgressFunc = func(task *Task) { fn(rebalanceProgress(task)) }
+	}
+
+	_, err := c.WaitForTask(ctx, MatchTaskType(TaskTypeRebalance), options)
+
+	var failed *TaskFailedError
+	if errors.As(err, &failed) {
+		return &RebalanceFailedError{reason: failed.Task().ErrorMessage}
+	}
+
+	if IsTaskTimeout(err) {
+		return fmt.Errorf("timed out waiting for rebalance to complete: %w", ctx.Err())
+	}
+
+	return err // Purposefully not wrapped
+}
+
+// rebalanceProgress converts the given rebalance task into the equivalent rebalance progress.
+func rebalanceProgress(task *Task) *RebalanceProgress {
+	progress := &RebalanceProgress{
+		Status:       RebalanceStatusNone,
+		Nodes:        make(map[string]float64, len(task.PerNode)),
+		ErrorMessage: task.ErrorMessage,
+	}
+
+	if task.Running() {
+		progress.Status = RebalanceStatusRunning
+	}
+
+	for node, percentage := range task.PerNode {
+		progress.Nodes[node] = percentage
+	}
+
+	return progress
+}
+
+// handleTopologyError converts the error returned by a topology change request into a 'TopologyChangeError' where
+// possible, using the reason returned by 'ns_server'.
+func handleTopologyError(action string, response *Response, err error) error {
+	if response == nil || response.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var (
+		reasons  []string
+		mismatch struct {
+			Mismatch int `json:"mismatch"`
+		}
+	)
+
+	// 'ns_server' returns the reason in a number of different formats depending on the endpoint, fallback to using the
+	// raw body if it's not in a known format
+	switch {
+	case json.Unmarshal(response.Body, &reasons) == nil && len(reasons) != 0:
+		return &TopologyChangeError{action: action, reason: strings.Join(reasons, ", ")}
+	case json.Unmarshal(response.Body, &mismatch) == nil && mismatch.Mismatch != 0:
+		return &TopologyChangeError{action: action, reason: "the cluster topology has changed"}
+	}
+
+	return &TopologyChangeError{action: action, reason: strings.TrimSpace(string(response.Body))}
+}
@@ -0,0 +1,354 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddNodeOptionsValues(t *testing.T) {
+	options := AddNodeOptions{
+		Hostname: "https://172.20.1.2:18091",
+		Username: "admin",
+		Password: "password",
+		Services: []Service{ServiceData, ServiceQuery, ServiceGSI},
+	}
+
+	require.Equal(t, "hostname=https%3A%2F%2F172.20.1.2%3A18091&password=password&services=kv%2Cn1ql%2Cindex&"+
+		"user=admin", options.values().Encode())
+
+	require.Equal(t, "hostname=172.20.1.2", AddNodeOptions{Hostname: "172.20.1.2"}.values().Encode())
+}
+
+func TestClusterNodeUnmarshalJSON(t *testing.T) {
+	data := []byte(`{
+  "otpNode": "ns_1@172.20.1.1",
+  "hostname": "172.20.1.1:8091",
+  "services": ["kv", "n1ql", "unknown"],
+  "clusterMembership": "inactiveFailed",
+  "status": "unhealthy",
+  "recoveryType": "delta"
+}`)
+
+	var node *ClusterNode
+
+	require.NoError(t, json.Unmarshal(data, &node))
+
+	expected := &ClusterNode{
+		OTPNode:      "ns_1@172.20.1.1",
+		Hostname:     "172.20.1.1:8091",
+		Services:     []Service{ServiceData, ServiceQuery},
+		Membership:   ClusterMembershipInactiveFailed,
+		Status:       "unhealthy",
+		RecoveryType: RecoveryTypeDelta,
+	}
+
+	require.Equal(t, expected, node)
+}
+
+func TestRebalanceProgressUnmarshalJSON(t *testing.T) {
+	type test struct {
+		name       string
+		data       string
+		expected   *RebalanceProgress
+		percentage float64
+	}
+
+	tests := []*test{
+		{
+			name:     "None",
+			data:     `{"status":"none"}`,
+			expected: &RebalanceProgress{Status: RebalanceStatusNone, Nodes: map[string]float64{}},
+		},
+		{
+			name: "Failed",
+			data: `{"status":"none","errorMessage":"Rebalance failed. See logs for detailed reason."}`,
+			expected: &RebalanceProgress{
+				Status:       RebalanceStatusNone,
+				Nodes:        map[string]float64{},
+				ErrorMessage: "Rebalance failed. See logs for detailed reason.",
+			},
+		},
+		{
+			name: "Running",
+			data: `{"status":"running","ns_1@172.20.1.1":{"progress":0.25},"ns_1@172.20.1.2":{"progress":0.75}}`,
+			expected: &RebalanceProgress{
+				Status: RebalanceStatusRunning,
+				Nodes:  map[string]float64{"ns_1@172.20.1.1": 25, "ns_1@172.20.1.2": 75},
+			},
+			percentage: 50,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var progress *RebalanceProgress
+
+			require.NoError(t, json.Unmarshal([]byte(test.data), &progress))
+			require.Equal(t, test.expected, progress)
+			require.Equal(t, test.percentage, progress.Percentage())
+		})
+	}
+}
+
+func TestHandleTopologyError(t *testing.T) {
+	type test struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name:     "List",
+			status:   http.StatusBadRequest,
+			body:     `["Prepare join failed. Node is already part of cluster."]`,
+			expected: "Prepare join failed. Node is already part of cluster.",
+		},
+		{
+			name:     "Mismatch",
+			status:   http.StatusBadRequest,
+			body:     `{"mismatch":1}`,
+			expected: "the cluster topology has changed",
+		},
+		{
+			name:     "Text",
+			status:   http.StatusBadRequest,
+			body:     "Unknown server given.\r\n",
+			expected: "Unknown server given.",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := handleTopologyError("test", &Response{StatusCode: test.status, Body: []byte(test.body)},
+				&UnexpectedStatusCodeError{Status: test.status})
+
+			var topologyErr *TopologyChangeError
+			require.ErrorAs(t, err, &topologyErr)
+			require.Equal(t, test.expected, topologyErr.Reason())
+		})
+	}
+
+	err := handleTopologyError("test", &Response{StatusCode: http.StatusInternalServerError},
+		&InternalServerError{})
+
+	var serverErr *InternalServerError
+	require.ErrorAs(t, err, &serverErr)
+}
+
+func TestClientAddNodeAndRebalance(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{Services: []Service{ServiceData}}}})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	otpNode, err := client.AddNode(context.Background(), AddNodeOptions{
+		Hostname: "172.20.1.2",
+		Services: []Service{ServiceQuery},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "n_1@127.0.0.1", otpNode)
+
+	nodes, err := client.ListClusterNodes(context.Background())
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	require.Equal(t, ClusterMembershipActive, nodes[0].Membership)
+	require.Equal(t, ClusterMembershipInactiveAdded, nodes[1].Membership)
+	require.Equal(t, []Service{ServiceQuery}, nodes[1].Services)
+
+	// The added node should not be part of the cluster config until the cluster is rebalanced
+	require.Len(t, cluster.Nodes(), 1)
+
+	cluster.lock.Lock()
+	revision := cluster.revision
+	cluster.lock.Unlock()
+
+	require.NoError(t, client.StartRebalance(context.Background()))
+	require.NoError(t, client.WaitForRebalance(context.Background(), nil))
+
+	nodes, err = client.ListClusterNodes(context.Background())
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	require.Equal(t, ClusterMembershipActive, nodes[1].Membership)
+
+	require.Len(t, cluster.Nodes(), 2)
+
+	cluster.lock.Lock()
+	defer cluster.lock.Unlock()
+
+	require.Greater(t, cluster.revision, revision)
+}
+
+func TestClientRebalanceStreamsClusterConfig(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{Services: []Service{ServiceData}}, {}}})
+	defer cluster.Close()
+
+	client := newTestStreamingClient(t, cluster, "")
+	defer client.Close()
+
+	configs, unsubscribe := client.SubscribeClusterConfig()
+	defer unsubscribe()
+
+	require.NoError(t, client.StartRebalance(context.Background(), "n_1@127.0.0.1"))
+
+	timeout := time.After(5 * time.Second)
+
+	// The ejected node should be removed from the streamed cluster config once the rebalance completes
+	for {
+		select {
+		case config := <-configs:
+			if len(config.Nodes) == 1 {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for the ejected node to be removed from the cluster config")
+		}
+	}
+}
+
+func TestClientWaitForRebalanceProgress(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{}, {}}, RebalancePolls: 2})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	require.NoError(t, client.StartRebalance(context.Background(), "n_1@127.0.0.1"))
+
+	var percentages []float64
+
+	err = client.WaitForRebalance(context.Background(), func(progress *RebalanceProgress) {
+		percentages = append(percentages, progress.Percentage())
+	})
+	require.NoError(t, err)
+	require.Equal(t, []float64{50, 0}, percentages)
+
+	require.Len(t, cluster.Nodes(), 1)
+}
+
+func TestClientStopRebalance(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{}, {}}, RebalancePolls: 10})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	require.NoError(t, client.StartRebalance(context.Background(), "n_1@127.0.0.1"))
+
+	progress, err := client.GetRebalanceProgress(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, RebalanceStatusRunning, progress.Status)
+	require.Equal(t, map[string]float64{"n_0@127.0.0.1": 10, "n_1@127.0.0.1": 10}, progress.Nodes)
+
+	require.NoError(t, client.StopRebalance(context.Background()))
+
+	progress, err = client.GetRebalanceProgress(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, RebalanceStatusNone, progress.Status)
+
+	// The topology should not change when the rebalance is stopped
+	require.Len(t, cluster.Nodes(), 2)
+}
+
+func TestClientWaitForRebalanceFailed(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{}, {}}, RebalanceError: "Rebalance failed."})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	require.NoError(t, client.StartRebalance(context.Background(), "n_1@127.0.0.1"))
+
+	err = client.WaitForRebalance(context.Background(), nil)
+	require.True(t, IsRebalanceFailed(err))
+
+	require.Len(t, cluster.Nodes(), 2)
+}
+
+func TestClientFailoverNodes(t *testing.T) {
+	type test struct {
+		name         string
+		options      FailoverOptions
+		recoveryType RecoveryType
+		expected     int
+	}
+
+	tests := []*test{
+		{
+			name:     "Hard",
+			options:  FailoverOptions{Nodes: []string{"n_1@127.0.0.1"}},
+			expected: 1,
+		},
+		{
+			name:     "Graceful",
+			options:  FailoverOptions{Nodes: []string{"n_1@127.0.0.1"}, Graceful: true},
+			expected: 1,
+		},
+		{
+			name:         "HardWithRecovery",
+			options:      FailoverOptions{Nodes: []string{"n_1@127.0.0.1"}, AllowUnsafe: true},
+			recoveryType: RecoveryTypeDelta,
+			expected:     2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{}, {}}})
+			defer cluster.Close()
+
+			client, err := newTestClient(cluster, true)
+			require.NoError(t, err)
+
+			require.NoError(t, client.FailoverNodes(context.Background(), test.options))
+			require.NoError(t, client.WaitForRebalance(context.Background(), nil))
+
+			// Failed over nodes are removed from the cluster config immediately
+			require.Len(t, cluster.Nodes(), 1)
+
+			nodes, err := client.ListClusterNodes(context.Background())
+			require.NoError(t, err)
+			require.Len(t, nodes, 2)
+			require.Equal(t, ClusterMembershipInactiveFailed, nodes[1].Membership)
+
+			if test.recoveryType != "" {
+				require.NoError(t, client.RecoverNode(context.Background(), nodes[1].OTPNode, test.recoveryType))
+			}
+
+			require.NoError(t, client.StartRebalance(context.Background()))
+			require.NoError(t, client.WaitForRebalance(context.Background(), nil))
+
+			require.Len(t, cluster.Nodes(), test.expected)
+		})
+	}
+}
+
+func TestClientTopologyChangeErrors(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{Nodes: TestNodes{{}, {}}})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, client.FailoverNodes(context.Background(), FailoverOptions{}), ErrNodesRequired)
+
+	var topologyErr *TopologyChangeError
+
+	err = client.FailoverNodes(context.Background(), FailoverOptions{Nodes: []string{"unknown"}})
+	require.ErrorAs(t, err, &topologyErr)
+	require.Equal(t, "Unknown server given.", topologyErr.Reason())
+
+	err = client.RecoverNode(context.Background(), "n_1@127.0.0.1", RecoveryTypeFull)
+	require.ErrorAs(t, err, &topologyErr)
+
+	_, err = client.AddNode(context.Background(), AddNodeOptions{})
+	require.ErrorAs(t, err, &topologyErr)
+	require.Equal(t, "Hostname is required.", topologyErr.Reason())
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/couchbase/tools-common/aprov"
 	"github.com/couchbase/tools-common/errutil"
 	"github.com/couchbase/tools-common/log"
 	"github.com/couchbase/tools-common/maths"
@@ -90,8 +91,11 @@ func readBody(method Method, endpoint Endpoint, reader io.Reader, contentLength
 
 // setAuthHeaders is a utility function which sets all the request headers which are provided by the 'AuthProvider'.
 func setAuthHeaders(host string, authProvider *AuthProvider, req *http.Request) {
-	// Use the auth provider to populate the credentials
-	req.SetBasicAuth(authProvider.provider.GetCredentials(host))
+	// Use the auth provider to populate the credentials, certificate providers authenticate using the client
+	// certificate presented during the TLS handshake so shouldn't send basic auth credentials.
+	if _, ok := authProvider.provider.(aprov.CertificateProvider); !ok {
+		req.SetBasicAuth(authProvider.provider.GetCredentials(host))
+	}
 
 	// Set the 'User-Agent' so that we can trace how these requests are handled by the cluster
 	req.Header.Set("User-Agent", authProvider.GetUserAgent())
@@ -159,7 +163,54 @@ func handleRequestError(req *http.Request, err error) error {
 
 // handleResponseError is a utility function which converts a failed REST request (soft failure i.e. the request itself
 // was successful) into a more useful/user friendly error.
+//
+// NOTE: Services which return an array of errors in the response body (e.g. the Analytics/Query Services) will have the
+// returned error wrapped in a 'ServiceError'.
 func handleResponseError(method Method, endpoint Endpoint, statusCode int, body []byte) error {
+	err := handleStatusCodeError(method, endpoint, statusCode, body)
+
+	// Authentication/authorization failures are handled consistently across the services
+	if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+		return err
+	}
+
+	details := parseServiceErrors(body)
+	if len(details) == 0 {
+		return err
+	}
+
+	return &ServiceError{status: statusCode, details: details, err: err}
+}
+
+// parseServiceErrors attempts to parse the array of errors returned by services such as the Analytics/Query Services
+// e.g. '{"errors":[{"code":24045,"msg":"Cannot find analytics scope"}]}', returning <nil> if the body is not in this
+// format.
+func parseServiceErrors(body []byte) []ServiceErrorDetail {
+	type overlay struct {
+		Errors []ServiceErrorDetail `json:"errors"`
+	}
+
+	var decoded overlay
+
+	// Purposefully ignored, most services don't return this payload
+	err := json.Unmarshal(body, &decoded)
+	if err != nil {
+		return nil
+	}
+
+	details := make([]ServiceErrorDetail, 0, len(decoded.Errors))
+
+	for _, detail := range decoded.Errors {
+		if detail.Code != 0 || detail.Message != "" {
+			details = append(details, detail)
+		}
+	}
+
+	return details
+}
+
+// handleStatusCodeError converts the given status code into an error.
+func handleStatusCodeError(method Method, endpoint Endpoint, statusCode int, body []byte) error {
 	switch statusCode {
 	case http.StatusForbidden:
 		type overlay struct {
@@ -1,7 +1,9 @@
 package cbrest
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -52,3 +54,72 @@ func TestShouldRetry(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleResponseErrorServiceErrors(t *testing.T) {
+	type test struct {
+		name     string
+		status   int
+		body     string
+		expected []ServiceErrorDetail
+	}
+
+	tests := []*test{
+		{
+			name:     "InternalServerError",
+			status:   http.StatusInternalServerError,
+			body:     `{"errors":[{"code":24045,"msg":"Cannot find analytics scope with name s1"}],"status":"fatal"}`,
+			expected: []ServiceErrorDetail{{Code: 24045, Message: "Cannot find analytics scope with name s1"}},
+		},
+		{
+			name:   "UnexpectedStatusCode",
+			status: http.StatusConflict,
+			body:   `{"errors":[{"code":24040,"msg":"scope exists"},{"code":1,"msg":"other"}]}`,
+			expected: []ServiceErrorDetail{
+				{Code: 24040, Message: "scope exists"},
+				{Code: 1, Message: "other"},
+			},
+		},
+		{
+			name:   "NotServiceErrors",
+			status: http.StatusInternalServerError,
+			body:   `{"errors":{"_":"not an array"}}`,
+		},
+		{
+			name:   "EmptyErrors",
+			status: http.StatusInternalServerError,
+			body:   `{"errors":[{}]}`,
+		},
+		{
+			name:   "Unauthorized",
+			status: http.StatusUnauthorized,
+			body:   `{"errors":[{"code":20001,"msg":"Unauthorized"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := handleResponseError(http.MethodGet, "/test", test.status, []byte(test.body))
+
+			var serviceErr *ServiceError
+			if test.expected == nil {
+				require.False(t, errors.As(err, &serviceErr))
+				return
+			}
+
+			require.ErrorAs(t, err, &serviceErr)
+			require.Equal(t, test.status, serviceErr.Status())
+			require.Equal(t, test.expected, serviceErr.Details())
+			require.True(t, IsServiceErrorCode(err, test.expected[0].Code))
+			require.False(t, IsServiceErrorCode(err, 42))
+
+			if test.status == http.StatusInternalServerError {
+				var internalErr *InternalServerError
+				require.ErrorAs(t, err, &internalErr)
+			} else {
+				var statusErr *UnexpectedStatusCodeError
+				require.ErrorAs(t, err, &statusErr)
+				require.Equal(t, test.status, statusErr.Status)
+			}
+		})
+	}
+}
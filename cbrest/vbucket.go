@@ -0,0 +1,150 @@
+package cbrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+)
+
+// KeyToVBucket returns the vBucket which owns the given key, using the same CRC32 based hashing as Couchbase Server.
+//
+// NOTE: Zero is returned if there are no vBuckets e.g. for an empty vBucket map.
+func KeyToVBucket(key []byte, numVBuckets uint16) uint16 {
+	if numVBuckets == 0 {
+		return 0
+	}
+
+	return uint16(((crc32.ChecksumIEEE(key) >> 16) & 0x7fff) % uint32(numVBuckets))
+}
+
+// VBucketServerMap represents the vBucket server map for a bucket, which describes which nodes own the active/replica
+// copies of each vBucket.
+type VBucketServerMap struct {
+	HashAlgorithm string `json:"hashAlgorithm"`
+	NumReplicas   int    `json:"numReplicas"`
+
+	// ServerList is the list of data nodes in the form '<host>:<port>', where the port is the memcached port.
+	ServerList []string `json:"serverList"`
+
+	// VBucketMap contains an entry for each vBucket, where the first element is the index of the node (in the server
+	// list) which owns the active vBucket, and the remaining elements are the replicas. An index of -1 indicates that
+	// there is no node for that copy of the vBucket.
+	VBucketMap [][]int `json:"vBucketMap"`
+}
+
+// NumVBuckets returns the number of vBuckets for the bucket.
+func (v *VBucketServerMap) NumVBuckets() uint16 {
+	return uint16(len(v.VBucketMap))
+}
+
+// Active returns the server which owns the active copy of the given vBucket, an empty string is returned if there is no
+// active copy.
+func (v *VBucketServerMap) Active(vbucket uint16) string {
+	if int(vbucket) >= len(v.VBucketMap) || len(v.VBucketMap[vbucket]) == 0 {
+		return ""
+	}
+
+	return v.server(v.VBucketMap[vbucket][0])
+}
+
+// Replicas returns the servers which own the replica copies of the given vBucket, replicas which are not assigned to a
+// node are omitted.
+func (v *VBucketServerMap) Replicas(vbucket uint16) []string {
+	if int(vbucket) >= len(v.VBucketMap) || len(v.VBucketMap[vbucket]) == 0 {
+		return nil
+	}
+
+	replicas := make([]string, 0, len(v.VBucketMap[vbucket])-1)
+
+	for _, idx := range v.VBucketMap[vbucket][1:] {
+		if server := v.server(idx); server != "" {
+			replicas = append(replicas, server)
+		}
+	}
+
+	return replicas
+}
+
+// ActiveForKey returns the server which owns the active copy of the vBucket for the given key, an empty string is
+// returned if there is no active copy (or the vBucket map is empty).
+func (v *VBucketServerMap) ActiveForKey(key []byte) string {
+	return v.Active(KeyToVBucket(key, v.NumVBuckets()))
+}
+
+// VBucketDistribution represents the vBuckets owned by a single node.
+type VBucketDistribution struct {
+	Active  []uint16
+	Replica []uint16
+}
+
+// Distribution returns the vBuckets owned by each server in the server list, this may be used to determine how data is
+// distributed throughout the cluster.
+func (v *VBucketServerMap) Distribution() map[string]*VBucketDistribution {
+	distribution := make(map[string]*VBucketDistribution, len(v.ServerList))
+	for _, server := range v.ServerList {
+		distribution[server] = &VBucketDistribution{}
+	}
+
+	for vbucket, servers := range v.VBucketMap {
+		for position, idx := range servers {
+			server := v.server(idx)
+			if server == "" {
+				continue
+			}
+
+			if position == 0 {
+				distribution[server].Active = append(distribution[server].Active, uint16(vbucket))
+			} else {
+				distribution[server].Replica = append(distribution[server].Replica, uint16(vbucket))
+			}
+		}
+	}
+
+	return distribution
+}
+
+// server returns the server at the given index in the server list, or an empty string if the index is invalid.
+func (v *VBucketServerMap) server(idx int) string {
+	if idx < 0 || idx >= len(v.ServerList) {
+		return ""
+	}
+
+	return v.ServerList[idx]
+}
+
+// GetVBucketMap returns the vBucket server map for the given bucket, a 'BucketNotFoundError' is returned if the bucket
+// does not exist.
+func (c *Client) GetVBucketMap(ctx context.Context, bucket string) (*VBucketServerMap, error) {
+	request := &Request{
+		ContentType:        ContentTypeURLEncoded,
+		Endpoint:           EndpointBucket.Format(bucket),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleBucketError(bucket, response, err)
+	}
+
+	type overlay struct {
+		VBucketServerMap *VBucketServerMap `json:"vBucketServerMap"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// Memcached buckets don't have vBuckets
+	if decoded.VBucketServerMap == nil {
+		return nil, &NoVBucketMapError{name: bucket}
+	}
+
+	return decoded.VBucketServerMap, nil
+}
@@ -0,0 +1,96 @@
+package cbrest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyToVBucket(t *testing.T) {
+	type test struct {
+		key         string
+		numVBuckets uint16
+		expected    uint16
+	}
+
+	tests := []*test{
+		{key: "key", numVBuckets: 1024, expected: 0x0290},
+		{key: "key", numVBuckets: 64, expected: 0x0010},
+		{key: "pymc0", numVBuckets: 1024, expected: 0x01c1},
+		{key: "", numVBuckets: 1024, expected: 0},
+		{key: "key", numVBuckets: 0, expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf(`{"key":"%s","num_vbuckets":%d}`, test.key, test.numVBuckets), func(t *testing.T) {
+			require.Equal(t, test.expected, KeyToVBucket([]byte(test.key), test.numVBuckets))
+		})
+	}
+}
+
+func TestVBucketServerMap(t *testing.T) {
+	vbsm := &VBucketServerMap{
+		ServerList: []string{"a:11210", "b:11210"},
+		VBucketMap: [][]int{{0, 1}, {1, 0}, {1, -1}, {-1, -1}},
+	}
+
+	require.Equal(t, uint16(4), vbsm.NumVBuckets())
+
+	require.Equal(t, "a:11210", vbsm.Active(0))
+	require.Equal(t, []string{"b:11210"}, vbsm.Replicas(0))
+	require.Equal(t, "b:11210", vbsm.Active(2))
+	require.Empty(t, vbsm.Replicas(2))
+	require.Empty(t, vbsm.Active(3))
+	require.Empty(t, vbsm.Active(4))
+	require.Nil(t, vbsm.Replicas(4))
+
+	require.Equal(t, vbsm.Active(KeyToVBucket([]byte("key"), 4)), vbsm.ActiveForKey([]byte("key")))
+	require.Empty(t, (&VBucketServerMap{}).ActiveForKey([]byte("key")))
+
+	expected := map[string]*VBucketDistribution{
+		"a:11210": {Active: []uint16{0}, Replica: []uint16{1}},
+		"b:11210": {Active: []uint16{1, 2}, Replica: []uint16{0}},
+	}
+
+	require.Equal(t, expected, vbsm.Distribution())
+}
+
+func TestGetVBucketMap(t *testing.T) {
+	cluster := NewTestCluster(t, TestClusterOptions{
+		Nodes: TestNodes{{}, {}},
+		Buckets: TestBuckets{
+			"default":   {NumVBuckets: 4, NumReplicas: 2},
+			"memcached": {Type: BucketTypeMemcached},
+		},
+	})
+	defer cluster.Close()
+
+	client, err := newTestClient(cluster, true)
+	require.NoError(t, err)
+
+	vbsm, err := client.GetVBucketMap(context.Background(), "default")
+	require.NoError(t, err)
+
+	servers := []string{
+		fmt.Sprintf("%s:%d", cluster.Address(), 11210),
+		fmt.Sprintf("%s:%d", cluster.Address(), 11211),
+	}
+
+	expected := &VBucketServerMap{
+		HashAlgorithm: "CRC",
+		NumReplicas:   2,
+		ServerList:    servers,
+		VBucketMap:    [][]int{{0, 1, -1}, {1, 0, -1}, {0, 1, -1}, {1, 0, -1}},
+	}
+
+	require.Equal(t, expected, vbsm)
+
+	_, err = client.GetVBucketMap(context.Background(), "memcached")
+
+	require.True(t, IsNoVBucketMap(err))
+
+	_, err = client.GetVBucketMap(context.Background(), "missing")
+	require.True(t, IsBucketNotFound(err))
+}
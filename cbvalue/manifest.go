@@ -0,0 +1,205 @@
+package cbvalue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// DefaultScope is the name of the scope which exists in every bucket, it may not be dropped.
+	DefaultScope = "_default"
+
+	// DefaultCollection is the name of the collection which is created in the default scope when a bucket is created.
+	DefaultCollection = "_default"
+)
+
+// UID represents a manifest, scope or collection unique identifier. These are encoded by 'ns_server' as hex strings.
+type UID uint64
+
+// String returns the hex representation of the UID, the same format used by 'ns_server'.
+func (u UID) String() string {
+	return strconv.FormatUint(uint64(u), 16)
+}
+
+// MarshalJSON implements the 'json.Marshaler' interface, encoding the UID as a hex string.
+func (u UID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, decoding the UID from a hex string.
+func (u *UID) UnmarshalJSON(data []byte) error {
+	var encoded string
+
+	err := json.Unmarshal(data, &encoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	decoded, err := strconv.ParseUint(encoded, 16, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse UID '%s': %w", encoded, err)
+	}
+
+	*u = UID(decoded)
+
+	return nil
+}
+
+// Manifest represents the collections manifest for a bucket, which describes the scopes/collections in the bucket.
+type Manifest struct {
+	UID    UID      `json:"uid"`
+	Scopes []*Scope `json:"scopes"`
+}
+
+// Scope returns the scope with the given name, or <nil> if it does not exist.
+func (m *Manifest) Scope(name string) *Scope {
+	for _, scope := range m.Scopes {
+		if scope.Name == name {
+			return scope
+		}
+	}
+
+	return nil
+}
+
+// Collection returns the collection with the given name in the given scope, or <nil> if it does not exist.
+func (m *Manifest) Collection(scope, name string) *Collection {
+	s := m.Scope(scope)
+	if s == nil {
+		return nil
+	}
+
+	return s.Collection(name)
+}
+
+// Scope represents a scope in a collections manifest.
+type Scope struct {
+	Name        string        `json:"name"`
+	UID         UID           `json:"uid"`
+	Collections []*Collection `json:"collections"`
+}
+
+// Collection returns the collection with the given name, or <nil> if it does not exist.
+func (s *Scope) Collection(name string) *Collection {
+	for _, collection := range s.Collections {
+		if collection.Name == name {
+			return collection
+		}
+	}
+
+	return nil
+}
+
+// Collection represents a collection in a collections manifest.
+type Collection struct {
+	Name string `json:"name"`
+	UID  UID    `json:"uid"`
+
+	// MaxTTL is the maximum time-to-live (in seconds) for documents in the collection, where zero indicates that the
+	// bucket TTL is used and -1 indicates that documents never expire (Couchbase Server 7.6+).
+	MaxTTL int32 `json:"maxTTL,omitempty"`
+}
+
+// ScopedCollection is a collection, paired with the name of the scope it exists in.
+type ScopedCollection struct {
+	Scope      string
+	Collection *Collection
+}
+
+// CollectionChange represents a collection which exists in two manifests, but with different attributes e.g. it's
+// been recreated (so has a new UID) or has a different max TTL.
+type CollectionChange struct {
+	Scope    string
+	Old, New *Collection
+}
+
+// ManifestDiff represents the differences between two manifests, scopes/collections are matched by name.
+//
+// NOTE: When a scope is added/removed, its collections will also be reported as added/removed.
+type ManifestDiff struct {
+	AddedScopes         []*Scope
+	RemovedScopes       []*Scope
+	AddedCollections    []ScopedCollection
+	RemovedCollections  []ScopedCollection
+	ModifiedCollections []CollectionChange
+}
+
+// Empty returns a boolean indicating whether the manifests contained the same scopes/collections.
+func (d ManifestDiff) Empty() bool {
+	return len(d.AddedScopes) == 0 &&
+		len(d.RemovedScopes) == 0 &&
+		len(d.AddedCollections) == 0 &&
+		len(d.RemovedCollections) == 0 &&
+		len(d.ModifiedCollections) == 0
+}
+
+// DiffManifests returns the differences between the before and after manifests, the returned values maintain the
+// ordering of the scopes/collections in the manifests.
+func DiffManifests(before, after *Manifest) ManifestDiff {
+	var diff ManifestDiff
+
+	for _, scope := range after.Scopes {
+		prev := before.Scope(scope.Name)
+		if prev == nil {
+			diff.AddedScopes = append(diff.AddedScopes, scope)
+			prev = &Scope{}
+		}
+
+		for _, collection := range scope.Collections {
+			existing := prev.Collection(collection.Name)
+
+			switch {
+			case existing == nil:
+				diff.AddedCollections = append(diff.AddedCollections, ScopedCollection{
+					Scope:      scope.Name,
+					Collection: collection,
+				})
+			case *existing != *collection:
+				diff.ModifiedCollections = append(diff.ModifiedCollections, CollectionChange{
+					Scope: scope.Name,
+					Old:   existing,
+					New:   collection,
+				})
+			}
+		}
+	}
+
+	for _, scope := range before.Scopes {
+		next := after.Scope(scope.Name)
+		if next == nil {
+			diff.RemovedScopes = append(diff.RemovedScopes, scope)
+			next = &Scope{}
+		}
+
+		for _, collection := range scope.Collections {
+			if next.Collection(collection.Name) == nil {
+				diff.RemovedCollections = append(diff.RemovedCollections, ScopedCollection{
+					Scope:      scope.Name,
+					Collection: collection,
+				})
+			}
+		}
+	}
+
+	return diff
+}
+
+// MapCollectionUIDs returns a mapping from the collection UIDs in the source manifest, to the UIDs of the collections
+// with the same scope/collection names in the target manifest. Collections which don't exist in the target manifest
+// are omitted.
+//
+// NOTE: This is useful when restoring data to a bucket where the collections were created with different UIDs.
+func MapCollectionUIDs(source, target *Manifest) map[UID]UID {
+	mapping := make(map[UID]UID)
+
+	for _, scope := range source.Scopes {
+		for _, collection := range scope.Collections {
+			if mapped := target.Collection(scope.Name, collection.Name); mapped != nil {
+				mapping[collection.UID] = mapped.UID
+			}
+		}
+	}
+
+	return mapping
+}
@@ -0,0 +1,111 @@
+package cbvalue
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestUnmarshalJSON(t *testing.T) {
+	data := []byte(`{
+  "uid": "1a",
+  "scopes": [
+    {"name": "_default", "uid": "0", "collections": [{"name": "_default", "uid": "0"}]},
+    {"name": "scope", "uid": "8", "collections": [{"name": "collection", "uid": "f", "maxTTL": 60}]},
+    {"name": "never", "uid": "9", "collections": [{"name": "collection", "uid": "10", "maxTTL": -1}]}
+  ]
+}`)
+
+	var manifest *Manifest
+
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	expected := &Manifest{
+		UID: 0x1a,
+		Scopes: []*Scope{
+			{Name: "_default", Collections: []*Collection{{Name: "_default"}}},
+			{Name: "scope", UID: 8, Collections: []*Collection{{Name: "collection", UID: 0xf, MaxTTL: 60}}},
+			{Name: "never", UID: 9, Collections: []*Collection{{Name: "collection", UID: 0x10, MaxTTL: -1}}},
+		},
+	}
+
+	require.Equal(t, expected, manifest)
+
+	encoded, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.JSONEq(t, string(data), string(encoded))
+}
+
+func TestUIDUnmarshalJSONInvalid(t *testing.T) {
+	var uid UID
+	require.Error(t, json.Unmarshal([]byte(`"xyz"`), &uid))
+}
+
+func TestManifestCollection(t *testing.T) {
+	manifest := &Manifest{
+		Scopes: []*Scope{{Name: "scope", Collections: []*Collection{{Name: "collection", UID: 8}}}},
+	}
+
+	require.Equal(t, &Collection{Name: "collection", UID: 8}, manifest.Collection("scope", "collection"))
+	require.Nil(t, manifest.Collection("scope", "missing"))
+	require.Nil(t, manifest.Collection("missing", "collection"))
+}
+
+func TestDiffManifests(t *testing.T) {
+	before := &Manifest{
+		UID: 4,
+		Scopes: []*Scope{
+			{Name: "_default", Collections: []*Collection{{Name: "_default"}, {Name: "dropped", UID: 8}}},
+			{Name: "removed", UID: 9, Collections: []*Collection{{Name: "c1", UID: 10}}},
+			{Name: "kept", UID: 11, Collections: []*Collection{{Name: "ttl", UID: 12}, {Name: "same", UID: 13}}},
+		},
+	}
+
+	after := &Manifest{
+		UID: 9,
+		Scopes: []*Scope{
+			{Name: "_default", Collections: []*Collection{{Name: "_default"}}},
+			{Name: "kept", UID: 11, Collections: []*Collection{{Name: "ttl", UID: 12, MaxTTL: 60}, {Name: "same", UID: 13}}},
+			{Name: "added", UID: 14, Collections: []*Collection{{Name: "c1", UID: 15}}},
+		},
+	}
+
+	expected := ManifestDiff{
+		AddedScopes:   []*Scope{after.Scopes[2]},
+		RemovedScopes: []*Scope{before.Scopes[1]},
+		AddedCollections: []ScopedCollection{
+			{Scope: "added", Collection: after.Scopes[2].Collections[0]},
+		},
+		RemovedCollections: []ScopedCollection{
+			{Scope: "_default", Collection: before.Scopes[0].Collections[1]},
+			{Scope: "removed", Collection: before.Scopes[1].Collections[0]},
+		},
+		ModifiedCollections: []CollectionChange{
+			{Scope: "kept", Old: before.Scopes[2].Collections[0], New: after.Scopes[1].Collections[0]},
+		},
+	}
+
+	diff := DiffManifests(before, after)
+	require.Equal(t, expected, diff)
+	require.False(t, diff.Empty())
+	require.True(t, DiffManifests(before, before).Empty())
+}
+
+func TestMapCollectionUIDs(t *testing.T) {
+	source := &Manifest{
+		Scopes: []*Scope{
+			{Name: "_default", Collections: []*Collection{{Name: "_default"}}},
+			{Name: "scope", UID: 8, Collections: []*Collection{{Name: "c1", UID: 9}, {Name: "c2", UID: 10}}},
+		},
+	}
+
+	target := &Manifest{
+		Scopes: []*Scope{
+			{Name: "_default", Collections: []*Collection{{Name: "_default"}}},
+			{Name: "scope", UID: 12, Collections: []*Collection{{Name: "c1", UID: 20}}},
+		},
+	}
+
+	require.Equal(t, map[UID]UID{0: 0, 9: 20}, MapCollectionUIDs(source, target))
+}
@@ -8,6 +8,12 @@ import (
 	"github.com/couchbase/tools-common/parse"
 )
 
+// GetString returns the value of the environmental variable varName, if the env var is not set it will return "",
+// false.
+func GetString(varName string) (string, bool) {
+	return os.LookupEnv(varName)
+}
+
 // GetInt returns the int value of the environmental variable varName  if the env var is not an int or empty it will
 // return 0, false.
 func GetInt(varName string) (int, bool) {
@@ -8,6 +8,17 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestGetString(t *testing.T) {
+	_, ok := GetString("CB_TEST_ENVAR_STRING_NO_ENV_CASE")
+	require.False(t, ok)
+
+	t.Setenv("CB_TEST_ENVAR_STRING_VALID_CASE", "value")
+
+	val, ok := GetString("CB_TEST_ENVAR_STRING_VALID_CASE")
+	require.True(t, ok)
+	require.Equal(t, "value", val)
+}
+
 func TestGetInt(t *testing.T) {
 	type test struct {
 		name         string
@@ -0,0 +1,292 @@
+package eventingutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+const (
+	// EndpointExport is used to export all the Eventing function definitions.
+	EndpointExport cbrest.Endpoint = "/api/v1/export"
+
+	// EndpointImport is used to import Eventing function definitions.
+	EndpointImport cbrest.Endpoint = "/api/v1/import"
+
+	// EndpointStatus is used to retrieve the status of all the Eventing functions.
+	EndpointStatus cbrest.Endpoint = "/api/v1/status"
+
+	// EndpointDeploy is used to deploy an Eventing function.
+	EndpointDeploy cbrest.Endpoint = "/api/v1/functions/%s/deploy"
+
+	// EndpointUndeploy is used to undeploy an Eventing function.
+	EndpointUndeploy cbrest.Endpoint = "/api/v1/functions/%s/undeploy"
+
+	// EndpointPause is used to pause an Eventing function.
+	EndpointPause cbrest.Endpoint = "/api/v1/functions/%s/pause"
+
+	// EndpointResume is used to resume a paused Eventing function.
+	EndpointResume cbrest.Endpoint = "/api/v1/functions/%s/resume"
+)
+
+// Status represents the composite status of an Eventing function, across all the Eventing nodes.
+type Status string
+
+const (
+	// StatusUndeployed indicates that the function is not deployed.
+	StatusUndeployed Status = "undeployed"
+
+	// StatusDeploying indicates that the function is being deployed.
+	StatusDeploying Status = "deploying"
+
+	// StatusDeployed indicates that the function is deployed, and processing mutations.
+	StatusDeployed Status = "deployed"
+
+	// StatusUndeploying indicates that the function is being undeployed.
+	StatusUndeploying Status = "undeploying"
+
+	// StatusPausing indicates that the function is being paused.
+	StatusPausing Status = "pausing"
+
+	// StatusPaused indicates that the function is paused, it may be resumed.
+	StatusPaused Status = "paused"
+)
+
+// FunctionStatus represents the status of an Eventing function.
+type FunctionStatus struct {
+	Name                  string         `json:"name"`
+	Status                Status         `json:"composite_status"`
+	NumBootstrappingNodes int            `json:"num_bootstrapping_nodes"`
+	NumDeployedNodes      int            `json:"num_deployed_nodes"`
+	FunctionScope         *FunctionScope `json:"function_scope"`
+}
+
+// Client is a wrapper around the 'cbrest' client which implements methods to manage Eventing functions.
+type Client struct {
+	*cbrest.Client
+}
+
+// NewClient creates a new client which will dispatch requests using the given 'cbrest' client.
+func NewClient(client *cbrest.Client) *Client {
+	return &Client{client}
+}
+
+// ExportFunctions returns all the Eventing function definitions, the returned definitions should be passed through
+// 'Function.Export' before being imported into another cluster.
+func (c *Client) ExportFunctions(ctx context.Context) ([]*Function, error) {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointExport,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            cbrest.ServiceEventing,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleResponseError("", response, err)
+	}
+
+	var functions []*Function
+
+	err = json.Unmarshal(response.Body, &functions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return functions, nil
+}
+
+// ImportFunctions imports the given Eventing functions, after exporting and renaming their keyspaces using the given
+// remapping. Functions are imported in the undeployed state, and must be deployed using 'DeployFunction'.
+func (c *Client) ImportFunctions(ctx context.Context, functions []*Function, remapping Remapping) error {
+	remapped := make([]*Function, 0, len(functions))
+	for _, function := range functions {
+		remapped = append(remapped, function.Export().Remap(remapping))
+	}
+
+	body, err := json.Marshal(remapped)
+	if err != nil {
+		return fmt.Errorf("failed to marshal functions: %w", err)
+	}
+
+	request := &cbrest.Request{
+		Body:               body,
+		ContentType:        cbrest.ContentTypeJSON,
+		Endpoint:           EndpointImport,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            cbrest.ServiceEventing,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleResponseError("", response, err)
+	}
+
+	return nil
+}
+
+// DeployFunction deploys the given Eventing function, use 'WaitForStatus' to wait for the deployment to complete.
+//
+// NOTE: The scope should be provided for functions created in 7.1.0 and above, which aren't in the global scope.
+func (c *Client) DeployFunction(ctx context.Context, name string, scope *FunctionScope) error {
+	return c.lifecycle(ctx, EndpointDeploy, name, scope)
+}
+
+// UndeployFunction undeploys the given Eventing function.
+func (c *Client) UndeployFunction(ctx context.Context, name string, scope *FunctionScope) error {
+	return c.lifecycle(ctx, EndpointUndeploy, name, scope)
+}
+
+// PauseFunction pauses the given deployed Eventing function, it may be resumed using 'ResumeFunction'.
+func (c *Client) PauseFunction(ctx context.Context, name string, scope *FunctionScope) error {
+	return c.lifecycle(ctx, EndpointPause, name, scope)
+}
+
+// ResumeFunction resumes the given paused Eventing function.
+func (c *Client) ResumeFunction(ctx context.Context, name string, scope *FunctionScope) error {
+	return c.lifecycle(ctx, EndpointResume, name, scope)
+}
+
+// GetStatus returns the status of all the Eventing functions.
+func (c *Client) GetStatus(ctx context.Context) ([]*FunctionStatus, error) {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointStatus,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            cbrest.ServiceEventing,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleResponseError("", response, err)
+	}
+
+	type overlay struct {
+		Apps []*FunctionStatus `json:"apps"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return decoded.Apps, nil
+}
+
+// WaitForStatus blocks until the given Eventing function reaches the expected status e.g. 'StatusDeployed' after
+// deploying a function. A 'FunctionNotSettledError' is returned if this doesn't happen within the poll timeout.
+func (c *Client) WaitForStatus(ctx context.Context, name string, scope *FunctionScope, expected Status) error {
+	ctx, cancelFunc := context.WithTimeout(ctx, c.PollTimeout())
+	defer cancelFunc()
+
+	var current Status
+
+	timeout, err := c.PollRequestsWithContext(ctx, func(attempt int) (bool, error) {
+		statuses, err := c.GetStatus(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to get status: %w", err)
+		}
+
+		status := findStatus(statuses, name, scope)
+		if status == nil {
+			return false, &FunctionNotFoundError{name: name}
+		}
+
+		current = status.Status
+
+		return current == expected, nil
+	})
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	if timeout {
+		return &FunctionNotSettledError{name: name, status: current, expected: expected}
+	}
+
+	return nil
+}
+
+// findStatus returns the status for the given function, or <nil> if it doesn't exist.
+func findStatus(statuses []*FunctionStatus, name string, scope *FunctionScope) *FunctionStatus {
+	for _, status := range statuses {
+		if status.Name != name {
+			continue
+		}
+
+		if scope == nil || status.FunctionScope == nil || *scope == *status.FunctionScope {
+			return status
+		}
+	}
+
+	return nil
+}
+
+// lifecycle executes the given lifecycle operation for an Eventing function.
+func (c *Client) lifecycle(ctx context.Context, endpoint cbrest.Endpoint, name string, scope *FunctionScope) error {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeJSON,
+		Endpoint:           endpoint.Format(name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            cbrest.ServiceEventing,
+	}
+
+	if scope != nil {
+		request.QueryParameters = url.Values{"bucket": {scope.Bucket}, "scope": {scope.Scope}}
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleResponseError(name, response, err)
+	}
+
+	return nil
+}
+
+// handleResponseError converts the error returned by an Eventing Service request into a typed error where possible,
+// using the response body returned by the Eventing Service.
+func handleResponseError(name string, response *cbrest.Response, err error) error {
+	if response == nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	type overlay struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		RuntimeInfo struct {
+			Info any `json:"info"`
+		} `json:"runtime_info"`
+	}
+
+	var decoded overlay
+
+	// Purposefully ignored, we'll fallback to returning the status code error if the body isn't valid
+	_ = json.Unmarshal(response.Body, &decoded)
+
+	if decoded.Name == "" {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if decoded.Name == errNameFunctionNotFound {
+		return &FunctionNotFoundError{name: name}
+	}
+
+	serviceErr := &ServiceError{name: decoded.Name, description: decoded.Description, err: err}
+
+	// The runtime info may be a string, or an object depending on the error
+	if info, ok := decoded.RuntimeInfo.Info.(string); ok {
+		serviceErr.info = info
+	}
+
+	return serviceErr
+}
@@ -0,0 +1,195 @@
+package eventingutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+func newTestClient(t *testing.T, handlers cbrest.TestHandlers) *Client {
+	nodes := cbrest.TestNodes{{Services: []cbrest.Service{cbrest.ServiceEventing}}}
+
+	return NewClient(cbrest.NewTestClusterClient(t, nodes, handlers))
+}
+
+func TestClientExportFunctions(t *testing.T) {
+	handlers := make(cbrest.TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointExport),
+		cbrest.NewTestHandler(t, http.StatusOK, []byte("["+testFunction+"]")))
+
+	functions, err := newTestClient(t, handlers).ExportFunctions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, functions, 1)
+	require.Equal(t, "fn", functions[0].Name)
+}
+
+func TestClientImportFunctions(t *testing.T) {
+	var function *Function
+
+	require.NoError(t, json.Unmarshal([]byte(testFunction), &function))
+
+	var actual []map[string]any
+
+	handlers := make(cbrest.TestHandlers)
+	handlers.Add(http.MethodPost, string(EndpointImport),
+		cbrest.NewTestHandlerWithValue(t, http.StatusOK, nil, &actual))
+
+	err := newTestClient(t, handlers).ImportFunctions(context.Background(), []*Function{function}, Remapping{
+		Buckets: map[string]string{"src": "dst"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, actual, 1)
+	require.NotContains(t, actual[0], "handleruuid")
+	require.Equal(t, "dst", actual[0]["depcfg"].(map[string]any)["source_bucket"])
+	require.Equal(t, false, actual[0]["settings"].(map[string]any)["deployment_status"])
+}
+
+func TestClientLifecycle(t *testing.T) {
+	type test struct {
+		name     string
+		endpoint cbrest.Endpoint
+		fn       func(client *Client, scope *FunctionScope) error
+	}
+
+	tests := []*test{
+		{
+			name:     "Deploy",
+			endpoint: EndpointDeploy,
+			fn: func(client *Client, scope *FunctionScope) error {
+				return client.DeployFunction(context.Background(), "fn", scope)
+			},
+		},
+		{
+			name:     "Undeploy",
+			endpoint: EndpointUndeploy,
+			fn: func(client *Client, scope *FunctionScope) error {
+				return client.UndeployFunction(context.Background(), "fn", scope)
+			},
+		},
+		{
+			name:     "Pause",
+			endpoint: EndpointPause,
+			fn: func(client *Client, scope *FunctionScope) error {
+				return client.PauseFunction(context.Background(), "fn", scope)
+			},
+		},
+		{
+			name:     "Resume",
+			endpoint: EndpointResume,
+			fn: func(client *Client, scope *FunctionScope) error {
+				return client.ResumeFunction(context.Background(), "fn", scope)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var query url.Values
+
+			handlers := make(cbrest.TestHandlers)
+			handlers.Add(http.MethodPost, string(test.endpoint.Format("fn")),
+				func(writer http.ResponseWriter, request *http.Request) {
+					query = request.URL.Query()
+				})
+
+			client := newTestClient(t, handlers)
+
+			require.NoError(t, test.fn(client, nil))
+			require.Empty(t, query)
+
+			require.NoError(t, test.fn(client, &FunctionScope{Bucket: "src", Scope: "s1"}))
+			require.Equal(t, url.Values{"bucket": {"src"}, "scope": {"s1"}}, query)
+		})
+	}
+}
+
+func TestClientLifecycleErrors(t *testing.T) {
+	type test struct {
+		name  string
+		body  string
+		check func(t *testing.T, err error)
+	}
+
+	tests := []*test{
+		{
+			name: "NotFound",
+			body: `{"name":"ERR_APP_NOT_FOUND_TS","code":19,"description":"Function not found",` +
+				`"runtime_info":{"code":19,"info":"Function: fn not found"}}`,
+			check: func(t *testing.T, err error) { require.True(t, IsFunctionNotFound(err)) },
+		},
+		{
+			name: "ServiceError",
+			body: `{"name":"ERR_APP_NOT_DEPLOYED","code":20,"description":"Function not deployed",` +
+				`"runtime_info":{"code":20,"info":"Function: fn not deployed"}}`,
+			check: func(t *testing.T, err error) {
+				var serviceErr *ServiceError
+				require.ErrorAs(t, err, &serviceErr)
+				require.Equal(t, "ERR_APP_NOT_DEPLOYED", serviceErr.Name())
+				require.Contains(t, serviceErr.Error(), "Function: fn not deployed")
+			},
+		},
+		{
+			name: "NotJSON",
+			body: "failed",
+			check: func(t *testing.T, err error) {
+				var statusErr *cbrest.UnexpectedStatusCodeError
+				require.ErrorAs(t, err, &statusErr)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handlers := make(cbrest.TestHandlers)
+			handlers.Add(http.MethodPost, string(EndpointPause.Format("fn")),
+				cbrest.NewTestHandler(t, http.StatusBadRequest, []byte(test.body)))
+
+			test.check(t, newTestClient(t, handlers).PauseFunction(context.Background(), "fn", nil))
+		})
+	}
+}
+
+func TestClientWaitForStatus(t *testing.T) {
+	var requests int64
+
+	handlers := make(cbrest.TestHandlers)
+	handlers.Add(http.MethodGet, string(EndpointStatus), func(writer http.ResponseWriter, _ *http.Request) {
+		status := StatusDeploying
+		if atomic.AddInt64(&requests, 1) > 1 {
+			status = StatusDeployed
+		}
+
+		cbrest.WriteTestJSON(t, writer, http.StatusOK, map[string]any{
+			"apps": []*FunctionStatus{
+				{Name: "fn", Status: StatusUndeployed, FunctionScope: &FunctionScope{Bucket: "other", Scope: "s1"}},
+				{Name: "fn", Status: status, FunctionScope: &FunctionScope{Bucket: "src", Scope: "s1"}},
+			},
+			"num_eventing_nodes": 1,
+		})
+	})
+
+	client := newTestClient(t, handlers)
+
+	scope := &FunctionScope{Bucket: "src", Scope: "s1"}
+
+	require.NoError(t, client.WaitForStatus(context.Background(), "fn", scope, StatusDeployed))
+	require.Equal(t, int64(2), atomic.LoadInt64(&requests))
+
+	require.True(t, IsFunctionNotFound(client.WaitForStatus(context.Background(), "missing", nil, StatusDeployed)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	var notSettledErr *FunctionNotSettledError
+	require.ErrorAs(t, client.WaitForStatus(ctx, "fn", scope, StatusPaused), &notSettledErr)
+	require.Equal(t, StatusDeployed, notSettledErr.status)
+}
@@ -0,0 +1,64 @@
+package eventingutil
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errNameFunctionNotFound is the error name returned by the Eventing Service when a function doesn't exist.
+const errNameFunctionNotFound = "ERR_APP_NOT_FOUND_TS"
+
+// FunctionNotFoundError is returned if the user attempts to interact with an Eventing function which doesn't exist.
+type FunctionNotFoundError struct {
+	name string
+}
+
+func (e *FunctionNotFoundError) Error() string {
+	return fmt.Sprintf("eventing function '%s' not found", e.name)
+}
+
+// IsFunctionNotFound returns a boolean indicating whether the given error is a 'FunctionNotFoundError'.
+func IsFunctionNotFound(err error) bool {
+	var notFound *FunctionNotFoundError
+	return errors.As(err, &notFound)
+}
+
+// ServiceError is returned if the Eventing Service rejects a request, for example when attempting to pause a function
+// which is not deployed.
+type ServiceError struct {
+	name        string
+	description string
+	info        string
+	err         error
+}
+
+func (e *ServiceError) Error() string {
+	msg := fmt.Sprintf("eventing service returned error '%s': %s", e.name, e.description)
+	if e.info != "" {
+		msg += ": " + e.info
+	}
+
+	return msg
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.err
+}
+
+// Name returns the error name returned by the Eventing Service e.g. 'ERR_APP_NOT_DEPLOYED'.
+func (e *ServiceError) Name() string {
+	return e.name
+}
+
+// FunctionNotSettledError is returned if the poll timeout is reached whilst waiting for a function to reach the
+// expected status.
+type FunctionNotSettledError struct {
+	name     string
+	status   Status
+	expected Status
+}
+
+func (e *FunctionNotSettledError) Error() string {
+	return fmt.Sprintf("timed out waiting for eventing function '%s' to be '%s', the current status is '%s'", e.name,
+		e.expected, e.status)
+}
@@ -0,0 +1,273 @@
+package eventingutil
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Function represents an Eventing function definition, as exported by the Eventing Service.
+//
+// NOTE: Attributes which aren't modelled are preserved, so that definitions may be exported/imported without losing
+// information.
+type Function struct {
+	Name             string            `json:"appname"`
+	Code             string            `json:"appcode"`
+	DeploymentConfig *DeploymentConfig `json:"depcfg"`
+	Settings         map[string]any    `json:"settings"`
+
+	// FunctionScope is the scope the function belongs to, this is <nil> for functions created prior to 7.1.0.
+	FunctionScope *FunctionScope `json:"function_scope,omitempty"`
+
+	extra map[string]json.RawMessage
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface.
+func (f *Function) UnmarshalJSON(data []byte) error {
+	type alias Function
+
+	extra, err := unmarshalWithExtra(data, (*alias)(f))
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	f.extra = extra
+
+	return nil
+}
+
+// MarshalJSON implements the 'json.Marshaler' interface.
+func (f *Function) MarshalJSON() ([]byte, error) {
+	type alias Function
+	return marshalWithExtra((*alias)(f), f.extra)
+}
+
+// Export returns a copy of the function, with the cluster specific attributes removed and the deployment/processing
+// status reset, so that it will be imported in the undeployed state.
+func (f *Function) Export() *Function {
+	exported := *f
+
+	exported.extra = make(map[string]json.RawMessage, len(f.extra))
+
+	for key, value := range f.extra {
+		if key == "handleruuid" || key == "function_instance_id" {
+			continue
+		}
+
+		exported.extra[key] = value
+	}
+
+	exported.Settings = make(map[string]any, len(f.Settings))
+
+	for key, value := range f.Settings {
+		exported.Settings[key] = value
+	}
+
+	if _, ok := exported.Settings["deployment_status"]; ok {
+		exported.Settings["deployment_status"] = false
+	}
+
+	if _, ok := exported.Settings["processing_status"]; ok {
+		exported.Settings["processing_status"] = false
+	}
+
+	if f.DeploymentConfig != nil {
+		exported.DeploymentConfig = f.DeploymentConfig.clone()
+	}
+
+	return &exported
+}
+
+// Remap returns a copy of the function, with the keyspaces it references (source, metadata, bindings and function
+// scope) renamed using the given remapping.
+func (f *Function) Remap(remapping Remapping) *Function {
+	remapped := *f
+
+	if f.DeploymentConfig != nil {
+		remapped.DeploymentConfig = f.DeploymentConfig.clone()
+		remapped.DeploymentConfig.remap(remapping)
+	}
+
+	// The function scope may be a wildcard for functions which have been upgraded from a previous version
+	if f.FunctionScope != nil && f.FunctionScope.Bucket != "*" {
+		mapped := remapping.remap(Keyspace{Bucket: f.FunctionScope.Bucket, Scope: f.FunctionScope.Scope})
+		remapped.FunctionScope = &FunctionScope{Bucket: mapped.Bucket, Scope: mapped.Scope}
+	}
+
+	return &remapped
+}
+
+// FunctionScope represents the bucket/scope which an Eventing function belongs to, used for access control.
+type FunctionScope struct {
+	Bucket string `json:"bucket"`
+	Scope  string `json:"scope"`
+}
+
+// DeploymentConfig represents the keyspaces used by an Eventing function.
+type DeploymentConfig struct {
+	SourceBucket       string `json:"source_bucket"`
+	SourceScope        string `json:"source_scope"`
+	SourceCollection   string `json:"source_collection"`
+	MetadataBucket     string `json:"metadata_bucket"`
+	MetadataScope      string `json:"metadata_scope"`
+	MetadataCollection string `json:"metadata_collection"`
+
+	// Buckets are the keyspace bindings which may be accessed by the function using an alias.
+	Buckets []*BucketBinding `json:"buckets"`
+
+	extra map[string]json.RawMessage
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface.
+func (d *DeploymentConfig) UnmarshalJSON(data []byte) error {
+	type alias DeploymentConfig
+
+	extra, err := unmarshalWithExtra(data, (*alias)(d))
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	d.extra = extra
+
+	return nil
+}
+
+// MarshalJSON implements the 'json.Marshaler' interface.
+func (d *DeploymentConfig) MarshalJSON() ([]byte, error) {
+	type alias DeploymentConfig
+	return marshalWithExtra((*alias)(d), d.extra)
+}
+
+// clone returns a deep copy of the deployment config.
+func (d *DeploymentConfig) clone() *DeploymentConfig {
+	cloned := *d
+
+	cloned.Buckets = make([]*BucketBinding, 0, len(d.Buckets))
+
+	for _, binding := range d.Buckets {
+		b := *binding
+		cloned.Buckets = append(cloned.Buckets, &b)
+	}
+
+	return &cloned
+}
+
+// remap renames the keyspaces in the deployment config using the given remapping.
+func (d *DeploymentConfig) remap(remapping Remapping) {
+	source := remapping.remap(Keyspace{Bucket: d.SourceBucket, Scope: d.SourceScope, Collection: d.SourceCollection})
+	d.SourceBucket, d.SourceScope, d.SourceCollection = source.Bucket, source.Scope, source.Collection
+
+	metadata := remapping.remap(Keyspace{
+		Bucket:     d.MetadataBucket,
+		Scope:      d.MetadataScope,
+		Collection: d.MetadataCollection,
+	})
+	d.MetadataBucket, d.MetadataScope, d.MetadataCollection = metadata.Bucket, metadata.Scope, metadata.Collection
+
+	for _, binding := range d.Buckets {
+		mapped := remapping.remap(Keyspace{
+			Bucket:     binding.BucketName,
+			Scope:      binding.ScopeName,
+			Collection: binding.CollectionName,
+		})
+		binding.BucketName, binding.ScopeName, binding.CollectionName = mapped.Bucket, mapped.Scope, mapped.Collection
+	}
+}
+
+// BucketBinding represents a keyspace which is accessible by an Eventing function using the given alias.
+type BucketBinding struct {
+	Alias          string `json:"alias"`
+	BucketName     string `json:"bucket_name"`
+	ScopeName      string `json:"scope_name,omitempty"`
+	CollectionName string `json:"collection_name,omitempty"`
+	Access         string `json:"access"`
+}
+
+// Keyspace represents a bucket/scope/collection referenced by an Eventing function.
+type Keyspace struct {
+	Bucket     string
+	Scope      string
+	Collection string
+}
+
+// Remapping describes how the keyspaces referenced by an Eventing function should be renamed, for example when moving
+// functions between clusters.
+type Remapping struct {
+	// Buckets maps bucket names to the name of the bucket they should be renamed to, the scope/collection are unchanged.
+	Buckets map[string]string
+
+	// Keyspaces maps keyspaces to the keyspace they should be renamed to, this takes precedence over the bucket
+	// mappings.
+	Keyspaces map[Keyspace]Keyspace
+}
+
+// remap returns the renamed keyspace.
+func (r Remapping) remap(keyspace Keyspace) Keyspace {
+	if mapped, ok := r.Keyspaces[keyspace]; ok {
+		return mapped
+	}
+
+	if mapped, ok := r.Buckets[keyspace.Bucket]; ok {
+		keyspace.Bucket = mapped
+	}
+
+	return keyspace
+}
+
+// unmarshalWithExtra unmarshals the given data into the given value, returning any attributes which were not decoded.
+func unmarshalWithExtra(data []byte, value any) (map[string]json.RawMessage, error) {
+	err := json.Unmarshal(data, value)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	var all map[string]json.RawMessage
+
+	err = json.Unmarshal(data, &all)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	known, err := marshalToMap(value)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	for key := range known {
+		delete(all, key)
+	}
+
+	return all, nil
+}
+
+// marshalWithExtra marshals the given value, including the given extra attributes.
+func marshalWithExtra(value any, extra map[string]json.RawMessage) ([]byte, error) {
+	encoded, err := marshalToMap(value)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	for key, value := range extra {
+		if _, ok := encoded[key]; !ok {
+			encoded[key] = value
+		}
+	}
+
+	return json.Marshal(encoded)
+}
+
+// marshalToMap marshals the given value, returning its top level attributes.
+func marshalToMap(value any) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	var decoded map[string]json.RawMessage
+
+	err = json.Unmarshal(encoded, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,103 @@
+package eventingutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testFunction = `{
+  "appname": "fn",
+  "appcode": "function OnUpdate(doc, meta) {}",
+  "depcfg": {
+    "source_bucket": "src",
+    "source_scope": "s1",
+    "source_collection": "c1",
+    "metadata_bucket": "meta",
+    "metadata_scope": "_default",
+    "metadata_collection": "_default",
+    "buckets": [{"alias": "dst", "bucket_name": "src", "scope_name": "s1", "collection_name": "c2", "access": "rw"}],
+    "curl": []
+  },
+  "settings": {"deployment_status": true, "processing_status": true, "worker_count": 1},
+  "function_scope": {"bucket": "src", "scope": "s1"},
+  "version": "evt-7.1.0",
+  "handleruuid": 1234,
+  "function_instance_id": "abc"
+}`
+
+func TestFunctionMarshalRoundTrip(t *testing.T) {
+	var function *Function
+
+	require.NoError(t, json.Unmarshal([]byte(testFunction), &function))
+	require.Equal(t, "fn", function.Name)
+	require.Equal(t, "src", function.DeploymentConfig.SourceBucket)
+	require.Equal(t, &FunctionScope{Bucket: "src", Scope: "s1"}, function.FunctionScope)
+
+	encoded, err := json.Marshal(function)
+	require.NoError(t, err)
+	require.JSONEq(t, testFunction, string(encoded))
+}
+
+func TestFunctionExport(t *testing.T) {
+	var function *Function
+
+	require.NoError(t, json.Unmarshal([]byte(testFunction), &function))
+
+	encoded, err := json.Marshal(function.Export())
+	require.NoError(t, err)
+
+	var decoded map[string]any
+
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.NotContains(t, decoded, "handleruuid")
+	require.NotContains(t, decoded, "function_instance_id")
+	require.Equal(t, "evt-7.1.0", decoded["version"])
+	require.Equal(t, map[string]any{"deployment_status": false, "processing_status": false, "worker_count": 1.0},
+		decoded["settings"])
+
+	// The original function should not be modified
+	require.Equal(t, true, function.Settings["deployment_status"])
+}
+
+func TestFunctionRemap(t *testing.T) {
+	var function *Function
+
+	require.NoError(t, json.Unmarshal([]byte(testFunction), &function))
+
+	remapped := function.Remap(Remapping{
+		Buckets: map[string]string{"src": "dst", "meta": "meta2"},
+		Keyspaces: map[Keyspace]Keyspace{
+			{Bucket: "src", Scope: "s1", Collection: "c2"}: {Bucket: "other", Scope: "s2", Collection: "c3"},
+		},
+	})
+
+	expected := &DeploymentConfig{
+		SourceBucket:       "dst",
+		SourceScope:        "s1",
+		SourceCollection:   "c1",
+		MetadataBucket:     "meta2",
+		MetadataScope:      "_default",
+		MetadataCollection: "_default",
+		Buckets: []*BucketBinding{
+			{Alias: "dst", BucketName: "other", ScopeName: "s2", CollectionName: "c3", Access: "rw"},
+		},
+		extra: function.DeploymentConfig.extra,
+	}
+
+	require.Equal(t, expected, remapped.DeploymentConfig)
+	require.Equal(t, &FunctionScope{Bucket: "dst", Scope: "s1"}, remapped.FunctionScope)
+
+	// The original function should not be modified
+	require.Equal(t, "src", function.DeploymentConfig.SourceBucket)
+	require.Equal(t, "src", function.DeploymentConfig.Buckets[0].BucketName)
+	require.Equal(t, "src", function.FunctionScope.Bucket)
+}
+
+func TestFunctionRemapWildcardScope(t *testing.T) {
+	function := &Function{FunctionScope: &FunctionScope{Bucket: "*", Scope: "*"}}
+
+	remapped := function.Remap(Remapping{Buckets: map[string]string{"*": "dst"}})
+	require.Equal(t, &FunctionScope{Bucket: "*", Scope: "*"}, remapped.FunctionScope)
+}
@@ -0,0 +1,245 @@
+package ftsutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+const (
+	// EndpointIndexes is used to list all the search index definitions.
+	EndpointIndexes cbrest.Endpoint = "/api/index"
+
+	// EndpointIndex is used to get/create/update/delete a single search index definition.
+	EndpointIndex cbrest.Endpoint = "/api/index/%s"
+)
+
+// Client is a wrapper around the 'cbrest' client which implements methods to manage Full Text Search index definitions.
+type Client struct {
+	*cbrest.Client
+}
+
+// NewClient creates a new client which will dispatch requests using the given 'cbrest' client.
+func NewClient(client *cbrest.Client) *Client {
+	return &Client{client}
+}
+
+// ListIndexes returns all the search index definitions, sorted by name.
+func (c *Client) ListIndexes(ctx context.Context) ([]*IndexDefinition, error) {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointIndexes,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            cbrest.ServiceSearch,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	type overlay struct {
+		IndexDefs *struct {
+			IndexDefs map[string]*IndexDefinition `json:"indexDefs"`
+		} `json:"indexDefs"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// There will be no index definitions if a search index has never been created
+	if decoded.IndexDefs == nil {
+		return make([]*IndexDefinition, 0), nil
+	}
+
+	names := maps.Keys(decoded.IndexDefs.IndexDefs)
+	slices.Sort(names)
+
+	indexes := make([]*IndexDefinition, 0, len(names))
+	for _, name := range names {
+		indexes = append(indexes, decoded.IndexDefs.IndexDefs[name])
+	}
+
+	return indexes, nil
+}
+
+// GetIndex returns the search index definition with the given name, an 'IndexNotFoundError' is returned if the index
+// does not exist.
+func (c *Client) GetIndex(ctx context.Context, name string) (*IndexDefinition, error) {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointIndex.Format(name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            cbrest.ServiceSearch,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleIndexError(name, "", true, response, err)
+	}
+
+	type overlay struct {
+		IndexDef *IndexDefinition `json:"indexDef"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if decoded.IndexDef == nil {
+		return nil, &IndexNotFoundError{name: name}
+	}
+
+	return decoded.IndexDef, nil
+}
+
+// CreateIndex creates a new search index using the given definition, an 'IndexExistsError' is returned if an index
+// with the same name already exists.
+func (c *Client) CreateIndex(ctx context.Context, definition *IndexDefinition) error {
+	if definition.UUID != "" {
+		return ErrUUIDProvided
+	}
+
+	return c.putIndex(ctx, definition)
+}
+
+// UpdateIndex replaces an existing search index definition, the definition must have the UUID of the definition being
+// replaced (e.g. as returned by 'GetIndex'). A 'UUIDMismatchError' is returned if the index has been modified since it
+// was retrieved.
+func (c *Client) UpdateIndex(ctx context.Context, definition *IndexDefinition) error {
+	if definition.UUID == "" {
+		return ErrUUIDRequired
+	}
+
+	return c.putIndex(ctx, definition)
+}
+
+// DeleteIndex deletes the search index with the given name, an 'IndexNotFoundError' is returned if the index does not
+// exist.
+func (c *Client) DeleteIndex(ctx context.Context, name string) error {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointIndex.Format(name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodDelete,
+		Service:            cbrest.ServiceSearch,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleIndexError(name, "", true, response, err)
+	}
+
+	return nil
+}
+
+// ExportIndexes returns all the search index definitions in a form which may be imported using 'ImportIndexes'.
+func (c *Client) ExportIndexes(ctx context.Context) ([]*IndexDefinition, error) {
+	indexes, err := c.ListIndexes(ctx)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	exported := make([]*IndexDefinition, 0, len(indexes))
+	for _, index := range indexes {
+		exported = append(exported, index.Export())
+	}
+
+	return exported, nil
+}
+
+// ImportIndexes creates search indexes using the given exported definitions, remapping their sources using the given
+// remapping. An 'ImportError' is returned for the first index which fails to be imported.
+func (c *Client) ImportIndexes(ctx context.Context, definitions []*IndexDefinition, remapping Remapping) error {
+	for _, definition := range definitions {
+		remapped, err := definition.Export().Remap(remapping)
+		if err != nil {
+			return &ImportError{name: definition.Name, err: err}
+		}
+
+		err = c.CreateIndex(ctx, remapped)
+		if err != nil {
+			return &ImportError{name: definition.Name, err: err}
+		}
+	}
+
+	return nil
+}
+
+// putIndex creates/updates the given search index definition.
+func (c *Client) putIndex(ctx context.Context, definition *IndexDefinition) error {
+	body, err := json.Marshal(definition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index definition: %w", err)
+	}
+
+	request := &cbrest.Request{
+		Body:               body,
+		ContentType:        cbrest.ContentTypeJSON,
+		Endpoint:           EndpointIndex.Format(definition.Name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPut,
+		Service:            cbrest.ServiceSearch,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		// Updates (but not creations) target an existing index
+		return handleIndexError(definition.Name, definition.UUID, definition.UUID != "", response, err)
+	}
+
+	return nil
+}
+
+// handleIndexError converts the error returned by a search index management request into a typed error where possible,
+// using the response body returned by the Search Service. The existing argument indicates whether the request targeted
+// an existing index, only then is an 'IndexNotFoundError' returned; other errors (e.g. a missing source bucket) may
+// also be reported as "not found".
+func handleIndexError(name, uuid string, existing bool, response *cbrest.Response, err error) error {
+	if response == nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	type overlay struct {
+		Error string `json:"error"`
+	}
+
+	var decoded overlay
+
+	// Purposefully ignored, we'll fallback to returning the status code error if the body isn't valid
+	_ = json.Unmarshal(response.Body, &decoded)
+
+	var (
+		msg      = strings.ToLower(decoded.Error)
+		notFound = existing &&
+			(response.StatusCode == http.StatusBadRequest || response.StatusCode == http.StatusNotFound) &&
+			(strings.Contains(msg, "index not found") || strings.Contains(msg, "index to update does not exist"))
+	)
+
+	switch {
+	case notFound:
+		return &IndexNotFoundError{name: name}
+	case strings.Contains(msg, "index with the same name already exists"):
+		return &IndexExistsError{name: name}
+	case strings.Contains(msg, "did not match input uuid"):
+		return &UUIDMismatchError{name: name, uuid: uuid}
+	}
+
+	return fmt.Errorf("failed to execute request: %w", err)
+}
@@ -0,0 +1,289 @@
+package ftsutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+// testSearch is a minimal simulation of the Search Service index definition endpoints.
+type testSearch struct {
+	lock    sync.Mutex
+	indexes map[string]*IndexDefinition
+	uuids   int
+}
+
+func (s *testSearch) fail(t *testing.T, writer http.ResponseWriter, msg string) {
+	cbrest.WriteTestJSON(t, writer, http.StatusBadRequest, map[string]string{"error": msg, "status": "fail"})
+}
+
+func (s *testSearch) handlers(t *testing.T, names ...string) cbrest.TestHandlers {
+	handlers := make(cbrest.TestHandlers)
+
+	handlers.Add(http.MethodGet, string(EndpointIndexes), func(writer http.ResponseWriter, _ *http.Request) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		body := map[string]any{"status": "ok"}
+		if len(s.indexes) != 0 {
+			body["indexDefs"] = map[string]any{"uuid": "defs", "indexDefs": s.indexes}
+		}
+
+		cbrest.WriteTestJSON(t, writer, http.StatusOK, body)
+	})
+
+	for _, name := range names {
+		name := name
+
+		handlers.Add(http.MethodGet, string(EndpointIndex.Format(name)), func(writer http.ResponseWriter,
+			_ *http.Request,
+		) {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+
+			index, ok := s.indexes[name]
+			if !ok {
+				s.fail(t, writer, "rest_auth: preparePerms, err: index not found")
+				return
+			}
+
+			cbrest.WriteTestJSON(t, writer, http.StatusOK, map[string]any{"status": "ok", "indexDef": index})
+		})
+
+		handlers.Add(http.MethodPut, string(EndpointIndex.Format(name)), func(writer http.ResponseWriter,
+			request *http.Request,
+		) {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+
+			var index *IndexDefinition
+			require.NoError(t, json.NewDecoder(request.Body).Decode(&index))
+
+			existing, ok := s.indexes[name]
+
+			switch {
+			case index.SourceName == "missing":
+				s.fail(t, writer, "rest_create_index: error creating index: "+name+", err: manager_api: "+
+					"failed to validate source, err: bucket: missing not found")
+
+				return
+			case ok && index.UUID == "":
+				s.fail(t, writer, "rest_create_index: error creating index: "+name+", err: manager_api: cannot "+
+					"create index because an index with the same name already exists: "+name)
+				return
+			case ok && index.UUID != existing.UUID:
+				s.fail(t, writer, fmt.Sprintf("rest_create_index: error creating index: %s, err: manager_api: "+
+					"current index uuuid: %s, did not match input uuid: %s", name, existing.UUID, index.UUID))
+
+				return
+			case !ok && index.UUID != "":
+				s.fail(t, writer, "rest_create_index: error creating index: "+name+", err: manager_api: index "+
+					"to update does not exist")
+
+				return
+			}
+
+			s.uuids++
+			index.UUID = fmt.Sprintf("uuid%d", s.uuids)
+			s.indexes[name] = index
+
+			cbrest.WriteTestJSON(t, writer, http.StatusOK, map[string]string{"status": "ok", "uuid": index.UUID})
+		})
+
+		handlers.Add(http.MethodDelete, string(EndpointIndex.Format(name)), func(writer http.ResponseWriter,
+			_ *http.Request,
+		) {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+
+			if _, ok := s.indexes[name]; !ok {
+				s.fail(t, writer, "rest_delete_index: error deleting index, err: manager_api: cannot delete "+
+					"index definition, err: index not found")
+
+				return
+			}
+
+			delete(s.indexes, name)
+
+			cbrest.WriteTestJSON(t, writer, http.StatusOK, map[string]string{"status": "ok"})
+		})
+	}
+
+	return handlers
+}
+
+func newTestClient(t *testing.T, search *testSearch, names ...string) *Client {
+	if search.indexes == nil {
+		search.indexes = make(map[string]*IndexDefinition)
+	}
+
+	nodes := cbrest.TestNodes{{Services: []cbrest.Service{cbrest.ServiceSearch}}}
+
+	return NewClient(cbrest.NewTestClusterClient(t, nodes, search.handlers(t, names...)))
+}
+
+func TestClientIndexManagement(t *testing.T) {
+	search := &testSearch{}
+	client := newTestClient(t, search, "idx1", "idx2")
+
+	ctx := context.Background()
+
+	indexes, err := client.ListIndexes(ctx)
+	require.NoError(t, err)
+	require.Empty(t, indexes)
+
+	index := &IndexDefinition{Type: "fulltext-index", Name: "idx2", SourceType: "gocbcore", SourceName: "default"}
+
+	require.NoError(t, client.CreateIndex(ctx, index))
+	require.NoError(t, client.CreateIndex(ctx, &IndexDefinition{Name: "idx1", SourceName: "default"}))
+
+	retrieved, err := client.GetIndex(ctx, "idx2")
+	require.NoError(t, err)
+	require.Equal(t, "uuid1", retrieved.UUID)
+
+	retrieved.SourceName = "other"
+
+	require.NoError(t, client.UpdateIndex(ctx, retrieved))
+
+	indexes, err = client.ListIndexes(ctx)
+	require.NoError(t, err)
+	require.Len(t, indexes, 2)
+	require.Equal(t, "idx1", indexes[0].Name)
+	require.Equal(t, "idx2", indexes[1].Name)
+	require.Equal(t, "other", indexes[1].SourceName)
+	require.Equal(t, "uuid3", indexes[1].UUID)
+
+	require.NoError(t, client.DeleteIndex(ctx, "idx2"))
+
+	_, err = client.GetIndex(ctx, "idx2")
+	require.True(t, IsIndexNotFound(err))
+}
+
+func TestClientIndexManagementErrors(t *testing.T) {
+	type test struct {
+		name  string
+		fn    func(client *Client) error
+		check func(t *testing.T, err error)
+	}
+
+	tests := []*test{
+		{
+			name: "CreateExists",
+			fn: func(client *Client) error {
+				return client.CreateIndex(context.Background(), &IndexDefinition{Name: "idx"})
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsIndexExists(err)) },
+		},
+		{
+			name: "CreateWithUUID",
+			fn: func(client *Client) error {
+				return client.CreateIndex(context.Background(), &IndexDefinition{Name: "idx", UUID: "uuid"})
+			},
+			check: func(t *testing.T, err error) { require.ErrorIs(t, err, ErrUUIDProvided) },
+		},
+		{
+			name: "UpdateWithoutUUID",
+			fn: func(client *Client) error {
+				return client.UpdateIndex(context.Background(), &IndexDefinition{Name: "idx"})
+			},
+			check: func(t *testing.T, err error) { require.ErrorIs(t, err, ErrUUIDRequired) },
+		},
+		{
+			name: "UpdateUUIDMismatch",
+			fn: func(client *Client) error {
+				return client.UpdateIndex(context.Background(), &IndexDefinition{Name: "idx", UUID: "stale"})
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsUUIDMismatch(err)) },
+		},
+		{
+			name: "UpdateNotFound",
+			fn: func(client *Client) error {
+				return client.UpdateIndex(context.Background(), &IndexDefinition{Name: "missing", UUID: "uuid"})
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsIndexNotFound(err)) },
+		},
+		{
+			name: "CreateSourceNotFound",
+			fn: func(client *Client) error {
+				return client.CreateIndex(context.Background(), &IndexDefinition{Name: "other", SourceName: "missing"})
+			},
+			check: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.False(t, IsIndexNotFound(err))
+			},
+		},
+		{
+			name: "UpdateSourceNotFound",
+			fn: func(client *Client) error {
+				return client.UpdateIndex(context.Background(), &IndexDefinition{
+					Name:       "idx",
+					UUID:       "uuid",
+					SourceName: "missing",
+				})
+			},
+			check: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.False(t, IsIndexNotFound(err))
+			},
+		},
+		{
+			name:  "DeleteNotFound",
+			fn:    func(client *Client) error { return client.DeleteIndex(context.Background(), "missing") },
+			check: func(t *testing.T, err error) { require.True(t, IsIndexNotFound(err)) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			search := &testSearch{indexes: map[string]*IndexDefinition{"idx": {Name: "idx", UUID: "uuid"}}}
+			test.check(t, test.fn(newTestClient(t, search, "idx", "missing", "other")))
+		})
+	}
+}
+
+func TestClientExportImportIndexes(t *testing.T) {
+	source := &testSearch{
+		indexes: map[string]*IndexDefinition{
+			"idx": {
+				Name:       "idx",
+				UUID:       "uuid",
+				SourceName: "default",
+				SourceUUID: "source",
+				Params:     json.RawMessage(`{"doc_config":{"mode":"scope.collection"},"mapping":{"types":{"s1.c1":{}}}}`),
+			},
+		},
+	}
+
+	exported, err := newTestClient(t, source).ExportIndexes(context.Background())
+	require.NoError(t, err)
+	require.Len(t, exported, 1)
+	require.Empty(t, exported[0].UUID)
+	require.Empty(t, exported[0].SourceUUID)
+
+	target := &testSearch{}
+
+	err = newTestClient(t, target, "idx").ImportIndexes(context.Background(), exported, Remapping{
+		Bucket: "other",
+		Scopes: map[string]string{"s1": "s2"},
+	})
+	require.NoError(t, err)
+
+	imported := target.indexes["idx"]
+	require.NotNil(t, imported)
+	require.Equal(t, "other", imported.SourceName)
+	require.JSONEq(t, `{"doc_config":{"mode":"scope.collection"},"mapping":{"types":{"s2.c1":{}}}}`,
+		string(imported.Params))
+
+	err = newTestClient(t, target, "idx").ImportIndexes(context.Background(), exported, Remapping{})
+
+	var importErr *ImportError
+	require.ErrorAs(t, err, &importErr)
+	require.True(t, IsIndexExists(err))
+}
@@ -0,0 +1,77 @@
+package ftsutil
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUUIDRequired is returned if the user attempts to update an index definition without providing the UUID of the
+	// definition being replaced.
+	ErrUUIDRequired = errors.New("the UUID of the existing index definition is required when updating an index")
+
+	// ErrUUIDProvided is returned if the user attempts to create an index using a definition which has a UUID, use
+	// 'Export' to remove the UUID.
+	ErrUUIDProvided = errors.New("the index definition must not have a UUID when creating an index")
+)
+
+// IndexNotFoundError is returned if the user attempts to interact with a search index which doesn't exist.
+type IndexNotFoundError struct {
+	name string
+}
+
+func (e *IndexNotFoundError) Error() string {
+	return fmt.Sprintf("search index '%s' not found", e.name)
+}
+
+// IsIndexNotFound returns a boolean indicating whether the given error is an 'IndexNotFoundError'.
+func IsIndexNotFound(err error) bool {
+	var notFound *IndexNotFoundError
+	return errors.As(err, &notFound)
+}
+
+// IndexExistsError is returned if the user attempts to create a search index which already exists.
+type IndexExistsError struct {
+	name string
+}
+
+func (e *IndexExistsError) Error() string {
+	return fmt.Sprintf("search index '%s' already exists", e.name)
+}
+
+// IsIndexExists returns a boolean indicating whether the given error is an 'IndexExistsError'.
+func IsIndexExists(err error) bool {
+	var exists *IndexExistsError
+	return errors.As(err, &exists)
+}
+
+// UUIDMismatchError is returned when updating a search index, if the index definition has been modified since it was
+// retrieved; the definition should be retrieved again, and the update reapplied.
+type UUIDMismatchError struct {
+	name string
+	uuid string
+}
+
+func (e *UUIDMismatchError) Error() string {
+	return fmt.Sprintf("search index '%s' has been modified, the UUID '%s' is not the current UUID", e.name, e.uuid)
+}
+
+// IsUUIDMismatch returns a boolean indicating whether the given error is an 'UUIDMismatchError'.
+func IsUUIDMismatch(err error) bool {
+	var mismatch *UUIDMismatchError
+	return errors.As(err, &mismatch)
+}
+
+// ImportError is returned if one of the index definitions fails to be imported.
+type ImportError struct {
+	name string
+	err  error
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("failed to import search index '%s': %s", e.name, e.err)
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.err
+}
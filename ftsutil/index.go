@@ -0,0 +1,158 @@
+package ftsutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// IndexDefinition represents a Full Text Search index definition, as returned by the Search Service.
+type IndexDefinition struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+
+	// UUID identifies the current version of the index definition, it's used for optimistic concurrency when updating
+	// an index and should be empty when creating an index.
+	UUID string `json:"uuid,omitempty"`
+
+	SourceType string `json:"sourceType"`
+
+	// SourceName is the name of the bucket which is being indexed.
+	SourceName string `json:"sourceName"`
+
+	// SourceUUID is the UUID of the bucket which is being indexed, it's cleared when exporting to allow the index to be
+	// created on a different bucket/cluster.
+	SourceUUID string `json:"sourceUUID,omitempty"`
+
+	SourceParams json.RawMessage `json:"sourceParams,omitempty"`
+	PlanParams   json.RawMessage `json:"planParams,omitempty"`
+	Params       json.RawMessage `json:"params,omitempty"`
+}
+
+// Export returns a copy of the index definition, with the cluster specific attributes removed, which may be used to
+// recreate the index using 'CreateIndex' or 'ImportIndexes'.
+func (i *IndexDefinition) Export() *IndexDefinition {
+	exported := *i
+
+	exported.UUID = ""
+	exported.SourceUUID = ""
+
+	return &exported
+}
+
+// Remapping describes how the source bucket/scopes/collections of an index definition should be renamed, for example
+// when restoring an index to a different bucket.
+type Remapping struct {
+	// Bucket is the name of the bucket the index should be created on, where an empty string means unchanged.
+	Bucket string
+
+	// Scopes maps scope names to the name of the scope they should be renamed to.
+	Scopes map[string]string
+
+	// Collections maps '<scope>.<collection>' to the '<scope>.<collection>' it should be renamed to, this takes
+	// precedence over the scope mappings.
+	Collections map[string]string
+}
+
+// remap returns the renamed type mapping key, which may be in the form '<scope>.<collection>[.<type>]'.
+func (r Remapping) remap(key string) string {
+	parts := strings.SplitN(key, ".", 3)
+	if len(parts) < 2 {
+		return key
+	}
+
+	keyspace := parts[0] + "." + parts[1]
+
+	if mapped, ok := r.Collections[keyspace]; ok {
+		keyspace = mapped
+	} else if mapped, ok := r.Scopes[parts[0]]; ok {
+		keyspace = mapped + "." + parts[1]
+	}
+
+	if len(parts) == 3 {
+		return keyspace + "." + parts[2]
+	}
+
+	return keyspace
+}
+
+// Remap returns a copy of the index definition, with the source bucket/scopes/collections renamed using the given
+// remapping.
+//
+// NOTE: Scopes/collections are only remapped for indexes which use a 'scope.collection' document config mode, for
+// other indexes only the bucket is remapped.
+func (i *IndexDefinition) Remap(remapping Remapping) (*IndexDefinition, error) {
+	remapped := *i
+
+	if remapping.Bucket != "" && remapping.Bucket != i.SourceName {
+		remapped.SourceName = remapping.Bucket
+		remapped.SourceUUID = ""
+	}
+
+	if len(i.Params) == 0 || (len(remapping.Scopes) == 0 && len(remapping.Collections) == 0) {
+		return &remapped, nil
+	}
+
+	params, err := remapParams(i.Params, remapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remap index '%s': %w", i.Name, err)
+	}
+
+	remapped.Params = params
+
+	return &remapped, nil
+}
+
+// remapParams renames the keys of the type mappings in the given index params, all other attributes are preserved.
+func remapParams(data json.RawMessage, remapping Remapping) (json.RawMessage, error) {
+	var params map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal params: %w", err)
+	}
+
+	type docConfig struct {
+		Mode string `json:"mode"`
+	}
+
+	var config docConfig
+
+	// Purposefully ignored, older index definitions may not have a doc config in which case there's nothing to remap
+	_ = json.Unmarshal(params["doc_config"], &config)
+
+	if !strings.HasPrefix(config.Mode, "scope.collection") || params["mapping"] == nil {
+		return data, nil
+	}
+
+	var mapping map[string]json.RawMessage
+
+	err = json.Unmarshal(params["mapping"], &mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mapping: %w", err)
+	}
+
+	if mapping["types"] == nil {
+		return data, nil
+	}
+
+	var types map[string]json.RawMessage
+
+	err = json.Unmarshal(mapping["types"], &types)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal type mappings: %w", err)
+	}
+
+	remapped := make(map[string]json.RawMessage, len(types))
+	for key, value := range types {
+		remapped[remapping.remap(key)] = value
+	}
+
+	// Purposefully ignored, marshaling maps of raw messages can't fail
+	mapping["types"], _ = json.Marshal(remapped)
+	params["mapping"], _ = json.Marshal(mapping)
+
+	encoded, _ := json.Marshal(params)
+
+	return encoded, nil
+}
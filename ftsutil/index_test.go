@@ -0,0 +1,80 @@
+package ftsutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexDefinitionExport(t *testing.T) {
+	index := &IndexDefinition{Name: "idx", UUID: "uuid", SourceName: "default", SourceUUID: "source"}
+
+	require.Equal(t, &IndexDefinition{Name: "idx", SourceName: "default"}, index.Export())
+	require.Equal(t, "uuid", index.UUID)
+}
+
+func TestIndexDefinitionRemap(t *testing.T) {
+	type test struct {
+		name      string
+		params    string
+		remapping Remapping
+		expected  string
+	}
+
+	tests := []*test{
+		{
+			name:      "BucketOnly",
+			params:    `{"mapping":{"types":{"s1.c1":{}}}}`,
+			remapping: Remapping{Bucket: "other"},
+			expected:  `{"mapping":{"types":{"s1.c1":{}}}}`,
+		},
+		{
+			name:      "NotScoped",
+			params:    `{"doc_config":{"mode":"type_field"},"mapping":{"types":{"s1.c1":{}}}}`,
+			remapping: Remapping{Scopes: map[string]string{"s1": "s2"}},
+			expected:  `{"doc_config":{"mode":"type_field"},"mapping":{"types":{"s1.c1":{}}}}`,
+		},
+		{
+			name: "Scoped",
+			params: `{"doc_config":{"mode":"scope.collection.type_field"},"mapping":{"default_mapping":{},` +
+				`"types":{"s1.c1":{"enabled":true},"s1.c2.beer":{},"s3.c1":{},"s4":{}}},"store":{}}`,
+			remapping: Remapping{
+				Scopes:      map[string]string{"s1": "s2", "s4": "s5"},
+				Collections: map[string]string{"s1.c1": "s6.c6", "s3.c1": "s3.c7"},
+			},
+			expected: `{"doc_config":{"mode":"scope.collection.type_field"},"mapping":{"default_mapping":{},` +
+				`"types":{"s6.c6":{"enabled":true},"s2.c2.beer":{},"s3.c7":{},"s4":{}}},"store":{}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index := &IndexDefinition{
+				Name:       "idx",
+				SourceName: "default",
+				SourceUUID: "source",
+				Params:     json.RawMessage(test.params),
+			}
+
+			remapped, err := index.Remap(test.remapping)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, string(remapped.Params))
+
+			if test.remapping.Bucket == "" {
+				require.Equal(t, "default", remapped.SourceName)
+				require.Equal(t, "source", remapped.SourceUUID)
+			} else {
+				require.Equal(t, test.remapping.Bucket, remapped.SourceName)
+				require.Empty(t, remapped.SourceUUID)
+			}
+		})
+	}
+}
+
+func TestIndexDefinitionRemapInvalidParams(t *testing.T) {
+	index := &IndexDefinition{Name: "idx", Params: json.RawMessage(`[]`)}
+
+	_, err := index.Remap(Remapping{Scopes: map[string]string{"s1": "s2"}})
+	require.Error(t, err)
+}
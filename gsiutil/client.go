@@ -0,0 +1,227 @@
+package gsiutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/couchbase/tools-common/cbrest"
+	"github.com/couchbase/tools-common/queryutil"
+)
+
+// EndpointGetIndexStatus is used to retrieve the definitions/status of all the indexes in the cluster.
+const EndpointGetIndexStatus cbrest.Endpoint = "/getIndexStatus"
+
+const (
+	// errCodeIndexExists is returned by the Query Service when creating an index which already exists.
+	errCodeIndexExists = 4300
+
+	// errCodeIndexNotFound is returned by the Query Service when dropping an index which doesn't exist.
+	errCodeIndexNotFound = 12016
+)
+
+// Client is a wrapper around the 'cbrest' client which implements methods to manage indexes using the Indexing/Query
+// Services.
+type Client struct {
+	*cbrest.Client
+}
+
+// NewClient creates a new client which will dispatch requests using the given 'cbrest' client.
+func NewClient(client *cbrest.Client) *Client {
+	return &Client{client}
+}
+
+// ListIndexes returns the definitions/status of the indexes in the given keyspace, an empty bucket/scope/collection
+// will match all buckets/scopes/collections.
+//
+// NOTE: Replica indexes are reported separately, and have the same name as the index they are a replica of.
+func (c *Client) ListIndexes(ctx context.Context, keyspace Keyspace) ([]*Index, error) {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointGetIndexStatus,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            cbrest.ServiceGSI,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	type overlay struct {
+		Status []*Index `json:"status"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	indexes := make([]*Index, 0, len(decoded.Status))
+
+	for _, index := range decoded.Status {
+		if keyspace.contains(index) {
+			indexes = append(indexes, index)
+		}
+	}
+
+	return indexes, nil
+}
+
+// CreateIndex creates the given index using the Query Service, an 'IndexExistsError' is returned if an index with the
+// same name already exists in the keyspace.
+func (c *Client) CreateIndex(ctx context.Context, definition IndexDefinition) error {
+	if !definition.Primary && definition.Name == "" {
+		return ErrIndexNameRequired
+	}
+
+	name := definition.Name
+	if definition.Primary && name == "" {
+		name = "#primary"
+	}
+
+	return c.executeIndexStatement(ctx, definition.Keyspace, name, createIndexStatement(definition))
+}
+
+// CreateIndexFromDefinition creates an index using the 'Definition' statement returned by 'ListIndexes', this may be
+// used to restore previously backed up index definitions.
+//
+// NOTE: The definition contains the nodes the index was placed on, these nodes must exist in the cluster.
+func (c *Client) CreateIndexFromDefinition(ctx context.Context, index *Index) error {
+	return c.executeIndexStatement(ctx, index.Keyspace(), index.Name, index.Definition)
+}
+
+// DropIndex drops the given index, an 'IndexNotFoundError' is returned if the index does not exist.
+func (c *Client) DropIndex(ctx context.Context, keyspace Keyspace, name string) error {
+	return c.executeIndexStatement(ctx, keyspace, name, dropIndexStatement(keyspace, name))
+}
+
+// BuildDeferredIndexes builds all the deferred indexes in the given keyspace, returning the names of the indexes which
+// are being built. Use 'WaitForIndexesOnline' to wait for the build to complete.
+//
+// NOTE: The keyspace must include a bucket, deferred indexes are built per-collection.
+func (c *Client) BuildDeferredIndexes(ctx context.Context, keyspace Keyspace) ([]string, error) {
+	indexes, err := c.ListIndexes(ctx, keyspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+
+	deferred := make(map[Keyspace][]string)
+
+	for _, index := range indexes {
+		if !index.Deferred() || slices.Contains(deferred[index.Keyspace()], index.Name) {
+			continue
+		}
+
+		deferred[index.Keyspace()] = append(deferred[index.Keyspace()], index.Name)
+	}
+
+	built := make([]string, 0)
+
+	for keyspace, names := range deferred {
+		err = c.executeStatement(ctx, buildIndexStatement(keyspace, names))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build indexes on keyspace %s: %w", keyspace, err)
+		}
+
+		built = append(built, names...)
+	}
+
+	slices.Sort(built)
+
+	return built, nil
+}
+
+// WaitForIndexesOnline blocks until the given indexes (or all the indexes in the keyspace if none are provided) are
+// online. An 'IndexesNotOnlineError' is returned if the poll timeout is reached, and an 'IndexFailedError' if any of
+// the indexes enter the error state.
+func (c *Client) WaitForIndexesOnline(ctx context.Context, keyspace Keyspace, names ...string) error {
+	ctx, cancelFunc := context.WithTimeout(ctx, c.PollTimeout())
+	defer cancelFunc()
+
+	var pending []string
+
+	timeout, err := c.PollRequestsWithContext(ctx, func(attempt int) (bool, error) {
+		indexes, err := c.ListIndexes(ctx, keyspace)
+		if err != nil {
+			return false, fmt.Errorf("failed to list indexes: %w", err)
+		}
+
+		pending, err = pendingIndexes(keyspace, indexes, names)
+
+		return len(pending) == 0, err
+	})
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	if timeout {
+		return &IndexesNotOnlineError{names: pending}
+	}
+
+	return nil
+}
+
+// pendingIndexes returns the names of the indexes which are not yet online, an error is returned if any of the
+// indexes have failed, or don't exist.
+func pendingIndexes(keyspace Keyspace, indexes []*Index, names []string) ([]string, error) {
+	pending := make([]string, 0)
+
+	for _, index := range indexes {
+		if len(names) != 0 && !slices.Contains(names, index.Name) {
+			continue
+		}
+
+		if index.Status == IndexStatusError {
+			return nil, &IndexFailedError{keyspace: index.Keyspace(), name: index.Name}
+		}
+
+		if index.Status != IndexStatusReady && !slices.Contains(pending, index.Name) {
+			pending = append(pending, index.Name)
+		}
+	}
+
+	for _, name := range names {
+		if slices.IndexFunc(indexes, func(index *Index) bool { return index.Name == name }) == -1 {
+			return nil, &IndexNotFoundError{keyspace: keyspace, name: name}
+		}
+	}
+
+	return pending, nil
+}
+
+// executeIndexStatement executes the given statement which manages the given index, converting Query Service errors
+// into typed errors where possible.
+func (c *Client) executeIndexStatement(ctx context.Context, keyspace Keyspace, name, statement string) error {
+	err := c.executeStatement(ctx, statement)
+	if err == nil {
+		return nil
+	}
+
+	// NOTE: Only the error codes are checked, since other errors share similar messages e.g. a missing keyspace
+	switch {
+	case queryutil.IsQueryErrorCode(err, errCodeIndexExists):
+		return &IndexExistsError{keyspace: keyspace, name: name}
+	case queryutil.IsQueryErrorCode(err, errCodeIndexNotFound):
+		return &IndexNotFoundError{keyspace: keyspace, name: name}
+	}
+
+	return err // Purposefully not wrapped
+}
+
+// executeStatement executes the given N1QL statement using the Query Service, see 'queryutil.Client.Query' for the
+// errors returned if the statement fails.
+func (c *Client) executeStatement(ctx context.Context, statement string) error {
+	_, err := queryutil.NewClient(c.Client).Execute(ctx, queryutil.QueryOptions{Statement: statement})
+	if err != nil {
+		return fmt.Errorf("failed to execute statement '%s': %w", statement, err)
+	}
+
+	return nil
+}
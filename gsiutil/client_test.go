@@ -0,0 +1,294 @@
+package gsiutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/cbrest"
+	"github.com/couchbase/tools-common/queryutil"
+)
+
+// testIndexer is a minimal simulation of the Indexing/Query Services, which tracks the statements it's executed.
+type testIndexer struct {
+	lock       sync.Mutex
+	indexes    []*Index
+	statements []string
+	errors     string
+
+	// building is the number of times an index will be reported as building before it becomes ready
+	building int
+}
+
+func (i *testIndexer) handlers(t *testing.T) cbrest.TestHandlers {
+	handlers := make(cbrest.TestHandlers)
+
+	handlers.Add(http.MethodGet, string(EndpointGetIndexStatus), func(writer http.ResponseWriter, _ *http.Request) {
+		i.lock.Lock()
+		defer i.lock.Unlock()
+
+		for _, index := range i.indexes {
+			if index.Status != IndexStatusBuilding {
+				continue
+			}
+
+			if i.building == 0 {
+				index.Status = IndexStatusReady
+			}
+		}
+
+		if i.building > 0 {
+			i.building--
+		}
+
+		cbrest.WriteTestJSON(t, writer, http.StatusOK, map[string]any{"code": "success", "status": i.indexes})
+	})
+
+	handlers.Add(http.MethodPost, string(queryutil.EndpointQueryService), func(writer http.ResponseWriter,
+		request *http.Request,
+	) {
+		i.lock.Lock()
+		defer i.lock.Unlock()
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+
+		i.statements = append(i.statements, body["statement"])
+
+		if i.errors != "" {
+			writer.WriteHeader(http.StatusInternalServerError)
+
+			_, err := writer.Write([]byte(i.errors))
+			require.NoError(t, err)
+
+			return
+		}
+
+		for _, index := range i.indexes {
+			if index.Status == IndexStatusCreated {
+				index.Status = IndexStatusBuilding
+			}
+		}
+
+		_, err := writer.Write([]byte(`{"status":"success"}`))
+		require.NoError(t, err)
+	})
+
+	return handlers
+}
+
+func newTestClient(t *testing.T, indexer *testIndexer) *Client {
+	nodes := cbrest.TestNodes{{Services: []cbrest.Service{cbrest.ServiceGSI, cbrest.ServiceQuery}}}
+
+	return NewClient(cbrest.NewTestClusterClient(t, nodes, indexer.handlers(t)))
+}
+
+func TestClientListIndexes(t *testing.T) {
+	indexer := &testIndexer{
+		indexes: []*Index{
+			{Name: "idx1", Bucket: "default", Status: IndexStatusReady},
+			{Name: "idx2", Bucket: "default", Scope: "scope", Collection: "collection", Status: IndexStatusReady},
+			{Name: "idx3", Bucket: "other", Scope: "_default", Collection: "_default", Status: IndexStatusCreated},
+		},
+	}
+
+	client := newTestClient(t, indexer)
+
+	type test struct {
+		name     string
+		keyspace Keyspace
+		expected []string
+	}
+
+	tests := []*test{
+		{
+			name:     "All",
+			expected: []string{"idx1", "idx2", "idx3"},
+		},
+		{
+			name:     "Bucket",
+			keyspace: Keyspace{Bucket: "default"},
+			expected: []string{"idx1", "idx2"},
+		},
+		{
+			name:     "DefaultCollection",
+			keyspace: Keyspace{Bucket: "default", Scope: "_default", Collection: "_default"},
+			expected: []string{"idx1"},
+		},
+		{
+			name:     "Scope",
+			keyspace: Keyspace{Bucket: "default", Scope: "scope"},
+			expected: []string{"idx2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexes, err := client.ListIndexes(context.Background(), test.keyspace)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(indexes))
+			for _, index := range indexes {
+				names = append(names, index.Name)
+			}
+
+			require.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestClientCreateDropIndex(t *testing.T) {
+	indexer := &testIndexer{}
+	client := newTestClient(t, indexer)
+
+	keyspace := Keyspace{Bucket: "default"}
+
+	require.NoError(t, client.CreateIndex(context.Background(), IndexDefinition{Keyspace: keyspace, Primary: true}))
+	require.NoError(t, client.DropIndex(context.Background(), keyspace, "#primary"))
+	require.NoError(t, client.CreateIndexFromDefinition(context.Background(), &Index{
+		Name:       "idx",
+		Bucket:     "default",
+		Definition: "CREATE INDEX `idx` ON `default`(`name`)",
+	}))
+
+	expected := []string{
+		"CREATE PRIMARY INDEX ON `default` USING GSI",
+		"DROP INDEX `#primary` ON `default` USING GSI",
+		"CREATE INDEX `idx` ON `default`(`name`)",
+	}
+
+	require.Equal(t, expected, indexer.statements)
+
+	require.ErrorIs(t, client.CreateIndex(context.Background(), IndexDefinition{Keyspace: keyspace}),
+		ErrIndexNameRequired)
+}
+
+func TestClientCreateDropIndexErrors(t *testing.T) {
+	type test struct {
+		name   string
+		errors string
+		fn     func(client *Client) error
+		check  func(t *testing.T, err error)
+	}
+
+	tests := []*test{
+		{
+			name:   "Exists",
+			errors: `{"errors":[{"code":4300,"msg":"The index idx already exists."}],"status":"errors"}`,
+			fn: func(client *Client) error {
+				return client.CreateIndex(context.Background(), IndexDefinition{
+					Name:     "idx",
+					Keyspace: Keyspace{Bucket: "default"},
+					Keys:     []string{"name"},
+				})
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsIndexExists(err)) },
+		},
+		{
+			name:   "NotFound",
+			errors: `{"errors":[{"code":12016,"msg":"Index Not Found - cause: GSI index idx not found."}]}`,
+			fn: func(client *Client) error {
+				return client.DropIndex(context.Background(), Keyspace{Bucket: "default"}, "idx")
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsIndexNotFound(err)) },
+		},
+		{
+			name:   "KeyspaceNotFound",
+			errors: `{"errors":[{"code":12003,"msg":"Keyspace not found in CB datastore: default:missing"}]}`,
+			fn: func(client *Client) error {
+				return client.CreateIndex(context.Background(), IndexDefinition{
+					Name:     "idx",
+					Keyspace: Keyspace{Bucket: "missing"},
+					Keys:     []string{"name"},
+				})
+			},
+			check: func(t *testing.T, err error) {
+				require.False(t, IsIndexNotFound(err))
+				require.True(t, queryutil.IsQueryErrorCode(err, 12003))
+			},
+		},
+		{
+			name:   "Other",
+			errors: `{"errors":[{"code":3000,"msg":"syntax error"}]}`,
+			fn: func(client *Client) error {
+				return client.DropIndex(context.Background(), Keyspace{Bucket: "default"}, "idx")
+			},
+			check: func(t *testing.T, err error) {
+				require.True(t, queryutil.IsQueryErrorCode(err, 3000))
+			},
+		},
+		{
+			name:   "NotJSON",
+			errors: "internal error",
+			fn: func(client *Client) error {
+				return client.DropIndex(context.Background(), Keyspace{Bucket: "default"}, "idx")
+			},
+			check: func(t *testing.T, err error) {
+				var internalErr *cbrest.InternalServerError
+				require.ErrorAs(t, err, &internalErr)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.check(t, test.fn(newTestClient(t, &testIndexer{errors: test.errors})))
+		})
+	}
+}
+
+func TestClientBuildDeferredIndexesAndWait(t *testing.T) {
+	indexer := &testIndexer{
+		indexes: []*Index{
+			{Name: "idx1", Bucket: "default", Status: IndexStatusCreated},
+			{Name: "idx1", Bucket: "default", Status: IndexStatusCreated},
+			{Name: "idx2", Bucket: "default", Status: IndexStatusReady},
+			{Name: "idx3", Bucket: "default", Scope: "scope", Collection: "collection", Status: IndexStatusCreated},
+		},
+		building: 1,
+	}
+
+	client := newTestClient(t, indexer)
+
+	keyspace := Keyspace{Bucket: "default"}
+
+	built, err := client.BuildDeferredIndexes(context.Background(), keyspace)
+	require.NoError(t, err)
+	require.Equal(t, []string{"idx1", "idx3"}, built)
+	require.ElementsMatch(t, []string{
+		"BUILD INDEX ON `default`(`idx1`) USING GSI",
+		"BUILD INDEX ON `default`.`scope`.`collection`(`idx3`) USING GSI",
+	}, indexer.statements)
+
+	require.NoError(t, client.WaitForIndexesOnline(context.Background(), keyspace))
+}
+
+func TestClientWaitForIndexesOnlineErrors(t *testing.T) {
+	indexer := &testIndexer{
+		indexes: []*Index{
+			{Name: "building", Bucket: "default", Status: IndexStatusBuilding},
+			{Name: "failed", Bucket: "default", Status: IndexStatusError},
+		},
+		building: 10,
+	}
+
+	client := newTestClient(t, indexer)
+
+	keyspace := Keyspace{Bucket: "default"}
+
+	var failedErr *IndexFailedError
+	require.ErrorAs(t, client.WaitForIndexesOnline(context.Background(), keyspace, "failed"), &failedErr)
+	require.True(t, IsIndexNotFound(client.WaitForIndexesOnline(context.Background(), keyspace, "missing")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	var notOnlineErr *IndexesNotOnlineError
+	require.ErrorAs(t, client.WaitForIndexesOnline(ctx, keyspace, "building"), &notOnlineErr)
+	require.Equal(t, []string{"building"}, notOnlineErr.Names())
+}
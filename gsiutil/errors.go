@@ -0,0 +1,66 @@
+package gsiutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrIndexNameRequired is returned if the user attempts to create a secondary index without providing a name.
+var ErrIndexNameRequired = errors.New("a name is required when creating a secondary index")
+
+// IndexNotFoundError is returned if the user attempts to interact with an index which doesn't exist.
+type IndexNotFoundError struct {
+	keyspace Keyspace
+	name     string
+}
+
+func (e *IndexNotFoundError) Error() string {
+	return fmt.Sprintf("index '%s' not found on keyspace %s", e.name, e.keyspace)
+}
+
+// IsIndexNotFound returns a boolean indicating whether the given error is an 'IndexNotFoundError'.
+func IsIndexNotFound(err error) bool {
+	var notFound *IndexNotFoundError
+	return errors.As(err, &notFound)
+}
+
+// IndexExistsError is returned if the user attempts to create an index which already exists.
+type IndexExistsError struct {
+	keyspace Keyspace
+	name     string
+}
+
+func (e *IndexExistsError) Error() string {
+	return fmt.Sprintf("index '%s' already exists on keyspace %s", e.name, e.keyspace)
+}
+
+// IsIndexExists returns a boolean indicating whether the given error is an 'IndexExistsError'.
+func IsIndexExists(err error) bool {
+	var exists *IndexExistsError
+	return errors.As(err, &exists)
+}
+
+// IndexFailedError is returned when waiting for an index to come online, but it enters the error state.
+type IndexFailedError struct {
+	keyspace Keyspace
+	name     string
+}
+
+func (e *IndexFailedError) Error() string {
+	return fmt.Sprintf("index '%s' on keyspace %s is in the error state", e.name, e.keyspace)
+}
+
+// IndexesNotOnlineError is returned if the poll timeout is reached whilst waiting for indexes to come online.
+type IndexesNotOnlineError struct {
+	names []string
+}
+
+func (e *IndexesNotOnlineError) Error() string {
+	return fmt.Sprintf("timed out waiting for indexes to come online: %s", strings.Join(e.names, ", "))
+}
+
+// Names returns the names of the indexes which were not online when the timeout was reached.
+func (e *IndexesNotOnlineError) Names() []string {
+	return append([]string(nil), e.names...)
+}
@@ -0,0 +1,127 @@
+package gsiutil
+
+import (
+	"github.com/couchbase/tools-common/cbvalue"
+)
+
+// IndexStatus represents the status of an index as reported by the Indexing Service.
+type IndexStatus string
+
+const (
+	// IndexStatusCreated indicates that the index has been created with 'defer_build' and is waiting to be built.
+	IndexStatusCreated IndexStatus = "Created"
+
+	// IndexStatusBuilding indicates that the index is currently being built.
+	IndexStatusBuilding IndexStatus = "Building"
+
+	// IndexStatusReady indicates that the index is built and online.
+	IndexStatusReady IndexStatus = "Ready"
+
+	// IndexStatusError indicates that the index is in an error state.
+	IndexStatusError IndexStatus = "Error"
+)
+
+// Keyspace represents a bucket/scope/collection in which indexes may be created.
+//
+// NOTE: When the scope/collection are omitted, the default scope/collection are assumed.
+type Keyspace struct {
+	Bucket     string
+	Scope      string
+	Collection string
+}
+
+// scope returns the scope name, defaulting to the default scope when not provided.
+func (k Keyspace) scope() string {
+	if k.Scope == "" {
+		return cbvalue.DefaultScope
+	}
+
+	return k.Scope
+}
+
+// collection returns the collection name, defaulting to the default collection when not provided.
+func (k Keyspace) collection() string {
+	if k.Collection == "" {
+		return cbvalue.DefaultCollection
+	}
+
+	return k.Collection
+}
+
+// contains returns a boolean indicating whether the given index is in the keyspace, where an empty scope/collection in
+// the keyspace matches any scope/collection.
+func (k Keyspace) contains(index *Index) bool {
+	return (k.Bucket == "" || k.Bucket == index.Bucket) &&
+		(k.Scope == "" || k.Scope == index.Keyspace().scope()) &&
+		(k.Collection == "" || k.Collection == index.Keyspace().collection())
+}
+
+// String returns the escaped N1QL representation of the keyspace e.g. `bucket`.`scope`.`collection`.
+func (k Keyspace) String() string {
+	if k.Scope == "" && k.Collection == "" {
+		return quoteIdentifier(k.Bucket)
+	}
+
+	return quoteIdentifier(k.Bucket) + "." + quoteIdentifier(k.scope()) + "." + quoteIdentifier(k.collection())
+}
+
+// Index represents an index definition, and its status as reported by the Indexing Service.
+type Index struct {
+	DefinitionID uint64 `json:"defnId"`
+	InstanceID   uint64 `json:"instId"`
+
+	// Name is the name of the index, unlike the name displayed in the UI this does not include the replica id.
+	Name string `json:"index"`
+
+	Bucket     string `json:"bucket"`
+	Scope      string `json:"scope"`
+	Collection string `json:"collection"`
+
+	Status   IndexStatus `json:"status"`
+	Progress float64     `json:"progress"`
+	Hosts    []string    `json:"hosts"`
+
+	// Definition is the N1QL statement which may be used to recreate the index, this is what should be backed up and
+	// restored using 'CreateIndexFromDefinition'.
+	Definition string `json:"definition"`
+
+	IndexType   string `json:"indexType"`
+	NumReplica  int    `json:"numReplica"`
+	Partitioned bool   `json:"partitioned"`
+}
+
+// Keyspace returns the keyspace in which the index exists.
+func (i *Index) Keyspace() Keyspace {
+	return Keyspace{Bucket: i.Bucket, Scope: i.Scope, Collection: i.Collection}
+}
+
+// Deferred returns a boolean indicating whether the index was created with 'defer_build' and has not yet been built.
+func (i *Index) Deferred() bool {
+	return i.Status == IndexStatusCreated
+}
+
+// IndexDefinition encapsulates the options which may be used to create an index.
+type IndexDefinition struct {
+	// Name is the name of the index, this may be omitted for primary indexes.
+	Name string
+
+	// Keyspace is the keyspace in which the index will be created.
+	//
+	// NOTE: This attribute is required.
+	Keyspace Keyspace
+
+	// Primary indicates that a primary index should be created, in which case 'Keys' and 'Where' are ignored.
+	Primary bool
+
+	// Keys are the N1QL expressions to index, these are not escaped.
+	Keys []string
+
+	// Where is an optional N1QL expression used to create a partial index, this is not escaped.
+	Where string
+
+	// Deferred indicates that the index should be created with 'defer_build', see 'BuildDeferredIndexes'.
+	Deferred bool
+
+	// NumReplica is the number of index replicas to create.
+	NumReplica int
+}
@@ -0,0 +1,69 @@
+package gsiutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// quoteIdentifier returns the given N1QL identifier escaped using backticks.
+func quoteIdentifier(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+// createIndexStatement returns the N1QL statement which will create the given index.
+func createIndexStatement(definition IndexDefinition) string {
+	var statement strings.Builder
+
+	if definition.Primary {
+		statement.WriteString("CREATE PRIMARY INDEX ")
+
+		if definition.Name != "" {
+			statement.WriteString(quoteIdentifier(definition.Name) + " ")
+		}
+
+		statement.WriteString("ON " + definition.Keyspace.String())
+	} else {
+		fmt.Fprintf(&statement, "CREATE INDEX %s ON %s(%s)", quoteIdentifier(definition.Name),
+			definition.Keyspace.String(), strings.Join(definition.Keys, ", "))
+
+		if definition.Where != "" {
+			statement.WriteString(" WHERE " + definition.Where)
+		}
+	}
+
+	statement.WriteString(" USING GSI")
+
+	with := make(map[string]any)
+
+	if definition.Deferred {
+		with["defer_build"] = true
+	}
+
+	if definition.NumReplica > 0 {
+		with["num_replica"] = definition.NumReplica
+	}
+
+	if len(with) != 0 {
+		// Purposefully ignored, marshaling a map of booleans/integers can't fail
+		encoded, _ := json.Marshal(with)
+		statement.WriteString(" WITH " + string(encoded))
+	}
+
+	return statement.String()
+}
+
+// dropIndexStatement returns the N1QL statement which will drop the given index.
+func dropIndexStatement(keyspace Keyspace, name string) string {
+	return fmt.Sprintf("DROP INDEX %s ON %s USING GSI", quoteIdentifier(name), keyspace.String())
+}
+
+// buildIndexStatement returns the N1QL statement which will build the given deferred indexes.
+func buildIndexStatement(keyspace Keyspace, names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, quoteIdentifier(name))
+	}
+
+	return fmt.Sprintf("BUILD INDEX ON %s(%s) USING GSI", keyspace.String(), strings.Join(quoted, ", "))
+}
@@ -0,0 +1,99 @@
+package gsiutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyspaceString(t *testing.T) {
+	type test struct {
+		name     string
+		keyspace Keyspace
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name:     "Bucket",
+			keyspace: Keyspace{Bucket: "default"},
+			expected: "`default`",
+		},
+		{
+			name:     "Collection",
+			keyspace: Keyspace{Bucket: "default", Scope: "scope", Collection: "collection"},
+			expected: "`default`.`scope`.`collection`",
+		},
+		{
+			name:     "DefaultCollection",
+			keyspace: Keyspace{Bucket: "default", Scope: "scope"},
+			expected: "`default`.`scope`.`_default`",
+		},
+		{
+			name:     "Escaped",
+			keyspace: Keyspace{Bucket: "de`fault"},
+			expected: "`de``fault`",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.keyspace.String())
+		})
+	}
+}
+
+func TestCreateIndexStatement(t *testing.T) {
+	type test struct {
+		name       string
+		definition IndexDefinition
+		expected   string
+	}
+
+	tests := []*test{
+		{
+			name:       "Primary",
+			definition: IndexDefinition{Keyspace: Keyspace{Bucket: "default"}, Primary: true},
+			expected:   "CREATE PRIMARY INDEX ON `default` USING GSI",
+		},
+		{
+			name: "NamedPrimaryDeferred",
+			definition: IndexDefinition{
+				Name:     "primary",
+				Keyspace: Keyspace{Bucket: "default"},
+				Primary:  true,
+				Deferred: true,
+			},
+			expected: "CREATE PRIMARY INDEX `primary` ON `default` USING GSI WITH {\"defer_build\":true}",
+		},
+		{
+			name: "Secondary",
+			definition: IndexDefinition{
+				Name:       "idx",
+				Keyspace:   Keyspace{Bucket: "default", Scope: "scope", Collection: "collection"},
+				Keys:       []string{"`name`", "age"},
+				Where:      "age > 18",
+				Deferred:   true,
+				NumReplica: 1,
+			},
+			expected: "CREATE INDEX `idx` ON `default`.`scope`.`collection`(`name`, age) WHERE age > 18 USING GSI " +
+				"WITH {\"defer_build\":true,\"num_replica\":1}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, createIndexStatement(test.definition))
+		})
+	}
+}
+
+func TestDropIndexStatement(t *testing.T) {
+	require.Equal(t, "DROP INDEX `idx` ON `default`.`scope`.`collection` USING GSI",
+		dropIndexStatement(Keyspace{Bucket: "default", Scope: "scope", Collection: "collection"}, "idx"))
+}
+
+func TestBuildIndexStatement(t *testing.T) {
+	require.Equal(t, "BUILD INDEX ON `default`(`idx1`, `idx2`) USING GSI",
+		buildIndexStatement(Keyspace{Bucket: "default"}, []string{"idx1", "idx2"}))
+}
@@ -19,6 +19,7 @@ func (r readSeekNoopCloser) Close() error { return nil }
 type blobStorageAPI interface {
 	ToContainerAPI(container string) (containerAPI, error)
 	ToBlobAPI(container, blob string) (blobAPI, error)
+	ToAppendBlobAPI(container, blob string) (appendBlobAPI, error)
 	CanGetSASToken() bool
 }
 
@@ -52,6 +53,20 @@ func (s serviceClient) ToBlobAPI(container, blob string) (blobAPI, error) {
 	return blobClient, nil
 }
 
+func (s serviceClient) ToAppendBlobAPI(container, blob string) (appendBlobAPI, error) {
+	containerClient, err := s.ToContainerAPI(container)
+	if err != nil {
+		return nil, err
+	}
+
+	blobClient, err := containerClient.ToAppendBlobAPI(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	return blobClient, nil
+}
+
 func (s serviceClient) CanGetSASToken() bool {
 	return s.client.CanGetAccountSASToken()
 }
@@ -61,6 +76,7 @@ type containerAPI interface {
 	GetListBlobsFlatPagerAPI(options azblob.ContainerListBlobsFlatOptions) listBlobsPagerAPI
 	GetListBlobsHierarchyPagerAPI(delimiter string, options azblob.ContainerListBlobsHierarchyOptions) listBlobsPagerAPI
 	ToBlobAPI(blob string) (blobAPI, error)
+	ToAppendBlobAPI(blob string) (appendBlobAPI, error)
 }
 
 var _ containerAPI = (*containerClient)(nil)
@@ -157,6 +173,15 @@ func (c containerClient) ToBlobAPI(blob string) (blobAPI, error) {
 	return blobClient{client: client}, nil
 }
 
+func (c containerClient) ToAppendBlobAPI(blob string) (appendBlobAPI, error) {
+	client, err := c.client.NewAppendBlobClient(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendBlobClient{client: client}, nil
+}
+
 // blobAPI is a block blob interface which allows interactions with a block blob stored in an Azure container.
 type blobAPI interface {
 	Delete(ctx context.Context, options azblob.BlobDeleteOptions) (azblob.BlobDeleteResponse, error)
@@ -238,3 +263,29 @@ func (b blobClient) GetSASToken(permissions azblob.BlobSASPermissions, start, ex
 ) {
 	return b.client.GetSASToken(permissions, start, expiry)
 }
+
+// appendBlobAPI is an append blob interface which allows interactions with an append blob stored in an Azure container.
+type appendBlobAPI interface {
+	Create(ctx context.Context, options azblob.AppendBlobCreateOptions) (azblob.AppendBlobCreateResponse, error)
+	AppendBlock(ctx context.Context, body io.ReadSeeker, options azblob.AppendBlobAppendBlockOptions,
+	) (azblob.AppendBlobAppendBlockResponse, error)
+}
+
+var _ appendBlobAPI = (*appendBlobClient)(nil)
+
+// appendBlobClient implements the 'appendBlobAPI' interface and encapsulates the Azure SDK in a unit testable
+// interface.
+type appendBlobClient struct {
+	client *azblob.AppendBlobClient
+}
+
+func (a appendBlobClient) Create(ctx context.Context, options azblob.AppendBlobCreateOptions,
+) (azblob.AppendBlobCreateResponse, error) {
+	return a.client.Create(ctx, &options)
+}
+
+func (a appendBlobClient) AppendBlock(ctx context.Context, body io.ReadSeeker,
+	options azblob.AppendBlobAppendBlockOptions,
+) (azblob.AppendBlobAppendBlockResponse, error) {
+	return a.client.AppendBlock(ctx, readSeekNoopCloser{body}, &options)
+}
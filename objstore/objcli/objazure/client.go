@@ -1,6 +1,7 @@
 package objazure
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"encoding/base64"
@@ -28,14 +29,29 @@ import (
 // Client implements the 'objcli.Client' interface allowing the creation/management of blobs stored in Azure blob store.
 type Client struct {
 	storageAPI blobStorageAPI
+	options    ClientOptions
 }
 
 var _ objcli.Client = (*Client)(nil)
 
+// ClientOptions encapsulates the options available when creating a new Azure client.
+type ClientOptions struct {
+	// AppendBlobs indicates that objects created by 'AppendToObject' should be created as append blobs, meaning
+	// subsequent appends may be performed using 'AppendBlock' requests rather than by copying the existing blob.
+	//
+	// NOTE: Objects which already exist as block blobs will continue to be appended to as block blobs.
+	AppendBlobs bool
+}
+
 // NewClient returns a new client which uses the given service client, in general this should be the one created using
 // the 'azblob.NewServiceClient' function exposed by the SDK.
 func NewClient(client *azblob.ServiceClient) *Client {
-	return &Client{storageAPI: serviceClient{client: client}}
+	return NewClientWithOptions(client, ClientOptions{})
+}
+
+// NewClientWithOptions returns a new client which uses the given service client and options.
+func NewClientWithOptions(client *azblob.ServiceClient, options ClientOptions) *Client {
+	return &Client{storageAPI: serviceClient{client: client}, options: options}
 }
 
 func (c *Client) Provider() objval.Provider {
@@ -77,14 +93,9 @@ func (c *Client) GetObject(ctx context.Context, bucket, key string, br *objval.B
 }
 
 func (c *Client) GetObjectAttrs(ctx context.Context, bucket, key string) (*objval.ObjectAttrs, error) {
-	blobClient, err := c.storageAPI.ToBlobAPI(bucket, key)
+	resp, err := c.getProperties(ctx, bucket, key)
 	if err != nil {
-		return nil, handleError(bucket, key, err)
-	}
-
-	resp, err := blobClient.GetProperties(ctx, azblob.BlobGetPropertiesOptions{})
-	if err != nil {
-		return nil, handleError(bucket, key, err)
+		return nil, err // Purposefully not wrapped
 	}
 
 	attrs := &objval.ObjectAttrs{
@@ -97,6 +108,21 @@ func (c *Client) GetObjectAttrs(ctx context.Context, bucket, key string) (*objva
 	return attrs, nil
 }
 
+// getProperties returns the properties for the given blob.
+func (c *Client) getProperties(ctx context.Context, bucket, key string) (azblob.BlobGetPropertiesResponse, error) {
+	blobClient, err := c.storageAPI.ToBlobAPI(bucket, key)
+	if err != nil {
+		return azblob.BlobGetPropertiesResponse{}, handleError(bucket, key, err)
+	}
+
+	resp, err := blobClient.GetProperties(ctx, azblob.BlobGetPropertiesOptions{})
+	if err != nil {
+		return azblob.BlobGetPropertiesResponse{}, handleError(bucket, key, err)
+	}
+
+	return resp, nil
+}
+
 func (c *Client) PutObject(ctx context.Context, bucket, key string, body io.ReadSeeker) error {
 	blobClient, err := c.storageAPI.ToBlobAPI(bucket, key)
 	if err != nil {
@@ -120,10 +146,14 @@ func (c *Client) PutObject(ctx context.Context, bucket, key string, body io.Read
 }
 
 func (c *Client) AppendToObject(ctx context.Context, bucket, key string, data io.ReadSeeker) error {
-	attrs, err := c.GetObjectAttrs(ctx, bucket, key)
+	props, err := c.getProperties(ctx, bucket, key)
 
 	// As defined by the 'Client' interface, if the given object does not exist, we create it
-	if objerr.IsNotFoundError(err) || attrs != nil && attrs.Size == 0 {
+	if objerr.IsNotFoundError(err) && c.options.AppendBlobs {
+		return c.createAppendBlob(ctx, bucket, key, data)
+	}
+
+	if objerr.IsNotFoundError(err) {
 		return c.PutObject(ctx, bucket, key, data)
 	}
 
@@ -131,6 +161,15 @@ func (c *Client) AppendToObject(ctx context.Context, bucket, key string, data io
 		return fmt.Errorf("failed to get object attributes: %w", err)
 	}
 
+	// Append blobs support appending natively, there's no need to copy the existing blob
+	if props.BlobType != nil && *props.BlobType == azblob.BlobTypeAppendBlob {
+		return c.appendToAppendBlob(ctx, bucket, key, props, data)
+	}
+
+	if *props.ContentLength == 0 {
+		return c.PutObject(ctx, bucket, key, data)
+	}
+
 	id, err := c.CreateMultipartUpload(ctx, bucket, key)
 	if err != nil {
 		return fmt.Errorf("failed to start multipart upload: %w", err)
@@ -154,6 +193,119 @@ func (c *Client) AppendToObject(ctx context.Context, bucket, key string, data io
 	return nil
 }
 
+// createAppendBlob creates a new append blob, appending the given data.
+func (c *Client) createAppendBlob(ctx context.Context, bucket, key string, data io.ReadSeeker) error {
+	blobClient, err := c.storageAPI.ToAppendBlobAPI(bucket, key)
+	if err != nil {
+		return handleError(bucket, key, err)
+	}
+
+	_, err = blobClient.Create(ctx, azblob.AppendBlobCreateOptions{})
+	if err != nil {
+		return handleError(bucket, key, err)
+	}
+
+	return c.appendBlocks(ctx, bucket, key, 0, data)
+}
+
+// appendToAppendBlob appends the given data to the existing append blob with the given properties, failing upfront
+// (rather than partially appending the data) if doing so would exceed the maximum number of blocks.
+func (c *Client) appendToAppendBlob(
+	ctx context.Context, bucket, key string, props azblob.BlobGetPropertiesResponse, data io.ReadSeeker,
+) error {
+	length, err := aws.SeekerLen(data)
+	if err != nil {
+		return fmt.Errorf("failed to determine body length: %w", err)
+	}
+
+	blocks := (length + MaxAppendBlockSize - 1) / MaxAppendBlockSize
+
+	if props.BlobCommittedBlockCount != nil && int64(*props.BlobCommittedBlockCount)+blocks > MaxAppendBlocks {
+		return ErrAppendBlobBlockLimit
+	}
+
+	return c.appendBlocks(ctx, bucket, key, *props.ContentLength, data)
+}
+
+// appendBlocks appends the given data to an existing append blob, which is expected to be of the given size.
+//
+// NOTE: Data larger than 'MaxAppendBlockSize' will be appended using multiple requests, therefore, the append is not
+// guaranteed to be atomic.
+func (c *Client) appendBlocks(ctx context.Context, bucket, key string, size int64, data io.ReadSeeker) error {
+	length, err := aws.SeekerLen(data)
+	if err != nil {
+		return fmt.Errorf("failed to determine body length: %w", err)
+	}
+
+	// Appending an empty block is an error, and there's nothing to append anyway
+	if length == 0 {
+		return nil
+	}
+
+	blobClient, err := c.storageAPI.ToAppendBlobAPI(bucket, key)
+	if err != nil {
+		return handleError(bucket, key, err)
+	}
+
+	if length > MaxAppendBlockSize {
+		length = MaxAppendBlockSize
+	}
+
+	buffer := make([]byte, length)
+
+	for {
+		n, err := io.ReadFull(data, buffer)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("failed to read body: %w", err)
+		}
+
+		md5sum := md5.Sum(buffer[:n])
+
+		// The append position ensures we fail, rather than corrupting the blob, if it's modified concurrently
+		options := azblob.AppendBlobAppendBlockOptions{
+			TransactionalContentMD5:        md5sum[:],
+			AppendPositionAccessConditions: &azblob.AppendPositionAccessConditions{AppendPosition: aws.Int64(size)},
+		}
+
+		_, err = blobClient.AppendBlock(ctx, bytes.NewReader(buffer[:n]), options)
+		if err != nil {
+			err = c.handleAppendBlockError(ctx, bucket, key, size+int64(n), err)
+		}
+
+		if err != nil {
+			return err // Purposefully not wrapped
+		}
+
+		size += int64(n)
+	}
+}
+
+// handleAppendBlockError handles an error returned whilst appending a block, which should result in a blob of the given
+// size, returning <nil> if the block was actually appended.
+func (c *Client) handleAppendBlockError(ctx context.Context, bucket, key string, size int64, err error) error {
+	var azureErr *azblob.StorageError
+	if !errors.As(err, &azureErr) {
+		return handleError(bucket, key, err)
+	}
+
+	switch azureErr.ErrorCode {
+	case azblob.StorageErrorCodeBlockCountExceedsLimit:
+		return ErrAppendBlobBlockLimit
+	case azblob.StorageErrorCodeAppendPositionConditionNotMet:
+		// The SDK may retry a request whose response was lost, in which case, the block will have already been appended
+		props, propsErr := c.getProperties(ctx, bucket, key)
+		if propsErr == nil && props.ContentLength != nil && *props.ContentLength == size {
+			return nil
+		}
+	}
+
+	return handleError(bucket, key, err)
+}
+
 func (c *Client) DeleteObjects(ctx context.Context, bucket string, keys ...string) error {
 	containerClient, err := c.storageAPI.ToContainerAPI(bucket)
 	if err != nil {
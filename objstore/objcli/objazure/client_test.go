@@ -55,6 +55,14 @@ func TestNewClient(t *testing.T) {
 	require.Equal(t, &Client{storageAPI: serviceClient{client: nil}}, NewClient(nil))
 }
 
+func TestNewClientWithOptions(t *testing.T) {
+	require.Equal(
+		t,
+		&Client{storageAPI: serviceClient{client: nil}, options: ClientOptions{AppendBlobs: true}},
+		NewClientWithOptions(nil, ClientOptions{AppendBlobs: true}),
+	)
+}
+
 func TestClientProvider(t *testing.T) {
 	require.Equal(t, objval.ProviderAzure, (&Client{}).Provider())
 }
@@ -375,6 +383,229 @@ func TestClientAppendToObject(t *testing.T) {
 	mbAPI.AssertNumberOfCalls(t, "CommitBlockList", 1)
 }
 
+func TestClientAppendToObjectNotExistsAppendBlobs(t *testing.T) {
+	var (
+		msAPI = &mockBlobStorageAPI{}
+		mbAPI = &mockBlobAPI{}
+		maAPI = &mockAppendBlobAPI{}
+	)
+
+	msAPI.On(
+		"ToBlobAPI",
+		mock.MatchedBy(func(container string) bool { return container == "container" }),
+		mock.MatchedBy(func(blob string) bool { return blob == "blob" }),
+	).Return(mbAPI, nil)
+
+	msAPI.On(
+		"ToAppendBlobAPI",
+		mock.MatchedBy(func(container string) bool { return container == "container" }),
+		mock.MatchedBy(func(blob string) bool { return blob == "blob" }),
+	).Return(maAPI, nil)
+
+	mbAPI.On("GetProperties", mock.Anything, mock.Anything).Return(
+		azblob.BlobGetPropertiesResponse{},
+		&azblob.StorageError{ErrorCode: azblob.StorageErrorCodeBlobNotFound},
+	)
+
+	maAPI.On("Create", mock.Anything, mock.Anything).Return(azblob.AppendBlobCreateResponse{}, nil)
+
+	fn1 := func(options azblob.AppendBlobAppendBlockOptions) bool {
+		expected := md5.Sum([]byte("value"))
+
+		return bytes.Equal(options.TransactionalContentMD5, expected[:]) &&
+			*options.AppendPositionAccessConditions.AppendPosition == 0
+	}
+
+	maAPI.On(
+		"AppendBlock",
+		mock.Anything,
+		bytes.NewReader([]byte("value")),
+		mock.MatchedBy(fn1),
+	).Return(azblob.AppendBlobAppendBlockResponse{}, nil)
+
+	client := &Client{storageAPI: msAPI, options: ClientOptions{AppendBlobs: true}}
+
+	require.NoError(t, client.AppendToObject(context.Background(), "container", "blob", strings.NewReader("value")))
+
+	msAPI.AssertExpectations(t)
+	msAPI.AssertNumberOfCalls(t, "ToBlobAPI", 1)
+	msAPI.AssertNumberOfCalls(t, "ToAppendBlobAPI", 2)
+
+	mbAPI.AssertExpectations(t)
+	mbAPI.AssertNumberOfCalls(t, "GetProperties", 1)
+
+	maAPI.AssertExpectations(t)
+	maAPI.AssertNumberOfCalls(t, "Create", 1)
+	maAPI.AssertNumberOfCalls(t, "AppendBlock", 1)
+}
+
+func TestClientAppendToObjectAppendBlob(t *testing.T) {
+	type test struct {
+		name     string
+		data     []byte
+		expected []int64
+	}
+
+	tests := []*test{
+		{
+			name: "Empty",
+		},
+		{
+			name:     "SingleBlock",
+			data:     []byte("value"),
+			expected: []int64{42},
+		},
+		{
+			name:     "MultipleBlocks",
+			data:     make([]byte, MaxAppendBlockSize+1),
+			expected: []int64{42, 42 + MaxAppendBlockSize},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				msAPI = &mockBlobStorageAPI{}
+				mbAPI = &mockBlobAPI{}
+				maAPI = &mockAppendBlobAPI{}
+			)
+
+			msAPI.On(
+				"ToBlobAPI",
+				mock.MatchedBy(func(container string) bool { return container == "container" }),
+				mock.MatchedBy(func(blob string) bool { return blob == "blob" }),
+			).Return(mbAPI, nil)
+
+			msAPI.On(
+				"ToAppendBlobAPI",
+				mock.MatchedBy(func(container string) bool { return container == "container" }),
+				mock.MatchedBy(func(blob string) bool { return blob == "blob" }),
+			).Return(maAPI, nil)
+
+			output := azblob.BlobGetPropertiesResponse{}
+
+			output.BlobType = azblob.BlobTypeAppendBlob.ToPtr()
+			output.ContentLength = aws.Int64(42)
+
+			mbAPI.On("GetProperties", mock.Anything, mock.Anything).Return(output, nil)
+
+			positions := make([]int64, 0)
+
+			fn1 := func(options azblob.AppendBlobAppendBlockOptions) bool {
+				positions = append(positions, *options.AppendPositionAccessConditions.AppendPosition)
+				return true
+			}
+
+			maAPI.On(
+				"AppendBlock",
+				mock.Anything,
+				mock.Anything,
+				mock.MatchedBy(fn1),
+			).Return(azblob.AppendBlobAppendBlockResponse{}, nil)
+
+			client := &Client{storageAPI: msAPI}
+
+			require.NoError(t, client.AppendToObject(context.Background(), "container", "blob", bytes.NewReader(test.data)))
+
+			msAPI.AssertNumberOfCalls(t, "ToBlobAPI", 1)
+			mbAPI.AssertNumberOfCalls(t, "GetProperties", 1)
+			maAPI.AssertNumberOfCalls(t, "AppendBlock", len(test.expected))
+
+			if test.expected != nil {
+				require.Equal(t, test.expected, positions)
+			}
+		})
+	}
+}
+
+func TestClientAppendToObjectAppendBlobBlockLimit(t *testing.T) {
+	var (
+		msAPI = &mockBlobStorageAPI{}
+		mbAPI = &mockBlobAPI{}
+	)
+
+	msAPI.On("ToBlobAPI", mock.Anything, mock.Anything).Return(mbAPI, nil)
+
+	var (
+		output = azblob.BlobGetPropertiesResponse{}
+		blocks = int32(MaxAppendBlocks)
+	)
+
+	output.BlobType = azblob.BlobTypeAppendBlob.ToPtr()
+	output.ContentLength = aws.Int64(42)
+	output.BlobCommittedBlockCount = &blocks
+
+	mbAPI.On("GetProperties", mock.Anything, mock.Anything).Return(output, nil)
+
+	client := &Client{storageAPI: msAPI}
+
+	err := client.AppendToObject(context.Background(), "container", "blob", strings.NewReader("value"))
+	require.ErrorIs(t, err, ErrAppendBlobBlockLimit)
+
+	msAPI.AssertNotCalled(t, "ToAppendBlobAPI", mock.Anything, mock.Anything)
+}
+
+func TestClientAppendToObjectAppendBlobErrors(t *testing.T) {
+	type test struct {
+		name     string
+		code     azblob.StorageErrorCode
+		size     int64
+		expected error
+	}
+
+	tests := []*test{
+		{
+			name:     "BlockCountExceedsLimit",
+			code:     azblob.StorageErrorCodeBlockCountExceedsLimit,
+			size:     42,
+			expected: ErrAppendBlobBlockLimit,
+		},
+		{
+			name: "AppendPositionConditionNotMetAlreadyAppended",
+			code: azblob.StorageErrorCodeAppendPositionConditionNotMet,
+			size: 47,
+		},
+		{
+			name:     "AppendPositionConditionNotMetConcurrentlyModified",
+			code:     azblob.StorageErrorCodeAppendPositionConditionNotMet,
+			size:     64,
+			expected: &azblob.StorageError{ErrorCode: azblob.StorageErrorCodeAppendPositionConditionNotMet},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				msAPI = &mockBlobStorageAPI{}
+				mbAPI = &mockBlobAPI{}
+				maAPI = &mockAppendBlobAPI{}
+			)
+
+			msAPI.On("ToBlobAPI", mock.Anything, mock.Anything).Return(mbAPI, nil)
+			msAPI.On("ToAppendBlobAPI", mock.Anything, mock.Anything).Return(maAPI, nil)
+
+			output := azblob.BlobGetPropertiesResponse{}
+
+			output.BlobType = azblob.BlobTypeAppendBlob.ToPtr()
+			output.ContentLength = aws.Int64(42)
+
+			mbAPI.On("GetProperties", mock.Anything, mock.Anything).Return(output, nil).Once()
+
+			output.ContentLength = aws.Int64(test.size)
+
+			mbAPI.On("GetProperties", mock.Anything, mock.Anything).Return(output, nil).Once()
+
+			maAPI.On("AppendBlock", mock.Anything, mock.Anything, mock.Anything).
+				Return(azblob.AppendBlobAppendBlockResponse{}, &azblob.StorageError{ErrorCode: test.code})
+
+			client := &Client{storageAPI: msAPI}
+
+			err := client.AppendToObject(context.Background(), "container", "blob", strings.NewReader("value"))
+			require.Equal(t, test.expected, err)
+		})
+	}
+}
+
 func TestClientDeleteObjects(t *testing.T) {
 	var (
 		msAPI = &mockBlobStorageAPI{}
@@ -1314,3 +1545,92 @@ func TestClientAbortMultipartUploadWithUploadID(t *testing.T) {
 	err := client.AbortMultipartUpload(context.Background(), "container", "id", "blob")
 	require.ErrorIs(t, err, objcli.ErrExpectedNoUploadID)
 }
+
+// BenchmarkAppendToObject demonstrates the cost of repeatedly appending to a growing, log-style, object; when appending
+// to block blobs the existing blob must be copied (server side) on each append, whilst append blobs only require the
+// new data to be uploaded.
+func BenchmarkAppendToObject(b *testing.B) {
+	type benchmark struct {
+		name     string
+		blobType azblob.BlobType
+	}
+
+	benchmarks := []*benchmark{
+		{
+			name:     "BlockBlob",
+			blobType: azblob.BlobTypeBlockBlob,
+		},
+		{
+			name:     "AppendBlob",
+			blobType: azblob.BlobTypeAppendBlob,
+		},
+	}
+
+	for _, benchmark := range benchmarks {
+		b.Run(benchmark.name, func(b *testing.B) {
+			var (
+				msAPI = &mockBlobStorageAPI{}
+				mbAPI = &mockBlobAPI{}
+				maAPI = &mockAppendBlobAPI{}
+				data  = bytes.NewReader(make([]byte, 1024))
+			)
+
+			var size, requests, copied int64
+
+			msAPI.On("ToBlobAPI", mock.Anything, mock.Anything).Return(mbAPI, nil)
+			msAPI.On("ToAppendBlobAPI", mock.Anything, mock.Anything).Return(maAPI, nil)
+			msAPI.On("CanGetSASToken").Return(true)
+
+			properties := func(_ context.Context, _ azblob.BlobGetPropertiesOptions) azblob.BlobGetPropertiesResponse {
+				output := azblob.BlobGetPropertiesResponse{}
+
+				output.BlobType = benchmark.blobType.ToPtr()
+				output.ContentLength = aws.Int64(size)
+				output.ETag = aws.String("etag")
+				output.LastModified = aws.Time(time.Time{})
+
+				return output
+			}
+
+			mbAPI.On("GetProperties", mock.Anything, mock.Anything).
+				Return(properties, nil).
+				Run(func(_ mock.Arguments) { requests++ })
+
+			mbAPI.On("URL").Return("example.com")
+			mbAPI.On("GetSASToken", mock.Anything, mock.Anything, mock.Anything).Return(azblob.SASQueryParameters{}, nil)
+
+			mbAPI.On("StageBlockFromURL", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(azblob.BlockBlobStageBlockFromURLResponse{}, nil).
+				Run(func(_ mock.Arguments) { requests++; copied += size })
+
+			mbAPI.On("StageBlock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(azblob.BlockBlobStageBlockResponse{}, nil).
+				Run(func(_ mock.Arguments) { requests++ })
+
+			mbAPI.On("CommitBlockList", mock.Anything, mock.Anything, mock.Anything).
+				Return(azblob.BlockBlobCommitBlockListResponse{}, nil).
+				Run(func(_ mock.Arguments) { requests++; size += data.Size() })
+
+			maAPI.On("AppendBlock", mock.Anything, mock.Anything, mock.Anything).
+				Return(azblob.AppendBlobAppendBlockResponse{}, nil).
+				Run(func(_ mock.Arguments) { requests++; size += data.Size() })
+
+			// The blob already exists, and is non-empty, this is the steady state for a log-style object
+			size = data.Size()
+
+			client := &Client{storageAPI: msAPI}
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				_, err := data.Seek(0, io.SeekStart)
+				require.NoError(b, err)
+
+				require.NoError(b, client.AppendToObject(context.Background(), "container", "blob", data))
+			}
+
+			b.ReportMetric(float64(requests)/float64(b.N), "requests/op")
+			b.ReportMetric(float64(copied)/float64(b.N), "copied-B/op")
+		})
+	}
+}
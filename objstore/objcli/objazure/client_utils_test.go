@@ -1,8 +1,7 @@
 package objazure
 
 import (
-	"encoding/base64"
-	"fmt"
+	"context"
 	"net/http"
 	"os"
 	"testing"
@@ -36,75 +35,103 @@ func TestAzureGetStaticCredentials(t *testing.T) {
 		accessKeyID     string
 		secretAccessKey string
 		env             map[string]string
-		expected        *azblob.SharedKeyCredential
-	}
-
-	enc := func(secret string) string {
-		return base64.RawStdEncoding.EncodeToString([]byte(secret))
-	}
-
-	must := func(account, secret string) *azblob.SharedKeyCredential {
-		credentials, err := azblob.NewSharedKeyCredential(account, enc(secret))
-		require.NoError(t, err)
-
-		return credentials
+		expected        bool
+		expectedError   error
 	}
 
 	tests := []*test{
 		{
 			name:            "StaticCredentials",
-			accessKeyID:     "account",
-			secretAccessKey: enc("secret"),
+			accessKeyID:     devStoreAccountName,
+			secretAccessKey: devStoreAccountKey,
 			// Static credentials should take priority
-			env:      map[string]string{"AZURE_STORAGE_ACCOUNT": "another", "AZURE_STORAGE_KEY": enc("secret")},
-			expected: must("account", "secret"),
+			env:      map[string]string{"AZURE_STORAGE_ACCOUNT": "another", "AZURE_STORAGE_KEY": devStoreAccountKey},
+			expected: true,
 		},
 		{
 			name:        "StaticCredentialsMustSupplyBoth",
-			accessKeyID: "account",
+			accessKeyID: devStoreAccountName,
 		},
 		{
 			name:            "StaticCredentialsMustSupplyBoth",
-			secretAccessKey: enc("secret"),
+			secretAccessKey: devStoreAccountKey,
 		},
 		{
 			name: "StaticCredentialsViaEnv",
 			env: map[string]string{
-				"AZURE_STORAGE_ACCOUNT": "account", "AZURE_STORAGE_KEY": enc("secret"),
+				"AZURE_STORAGE_ACCOUNT": devStoreAccountName, "AZURE_STORAGE_KEY": devStoreAccountKey,
 				// Static env should take priority over a connection string
-				"AZURE_STORAGE_CONNECTION_STRING": fmt.Sprintf("AccountName=another;AccountKey=%s", enc("secret")),
+				"AZURE_STORAGE_CONNECTION_STRING": "AccountName=another;AccountKey=" + devStoreAccountKey,
 			},
-			expected: must("account", "secret"),
+			expected: true,
 		},
 		{
 			name: "StaticCredentialsViaConnectionString",
 			env: map[string]string{
-				"AZURE_STORAGE_CONNECTION_STRING": fmt.Sprintf("AccountName=account;AccountKey=%s", enc("secret")),
+				"AZURE_STORAGE_CONNECTION_STRING": "AccountName=" + devStoreAccountName + ";AccountKey=" + devStoreAccountKey,
 			},
-			expected: must("account", "secret"),
+			expected: true,
 		},
 		{
 			name: "MalformedConnectionString",
 			env: map[string]string{
-				"AZURE_STORAGE_CONNECTION_STRING": fmt.Sprintf("AccountNameaccount;AccountKey=%s", enc("secret")),
+				"AZURE_STORAGE_CONNECTION_STRING": "AccountName" + devStoreAccountName + ";AccountKey=" + devStoreAccountKey,
 			},
+			expectedError: objerr.ErrNoCredentialsFound,
 		},
 		{
 			name: "NoValidCredentials",
 		},
 	}
 
+	// getStaticServiceClient resolves the static credentials in the same order as 'GetServiceClient', without falling
+	// back to the token credential chain.
+	getStaticServiceClient := func(options CredentialsOptions) (*azblob.ServiceClient, error) {
+		for _, credentials := range []staticCredentials{explicitCredentials(options), envCredentials()} {
+			client, err := getServiceClientWithStaticCredentials(options, credentials)
+			if err != nil || client != nil {
+				return client, err
+			}
+		}
+
+		return nil, nil
+	}
+
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			clearAzureEnv(t)
+
 			for key, val := range test.env {
-				err := os.Setenv(key, val)
-				require.NoError(t, err)
-				defer os.Unsetenv(key)
+				t.Setenv(key, val)
+			}
+
+			azurite := newTestAzurite(t, http.StatusOK)
+
+			serviceClient, err := getStaticServiceClient(CredentialsOptions{
+				Endpoint:    azurite.endpoint(),
+				AccountName: test.accessKeyID,
+				AccountKey:  test.secretAccessKey,
+			})
+
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
 			}
 
-			actual, err := getStaticCredentials(test.accessKeyID, test.secretAccessKey)
 			require.NoError(t, err)
-			require.Equal(t, test.expected, actual)
+
+			if !test.expected {
+				require.Nil(t, serviceClient)
+				return
+			}
+
+			require.NotNil(t, serviceClient)
+
+			_, err = NewClient(serviceClient).GetObjectAttrs(context.Background(), "container", "blob")
+			require.NoError(t, err)
+
+			// The test server only accepts a shared key for the development storage account
+			require.Equal(t, []string{"SharedKey"}, azurite.auth)
 		})
 	}
 }
@@ -2,3 +2,9 @@ package objazure
 
 // PageSize is the default page size used by Azure.
 const PageSize = 5000
+
+// MaxAppendBlockSize is the maximum size of a single block which may be appended to an append blob.
+const MaxAppendBlockSize = 4 * 1024 * 1024
+
+// MaxAppendBlocks is the maximum number of blocks which may be appended to an append blob.
+const MaxAppendBlocks = 50_000
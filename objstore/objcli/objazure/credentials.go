@@ -0,0 +1,366 @@
+package objazure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+
+	"github.com/couchbase/tools-common/objstore/objerr"
+)
+
+const (
+	azureDefaultEndpointsProtocol = "defaultendpointsprotocol"
+	azureSharedAccessSignature    = "sharedaccesssignature"
+	azureUseDevelopmentStorage    = "usedevelopmentstorage"
+	azureDevelopmentStorageProxy  = "developmentstorageproxyuri"
+)
+
+const (
+	// devStoreAccountName is the well-known account name used by the Azure storage emulators e.g. Azurite.
+	devStoreAccountName = "devstoreaccount1"
+
+	// devStoreAccountKey is the well-known account key used by the Azure storage emulators e.g. Azurite.
+	devStoreAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+	// devStoreBlobEndpoint is the default blob endpoint used by the Azure storage emulators e.g. Azurite.
+	devStoreBlobEndpoint = "http://127.0.0.1:10000"
+)
+
+// CredentialsOptions encapsulates the options available when creating a client using 'NewClientFromCredentials'.
+//
+// NOTE: Credentials are resolved in the following order, the first source which provides credentials is used:
+//  1. The explicitly provided shared key, SAS token or connection string (in that order)
+//  2. The 'AZURE_STORAGE_ACCOUNT'/'AZURE_STORAGE_KEY', 'AZURE_STORAGE_SAS_TOKEN' or 'AZURE_STORAGE_CONNECTION_STRING'
+//     environment variables (in that order)
+//  3. The user assigned managed identity (when 'ManagedIdentityClientID' is provided), otherwise the default azidentity
+//     credential chain (which includes the system assigned managed identity)
+type CredentialsOptions struct {
+	// Endpoint is the blob service endpoint, when not provided this will be determined using the account name; this
+	// should be provided when using a storage emulator such as Azurite.
+	Endpoint string
+
+	// AccountName/AccountKey are the account name and shared key used to authenticate.
+	AccountName, AccountKey string
+
+	// SASToken is a shared access signature which will be used to authenticate, the account name (or endpoint) must
+	// also be provided.
+	SASToken string
+
+	// ConnectionString is an Azure style connection string, the endpoint and credentials will be determined using the
+	// connection string.
+	ConnectionString string
+
+	// ManagedIdentityClientID is the client id of a user assigned managed identity, when provided, it's used in place
+	// of the default azidentity credential chain.
+	ManagedIdentityClientID string
+
+	// SDKOptions are the options passed to the Azure SDK when creating the service client.
+	SDKOptions *azblob.ClientOptions
+
+	// ClientOptions are the options used when creating the client.
+	ClientOptions ClientOptions
+}
+
+// NewClientFromCredentials returns a new client which will authenticate using the first set of credentials which are
+// found using the given options/environment.
+func NewClientFromCredentials(options CredentialsOptions) (*Client, error) {
+	client, err := NewServiceClientFromCredentials(options)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	return NewClientWithOptions(client, options.ClientOptions), nil
+}
+
+// NewServiceClientFromCredentials returns a new service client which will authenticate using the first set of
+// credentials which are found using the given options/environment.
+func NewServiceClientFromCredentials(options CredentialsOptions) (*azblob.ServiceClient, error) {
+	// Explicitly provided credentials always take priority over any credentials found in the environment
+	for _, credentials := range []staticCredentials{explicitCredentials(options), envCredentials()} {
+		client, err := getServiceClientWithStaticCredentials(options, credentials)
+		if err != nil || client != nil {
+			return client, err
+		}
+	}
+
+	client, err := getServiceClientWithTokenCredentialChain(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service client with token credential: %w", err)
+	}
+
+	return client, nil
+}
+
+// staticCredentials are the static credentials provided by a single source, either the explicitly provided options or
+// the environment.
+type staticCredentials struct {
+	accountName, accountKey, sasToken, connectionString string
+}
+
+// explicitCredentials returns the static credentials explicitly provided in the given options.
+func explicitCredentials(options CredentialsOptions) staticCredentials {
+	return staticCredentials{
+		accountName:      options.AccountName,
+		accountKey:       options.AccountKey,
+		sasToken:         options.SASToken,
+		connectionString: options.ConnectionString,
+	}
+}
+
+// envCredentials returns the static credentials provided via the environment.
+func envCredentials() staticCredentials {
+	return staticCredentials{
+		accountName:      os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		accountKey:       os.Getenv("AZURE_STORAGE_KEY"),
+		sasToken:         os.Getenv("AZURE_STORAGE_SAS_TOKEN"),
+		connectionString: os.Getenv("AZURE_STORAGE_CONNECTION_STRING"),
+	}
+}
+
+// getServiceClientWithStaticCredentials attempts to create a service client using the given static credentials,
+// returns <nil>, <nil> in the event that they don't contain a shared key, SAS token or connection string.
+func getServiceClientWithStaticCredentials(options CredentialsOptions,
+	credentials staticCredentials,
+) (*azblob.ServiceClient, error) {
+	if credentials.accountName != "" && credentials.accountKey != "" {
+		return getServiceClientWithSharedKey(options, credentials.accountName, credentials.accountKey)
+	}
+
+	if credentials.sasToken != "" {
+		return getServiceClientWithSASToken(options, credentials.sasToken)
+	}
+
+	if credentials.connectionString != "" {
+		return getServiceClientWithConnectionString(options, credentials.connectionString)
+	}
+
+	return nil, nil
+}
+
+// getServiceClientWithSharedKey creates a service client which authenticates using the given shared key.
+func getServiceClientWithSharedKey(options CredentialsOptions, name, key string) (*azblob.ServiceClient, error) {
+	credentials, err := azblob.NewSharedKeyCredential(name, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shared key credential: %w", handleCredsError(err))
+	}
+
+	serviceURL, err := getServiceURL(options.Endpoint, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service URL: %w", err)
+	}
+
+	return azblob.NewServiceClientWithSharedKey(serviceURL, credentials, options.SDKOptions)
+}
+
+// getServiceClientWithSASToken creates a service client which authenticates using the given SAS token.
+func getServiceClientWithSASToken(options CredentialsOptions, token string) (*azblob.ServiceClient, error) {
+	serviceURL, err := getServiceURL(options.Endpoint, options.AccountName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service URL: %w", err)
+	}
+
+	return azblob.NewServiceClientWithNoCredential(withSASToken(serviceURL, token), options.SDKOptions)
+}
+
+// getServiceClientWithConnectionString creates a service client using the endpoint and credentials from the given
+// connection string.
+func getServiceClientWithConnectionString(options CredentialsOptions, cs string) (*azblob.ServiceClient, error) {
+	values := parseConnectionString(cs)
+
+	// The storage emulator uses well-known credentials, and listens on a well-known endpoint
+	if strings.EqualFold(values[azureUseDevelopmentStorage], "true") {
+		values = devStoreConnectionStringValues(values)
+	}
+
+	serviceURL := values[azureBlobEndpoint]
+	if options.Endpoint != "" {
+		serviceURL = options.Endpoint
+	}
+
+	if serviceURL == "" && values[azureAccountName] == "" {
+		return nil, fmt.Errorf("failed to get service URL: %w", ErrFailedToDetermineAccountName)
+	}
+
+	if serviceURL == "" {
+		serviceURL = connectionStringServiceURL(values)
+	}
+
+	if values[azureAccountName] != "" && values[azureAccountKey] != "" {
+		credentials, err := azblob.NewSharedKeyCredential(values[azureAccountName], values[azureAccountKey])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create shared key credential: %w", handleCredsError(err))
+		}
+
+		return azblob.NewServiceClientWithSharedKey(serviceURL, credentials, options.SDKOptions)
+	}
+
+	if values[azureSharedAccessSignature] != "" {
+		return azblob.NewServiceClientWithNoCredential(
+			withSASToken(serviceURL, values[azureSharedAccessSignature]),
+			options.SDKOptions,
+		)
+	}
+
+	return nil, objerr.ErrNoCredentialsFound
+}
+
+// getServiceClientWithTokenCredentialChain attempts to create a service client using the default azidentity credential
+// chain, or the user assigned managed identity when one has been provided.
+func getServiceClientWithTokenCredentialChain(options CredentialsOptions) (*azblob.ServiceClient, error) {
+	serviceURL, err := getServiceURL(options.Endpoint, options.AccountName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service URL: %w", err)
+	}
+
+	credential, err := getTokenCredential(options)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	return azblob.NewServiceClient(serviceURL, tokenCredential{credential: credential}, options.SDKOptions)
+}
+
+// getTokenCredential returns the token credential which should be used to authenticate.
+//
+// NOTE: The default azidentity credential chain already contains a managed identity credential which would take
+// priority over (and therefore shadow) a user assigned managed identity, so when one is provided, it's used exclusively.
+func getTokenCredential(options CredentialsOptions) (azcore.TokenCredential, error) {
+	var (
+		credential azcore.TokenCredential
+		err        error
+	)
+
+	if options.ManagedIdentityClientID != "" {
+		credential, err = azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
+			ID: azidentity.ClientID(options.ManagedIdentityClientID),
+		})
+	} else {
+		credential, err = azidentity.NewDefaultAzureCredential(nil)
+	}
+
+	// We return this error here because this is the last source of credentials that we check
+	if err != nil {
+		return nil, objerr.ErrNoCredentialsFound
+	}
+
+	return credential, nil
+}
+
+// parseConnectionString parses an Azure style connection string returning a map of the key value pairs, where the keys
+// have been lowercased.
+func parseConnectionString(cs string) map[string]string {
+	values := make(map[string]string)
+
+	for _, kv := range strings.Split(cs, ";") {
+		idx := strings.IndexByte(kv, '=')
+		if idx <= 0 {
+			continue
+		}
+
+		values[strings.TrimSpace(strings.ToLower(kv[:idx]))] = strings.TrimSpace(kv[idx+1:])
+	}
+
+	return values
+}
+
+// devStoreConnectionStringValues returns the connection string values which should be used when connecting to a local
+// storage emulator.
+func devStoreConnectionStringValues(values map[string]string) map[string]string {
+	endpoint := devStoreBlobEndpoint
+	if values[azureDevelopmentStorageProxy] != "" {
+		endpoint = strings.TrimSuffix(values[azureDevelopmentStorageProxy], "/")
+	}
+
+	return map[string]string{
+		azureAccountName:  devStoreAccountName,
+		azureAccountKey:   devStoreAccountKey,
+		azureBlobEndpoint: fmt.Sprintf("%s/%s", endpoint, devStoreAccountName),
+	}
+}
+
+// connectionStringServiceURL returns the service URL constructed using the protocol, account name and suffix from the
+// given connection string values.
+func connectionStringServiceURL(values map[string]string) string {
+	protocol := "https"
+	if values[azureDefaultEndpointsProtocol] != "" {
+		protocol = values[azureDefaultEndpointsProtocol]
+	}
+
+	suffix := "core.windows.net"
+	if values[azureEndpointSuffix] != "" {
+		suffix = values[azureEndpointSuffix]
+	}
+
+	return fmt.Sprintf("%s://%s.blob.%s", protocol, values[azureAccountName], suffix)
+}
+
+// withSASToken returns the given service URL with the SAS token appended as the query string.
+func withSASToken(serviceURL, token string) string {
+	return fmt.Sprintf("%s/?%s", strings.TrimSuffix(serviceURL, "/"), strings.TrimPrefix(token, "?"))
+}
+
+// tokenCredential wraps an Azure token credential, converting any errors into user friendly errors.
+type tokenCredential struct {
+	credential azcore.TokenCredential
+}
+
+func (t tokenCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	token, err := t.credential.GetToken(ctx, options)
+	if err != nil {
+		return azcore.AccessToken{}, handleTokenError(err)
+	}
+
+	return token, nil
+}
+
+// credentialError is returned when we fail to get a token, it's marked as non-retriable to ensure that the Azure SDK
+// fails fast, rather than retrying a request which will never succeed.
+type credentialError struct {
+	err error
+}
+
+func (c *credentialError) Error() string {
+	return c.err.Error()
+}
+
+func (c *credentialError) Unwrap() error {
+	return c.err
+}
+
+// NonRetriable implements the 'errorinfo.NonRetriable' interface.
+func (c *credentialError) NonRetriable() {}
+
+// nonRetriable is implemented by the azidentity errors which indicate that retrying won't succeed.
+type nonRetriable interface {
+	NonRetriable()
+}
+
+// handleTokenError converts the given error (returned whilst fetching a token) into a user friendly error, where
+// possible. Other errors e.g. context cancellation or network errors are returned as is, so they may be retried.
+func handleTokenError(err error) error {
+	var authErr *azidentity.AuthenticationFailedError
+	if errors.As(err, &authErr) {
+		if authErr.RawResponse != nil && authErr.RawResponse.StatusCode == http.StatusForbidden {
+			return &credentialError{err: objerr.ErrUnauthorized}
+		}
+
+		return &credentialError{err: objerr.ErrUnauthenticated}
+	}
+
+	// azidentity doesn't export the error returned when a credential is unavailable (e.g. because the required
+	// environment variables aren't set), however, it's the only other error which is marked as non-retriable
+	var unavailable nonRetriable
+	if errors.As(err, &unavailable) {
+		return &credentialError{err: objerr.ErrNoCredentialsFound}
+	}
+
+	return err // Purposefully not wrapped
+}
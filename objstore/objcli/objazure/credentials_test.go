@@ -0,0 +1,471 @@
+package objazure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/objstore/objerr"
+)
+
+// testAzurite is a minimal Azurite-compatible server which records the method used to authenticate each request.
+type testAzurite struct {
+	server *httptest.Server
+	status int
+	auth   []string
+}
+
+// newTestAzurite creates a new test server, which will respond to requests with the given status code.
+func newTestAzurite(t *testing.T, status int) *testAzurite {
+	azurite := &testAzurite{status: status}
+
+	azurite.server = httptest.NewServer(http.HandlerFunc(azurite.handle))
+	t.Cleanup(azurite.server.Close)
+
+	return azurite
+}
+
+func (a *testAzurite) handle(writer http.ResponseWriter, request *http.Request) {
+	authorization := request.Header.Get("Authorization")
+
+	switch {
+	case strings.HasPrefix(authorization, "SharedKey "+devStoreAccountName+":"):
+		a.auth = append(a.auth, "SharedKey")
+	case strings.HasPrefix(authorization, "Bearer "):
+		a.auth = append(a.auth, "Bearer")
+	case request.URL.Query().Get("sig") != "":
+		a.auth = append(a.auth, "SAS")
+	default:
+		a.auth = append(a.auth, "None")
+	}
+
+	if a.status != http.StatusOK {
+		writer.Header().Set("x-ms-error-code", "AuthenticationFailed")
+		writer.WriteHeader(a.status)
+
+		return
+	}
+
+	writer.Header().Set("Content-Length", "5")
+	writer.Header().Set("ETag", "etag")
+	writer.Header().Set("Last-Modified", time.Time{}.Format(http.TimeFormat))
+	writer.Header().Set("x-ms-blob-type", string(azblob.BlobTypeBlockBlob))
+	writer.WriteHeader(http.StatusOK)
+}
+
+// endpoint returns the path style endpoint for the development storage account.
+func (a *testAzurite) endpoint() string {
+	return fmt.Sprintf("%s/%s", a.server.URL, devStoreAccountName)
+}
+
+// clearAzureEnv ensures that credentials from the environment are not used by tests.
+func clearAzureEnv(t *testing.T) {
+	for _, key := range []string{
+		"AZURE_STORAGE_ACCOUNT",
+		"AZURE_STORAGE_KEY",
+		"AZURE_STORAGE_SAS_TOKEN",
+		"AZURE_STORAGE_CONNECTION_STRING",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestNewClientFromCredentials(t *testing.T) {
+	type test struct {
+		name     string
+		options  func(azurite *testAzurite) CredentialsOptions
+		env      func(azurite *testAzurite) map[string]string
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name: "SharedKey",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{
+					Endpoint:    azurite.endpoint(),
+					AccountName: devStoreAccountName,
+					AccountKey:  devStoreAccountKey,
+					// The shared key should take priority
+					SASToken: "sv=2020-08-04&sig=signature",
+				}
+			},
+			expected: "SharedKey",
+		},
+		{
+			name: "SharedKeyViaEnv",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{Endpoint: azurite.endpoint()}
+			},
+			env: func(azurite *testAzurite) map[string]string {
+				return map[string]string{
+					"AZURE_STORAGE_ACCOUNT": devStoreAccountName,
+					"AZURE_STORAGE_KEY":     devStoreAccountKey,
+				}
+			},
+			expected: "SharedKey",
+		},
+		{
+			name: "SASToken",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{
+					Endpoint: azurite.endpoint(),
+					SASToken: "?sv=2020-08-04&sig=signature",
+					// The SAS token should take priority
+					ConnectionString: "UseDevelopmentStorage=true",
+				}
+			},
+			expected: "SAS",
+		},
+		{
+			name: "SASTokenViaEnv",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{Endpoint: azurite.endpoint()}
+			},
+			env: func(azurite *testAzurite) map[string]string {
+				return map[string]string{"AZURE_STORAGE_SAS_TOKEN": "sv=2020-08-04&sig=signature"}
+			},
+			expected: "SAS",
+		},
+		{
+			name: "ConnectionStringWithSharedKey",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{
+					ConnectionString: fmt.Sprintf(
+						"DefaultEndpointsProtocol=http;AccountName=%s;AccountKey=%s;BlobEndpoint=%s;",
+						devStoreAccountName,
+						devStoreAccountKey,
+						azurite.endpoint(),
+					),
+				}
+			},
+			expected: "SharedKey",
+		},
+		{
+			name: "ConnectionStringWithSASToken",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{
+					ConnectionString: fmt.Sprintf(
+						"BlobEndpoint=%s;SharedAccessSignature=sv=2020-08-04&sig=signature",
+						azurite.endpoint(),
+					),
+				}
+			},
+			expected: "SAS",
+		},
+		{
+			name: "ConnectionStringViaEnv",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{}
+			},
+			env: func(azurite *testAzurite) map[string]string {
+				return map[string]string{
+					"AZURE_STORAGE_CONNECTION_STRING": fmt.Sprintf(
+						"AccountName=%s;AccountKey=%s;BlobEndpoint=%s",
+						devStoreAccountName,
+						devStoreAccountKey,
+						azurite.endpoint(),
+					),
+				}
+			},
+			expected: "SharedKey",
+		},
+		{
+			name: "SASTokenTakesPriorityOverEnv",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{Endpoint: azurite.endpoint(), SASToken: "sv=2020-08-04&sig=signature"}
+			},
+			env: func(azurite *testAzurite) map[string]string {
+				return map[string]string{
+					"AZURE_STORAGE_ACCOUNT": devStoreAccountName,
+					"AZURE_STORAGE_KEY":     devStoreAccountKey,
+				}
+			},
+			expected: "SAS",
+		},
+		{
+			name: "ConnectionStringTakesPriorityOverEnv",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{
+					ConnectionString: fmt.Sprintf(
+						"BlobEndpoint=%s;SharedAccessSignature=sv=2020-08-04&sig=signature",
+						azurite.endpoint(),
+					),
+				}
+			},
+			env: func(azurite *testAzurite) map[string]string {
+				return map[string]string{
+					"AZURE_STORAGE_ACCOUNT": devStoreAccountName,
+					"AZURE_STORAGE_KEY":     devStoreAccountKey,
+				}
+			},
+			expected: "SAS",
+		},
+		{
+			name: "SharedKeyMustSupplyBoth",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{Endpoint: azurite.endpoint(), AccountName: devStoreAccountName}
+			},
+			env: func(azurite *testAzurite) map[string]string {
+				return map[string]string{"AZURE_STORAGE_SAS_TOKEN": "sv=2020-08-04&sig=signature"}
+			},
+			expected: "SAS",
+		},
+		{
+			name: "SharedKeyViaEnvTakesPriorityOverConnectionString",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{Endpoint: azurite.endpoint()}
+			},
+			env: func(azurite *testAzurite) map[string]string {
+				return map[string]string{
+					"AZURE_STORAGE_ACCOUNT": devStoreAccountName,
+					"AZURE_STORAGE_KEY":     devStoreAccountKey,
+					"AZURE_STORAGE_CONNECTION_STRING": fmt.Sprintf(
+						"BlobEndpoint=%s;SharedAccessSignature=sv=2020-08-04&sig=signature",
+						azurite.endpoint(),
+					),
+				}
+			},
+			expected: "SharedKey",
+		},
+		{
+			name: "DevelopmentStorage",
+			options: func(azurite *testAzurite) CredentialsOptions {
+				return CredentialsOptions{
+					ConnectionString: fmt.Sprintf(
+						"UseDevelopmentStorage=true;DevelopmentStorageProxyUri=%s",
+						azurite.server.URL,
+					),
+				}
+			},
+			expected: "SharedKey",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearAzureEnv(t)
+
+			azurite := newTestAzurite(t, http.StatusOK)
+
+			if test.env != nil {
+				for key, val := range test.env(azurite) {
+					t.Setenv(key, val)
+				}
+			}
+
+			client, err := NewClientFromCredentials(test.options(azurite))
+			require.NoError(t, err)
+
+			attrs, err := client.GetObjectAttrs(context.Background(), "container", "blob")
+			require.NoError(t, err)
+			require.Equal(t, int64(5), attrs.Size)
+			require.Equal(t, []string{test.expected}, azurite.auth)
+		})
+	}
+}
+
+func TestNewClientFromCredentialsWithClientOptions(t *testing.T) {
+	clearAzureEnv(t)
+
+	client, err := NewClientFromCredentials(CredentialsOptions{
+		ConnectionString: "UseDevelopmentStorage=true",
+		ClientOptions:    ClientOptions{AppendBlobs: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ClientOptions{AppendBlobs: true}, client.options)
+}
+
+func TestNewClientFromCredentialsConnectionStringWithoutCredentials(t *testing.T) {
+	clearAzureEnv(t)
+
+	_, err := NewClientFromCredentials(CredentialsOptions{ConnectionString: "BlobEndpoint=http://localhost:10000"})
+	require.ErrorIs(t, err, objerr.ErrNoCredentialsFound)
+}
+
+func TestNewClientFromCredentialsConnectionStringWithoutAccount(t *testing.T) {
+	clearAzureEnv(t)
+
+	_, err := NewClientFromCredentials(CredentialsOptions{ConnectionString: "AccountKey=key"})
+	require.ErrorIs(t, err, ErrFailedToDetermineAccountName)
+}
+
+func TestGetTokenCredential(t *testing.T) {
+	credential, err := getTokenCredential(CredentialsOptions{})
+	require.NoError(t, err)
+	require.IsType(t, &azidentity.DefaultAzureCredential{}, credential)
+
+	credential, err = getTokenCredential(CredentialsOptions{ManagedIdentityClientID: "id"})
+	require.NoError(t, err)
+	require.IsType(t, &azidentity.ManagedIdentityCredential{}, credential)
+}
+
+func TestNewClientFromCredentialsErrors(t *testing.T) {
+	type test struct {
+		name     string
+		status   int
+		expected error
+	}
+
+	tests := []*test{
+		{
+			name:     "Unauthenticated",
+			status:   http.StatusUnauthorized,
+			expected: objerr.ErrUnauthenticated,
+		},
+		{
+			name:     "Unauthorized",
+			status:   http.StatusForbidden,
+			expected: objerr.ErrUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearAzureEnv(t)
+
+			azurite := newTestAzurite(t, test.status)
+
+			client, err := NewClientFromCredentials(CredentialsOptions{
+				Endpoint:    azurite.endpoint(),
+				AccountName: devStoreAccountName,
+				AccountKey:  devStoreAccountKey,
+			})
+			require.NoError(t, err)
+
+			_, err = client.GetObjectAttrs(context.Background(), "container", "blob")
+			require.ErrorIs(t, err, test.expected)
+		})
+	}
+}
+
+// testTokenCredential is a token credential which returns the given error, or a static token.
+type testTokenCredential struct {
+	err error
+}
+
+func (t testTokenCredential) GetToken(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if t.err != nil {
+		return azcore.AccessToken{}, t.err
+	}
+
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// testCredentialUnavailableError mimics the (unexported) error returned by azidentity when a credential is unavailable.
+type testCredentialUnavailableError struct{}
+
+func (t testCredentialUnavailableError) Error() string {
+	return "credential unavailable"
+}
+
+func (t testCredentialUnavailableError) NonRetriable() {}
+
+func TestTokenCredential(t *testing.T) {
+	type test struct {
+		name     string
+		err      error
+		expected error
+	}
+
+	tests := []*test{
+		{
+			name: "Success",
+		},
+		{
+			name:     "CredentialUnavailable",
+			err:      testCredentialUnavailableError{},
+			expected: objerr.ErrNoCredentialsFound,
+		},
+		{
+			name:     "AuthenticationFailed",
+			err:      &azidentity.AuthenticationFailedError{},
+			expected: objerr.ErrUnauthenticated,
+		},
+		{
+			name: "AuthenticationFailedForbidden",
+			err: &azidentity.AuthenticationFailedError{
+				RawResponse: &http.Response{StatusCode: http.StatusForbidden},
+			},
+			expected: objerr.ErrUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			azurite := newTestAzurite(t, http.StatusOK)
+
+			serviceClient, err := azblob.NewServiceClient(
+				azurite.endpoint(),
+				tokenCredential{credential: testTokenCredential{err: test.err}},
+				nil,
+			)
+			require.NoError(t, err)
+
+			_, err = NewClient(serviceClient).GetObjectAttrs(context.Background(), "container", "blob")
+
+			if test.expected != nil {
+				require.ErrorIs(t, err, test.expected)
+				require.Empty(t, azurite.auth)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, []string{"Bearer"}, azurite.auth)
+		})
+	}
+}
+
+func TestHandleTokenError(t *testing.T) {
+	type test struct {
+		name     string
+		err      error
+		expected error
+	}
+
+	tests := []*test{
+		{
+			name:     "CredentialUnavailable",
+			err:      testCredentialUnavailableError{},
+			expected: objerr.ErrNoCredentialsFound,
+		},
+		{
+			name:     "AuthenticationFailed",
+			err:      &azidentity.AuthenticationFailedError{},
+			expected: objerr.ErrUnauthenticated,
+		},
+		{
+			name:     "ContextCancelled",
+			err:      context.Canceled,
+			expected: context.Canceled,
+		},
+		{
+			name:     "OtherError",
+			err:      assert.AnError,
+			expected: assert.AnError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := handleTokenError(test.err)
+			require.ErrorIs(t, err, test.expected)
+
+			if test.err == test.expected {
+				require.Equal(t, test.err, err)
+			}
+		})
+	}
+}
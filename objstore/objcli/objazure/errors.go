@@ -2,6 +2,12 @@ package objazure
 
 import "errors"
 
-// ErrFailedToDetermineAccountName is returned in the event that we fail to determine the Azure account name using both
-// the static credentials or the environment.
-var ErrFailedToDetermineAccountName = errors.New("failed to determine account name")
+var (
+	// ErrFailedToDetermineAccountName is returned in the event that we fail to determine the Azure account name using
+	// both the static credentials or the environment.
+	ErrFailedToDetermineAccountName = errors.New("failed to determine account name")
+
+	// ErrAppendBlobBlockLimit is returned when appending to an append blob would exceed the maximum number of blocks
+	// (see 'MaxAppendBlocks'); no more data may be appended to the blob, it must be rewritten, or a new blob used.
+	ErrAppendBlobBlockLimit = errors.New("append blob has reached the maximum number of blocks")
+)
@@ -23,6 +23,29 @@ func (_m *mockBlobStorageAPI) CanGetSASToken() bool {
 	return r0
 }
 
+// ToAppendBlobAPI provides a mock function with given fields: container, blob
+func (_m *mockBlobStorageAPI) ToAppendBlobAPI(container, blob string) (appendBlobAPI, error) {
+	ret := _m.Called(container, blob)
+
+	var r0 appendBlobAPI
+	if rf, ok := ret.Get(0).(func(string, string) appendBlobAPI); ok {
+		r0 = rf(container, blob)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(appendBlobAPI)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(container, blob)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ToBlobAPI provides a mock function with given fields: container, blob
 func (_m *mockBlobStorageAPI) ToBlobAPI(container, blob string) (blobAPI, error) {
 	ret := _m.Called(container, blob)
@@ -44,6 +44,29 @@ func (_m *mockContainerAPI) GetListBlobsHierarchyPagerAPI(delimiter string, opti
 	return r0
 }
 
+// ToAppendBlobAPI provides a mock function with given fields: blob
+func (_m *mockContainerAPI) ToAppendBlobAPI(blob string) (appendBlobAPI, error) {
+	ret := _m.Called(blob)
+
+	var r0 appendBlobAPI
+	if rf, ok := ret.Get(0).(func(string) appendBlobAPI); ok {
+		r0 = rf(blob)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(appendBlobAPI)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(blob)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ToBlobAPI provides a mock function with given fields: blob
 func (_m *mockContainerAPI) ToBlobAPI(blob string) (blobAPI, error) {
 	ret := _m.Called(blob)
@@ -11,6 +11,13 @@ import (
 
 // handleError converts an error relating accessing an object via its key into a user friendly error where possible.
 func handleError(bucket, key string, err error) error {
+	// The Azure SDK wraps errors in a way which only supports 'errors.As', unwrap any errors returned whilst fetching a
+	// token so that they may be checked using 'errors.Is'.
+	var credsErr *credentialError
+	if errors.As(err, &credsErr) {
+		return credsErr.err
+	}
+
 	var azureErr *azblob.StorageError
 	if err == nil || !errors.As(err, &azureErr) {
 		return objerr.HandleError(err)
@@ -52,6 +52,7 @@ type objectAPI interface {
 	ComposerFrom(srcs ...objectAPI) composeAPI
 	CopierFrom(src objectAPI) copierAPI
 	Retryer(opts ...storage.RetryOption) objectAPI
+	If(conds storage.Conditions) objectAPI
 }
 
 // objectHandle implements the 'objectAPI' interface and encapsulates the Google Storage SDK into a unit testable
@@ -116,6 +117,10 @@ func (o objectHandle) Retryer(opts ...storage.RetryOption) objectAPI {
 	return objectHandle{h: o.h.Retryer(opts...)}
 }
 
+func (o objectHandle) If(conds storage.Conditions) objectAPI {
+	return objectHandle{h: o.h.If(conds)}
+}
+
 // readerAPI is a range aware reader API which is used to stream object data from Google Storage.
 type readerAPI interface {
 	io.ReadCloser
@@ -119,10 +119,11 @@ func (c *Client) PutObject(ctx context.Context, bucket, key string, body io.Read
 }
 
 func (c *Client) AppendToObject(ctx context.Context, bucket, key string, data io.ReadSeeker) error {
-	attrs, err := c.GetObjectAttrs(ctx, bucket, key)
+	attrs, err := c.serviceAPI.Bucket(bucket).Object(key).Attrs(ctx)
+	err = handleError(bucket, key, err)
 
 	// As defined by the 'Client' interface, if the given object does not exist, we create it
-	if objerr.IsNotFoundError(err) || attrs.Size == 0 {
+	if objerr.IsNotFoundError(err) || err == nil && attrs.Size == 0 {
 		return c.PutObject(ctx, bucket, key, data)
 	}
 
@@ -130,26 +131,43 @@ func (c *Client) AppendToObject(ctx context.Context, bucket, key string, data io
 		return fmt.Errorf("failed to get object attributes: %w", err)
 	}
 
-	id, err := c.CreateMultipartUpload(ctx, bucket, key)
+	length, err := aws.SeekerLen(data)
 	if err != nil {
-		return fmt.Errorf("failed to start multipart upload: %w", err)
+		return fmt.Errorf("failed to determine body length: %w", err)
 	}
 
-	intermediate, err := c.UploadPart(ctx, bucket, id, key, 2, data)
+	// The data is uploaded to a temporary object which is then composed with the existing object, this means we never
+	// need to download/copy the existing object.
+	intermediate := partKey(uuid.NewString(), key)
+	defer c.cleanup(ctx, bucket, intermediate)
+
+	err = c.PutObject(ctx, bucket, intermediate, data)
 	if err != nil {
-		return fmt.Errorf("failed to upload part: %w", err)
+		return fmt.Errorf("failed to upload temporary object: %w", err)
 	}
 
-	part := objval.Part{ID: key, Number: 1, Size: attrs.Size}
+	// The existing object is also a source, only compose the generation we've observed, so that a retried request (whose
+	// response was lost) can't append the data twice.
+	err = c.compose(ctx, bucket, key, &storage.Conditions{GenerationMatch: attrs.Generation}, key, intermediate)
+
+	// The precondition will fail for a retried request which was successful, in which case the data has been appended
+	if isPreconditionFailed(err) && c.hasSize(ctx, bucket, key, attrs.Size+length) {
+		return nil
+	}
 
-	err = c.CompleteMultipartUpload(ctx, bucket, id, key, part, intermediate)
 	if err != nil {
-		return fmt.Errorf("failed to complete multipart upload: %w", err)
+		return fmt.Errorf("failed to compose object: %w", err)
 	}
 
 	return nil
 }
 
+// hasSize returns a boolean indicating whether the given object exists and is of the given size.
+func (c *Client) hasSize(ctx context.Context, bucket, key string, size int64) bool {
+	attrs, err := c.GetObjectAttrs(ctx, bucket, key)
+	return err == nil && attrs.Size == size
+}
+
 func (c *Client) DeleteObjects(ctx context.Context, bucket string, keys ...string) error {
 	pool := hofp.NewPool(hofp.Options{
 		Context:   ctx,
@@ -366,13 +384,13 @@ func (c *Client) CompleteMultipartUpload(ctx context.Context, bucket, id, key st
 // complete recursively composes the object in chunks of 32 eventually resulting in a single complete object.
 func (c *Client) complete(ctx context.Context, bucket, key string, parts ...string) error {
 	if len(parts) <= MaxComposable {
-		return c.compose(ctx, bucket, key, parts...)
+		return c.compose(ctx, bucket, key, nil, parts...)
 	}
 
 	intermediate := partKey(uuid.NewString(), key)
 	defer c.cleanup(ctx, bucket, intermediate)
 
-	err := c.compose(ctx, bucket, intermediate, parts[:MaxComposable]...)
+	err := c.compose(ctx, bucket, intermediate, nil, parts[:MaxComposable]...)
 	if err != nil {
 		return err
 	}
@@ -380,20 +398,26 @@ func (c *Client) complete(ctx context.Context, bucket, key string, parts ...stri
 	return c.complete(ctx, bucket, key, append([]string{intermediate}, parts[MaxComposable:]...)...)
 }
 
-// compose the given parts into a single object.
-func (c *Client) compose(ctx context.Context, bucket, key string, parts ...string) error {
+// compose the given parts into a single object, the given conditions (if any) are applied to the destination object.
+//
+// NOTE: Where the destination object is also one of the parts, conditions must be provided to ensure that a retried
+// request doesn't compose the destination object into itself multiple times.
+func (c *Client) compose(ctx context.Context, bucket, key string, conds *storage.Conditions, parts ...string) error {
 	handles := make([]objectAPI, 0, len(parts))
 
 	for _, part := range parts {
 		handles = append(handles, c.serviceAPI.Bucket(bucket).Object(part))
 	}
 
-	var (
-		// Object composition is non-destructive from the source perspective and we don't mind potentially "overwriting"
-		// the destination object, always retry.
-		dst    = c.serviceAPI.Bucket(bucket).Object(key).Retryer(storage.WithPolicy(storage.RetryAlways))
-		_, err = dst.ComposerFrom(handles...).Run(ctx)
-	)
+	// Object composition is non-destructive from the source perspective and we don't mind potentially "overwriting" the
+	// destination object, always retry.
+	dst := c.serviceAPI.Bucket(bucket).Object(key).Retryer(storage.WithPolicy(storage.RetryAlways))
+
+	if conds != nil {
+		dst = dst.If(*conds)
+	}
+
+	_, err := dst.ComposerFrom(handles...).Run(ctx)
 
 	return handleError(bucket, key, err)
 }
@@ -6,6 +6,8 @@ import (
 	"crypto/md5"
 	"fmt"
 	"hash/crc32"
+	"io"
+	"net/http"
 	"reflect"
 	"regexp"
 	"strings"
@@ -17,6 +19,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 
 	"github.com/couchbase/tools-common/objstore/objcli"
@@ -353,9 +356,13 @@ func TestClientAppendToObjectUsingObjectComposition(t *testing.T) {
 		return reflect.DeepEqual(option, storage.WithPolicy(storage.RetryAlways))
 	})).Return(moAPI)
 
-	moAPI.On("Attrs", mock.Anything).Return(&storage.ObjectAttrs{Size: 5}, nil)
+	moAPI.On("Attrs", mock.Anything).Return(&storage.ObjectAttrs{Size: 5, Generation: 42}, nil)
 	moAPI.On("NewWriter", mock.Anything).Return(mwAPI, nil)
 
+	moAPI.On("If", mock.MatchedBy(func(conds storage.Conditions) bool {
+		return conds == storage.Conditions{GenerationMatch: 42}
+	})).Return(moAPI)
+
 	mwAPI.On("SendMD5", mock.Anything)
 	mwAPI.On("SendCRC", mock.Anything)
 
@@ -386,6 +393,7 @@ func TestClientAppendToObjectUsingObjectComposition(t *testing.T) {
 	moAPI.AssertExpectations(t)
 	moAPI.AssertNumberOfCalls(t, "Attrs", 1)
 	moAPI.AssertNumberOfCalls(t, "Retryer", 3)
+	moAPI.AssertNumberOfCalls(t, "If", 1)
 	moAPI.AssertNumberOfCalls(t, "NewWriter", 1)
 	moAPI.AssertNumberOfCalls(t, "ComposerFrom", 1)
 	moAPI.AssertNumberOfCalls(t, "Delete", 1)
@@ -400,6 +408,134 @@ func TestClientAppendToObjectUsingObjectComposition(t *testing.T) {
 	mcAPI.AssertNumberOfCalls(t, "Run", 1)
 }
 
+func TestClientAppendToObjectGetAttrsError(t *testing.T) {
+	var (
+		msAPI = &mockServiceAPI{}
+		mbAPI = &mockBucketAPI{}
+		moAPI = &mockObjectAPI{}
+	)
+
+	msAPI.On("Bucket", mock.MatchedBy(func(bucket string) bool { return bucket == "bucket" })).Return(mbAPI)
+
+	mbAPI.On("Object", mock.MatchedBy(func(key string) bool { return key == "key" })).Return(moAPI)
+
+	moAPI.On("Attrs", mock.Anything).Return(nil, assert.AnError)
+
+	client := &Client{serviceAPI: msAPI}
+
+	err := client.AppendToObject(context.Background(), "bucket", "key", strings.NewReader("value"))
+	require.ErrorIs(t, err, assert.AnError)
+
+	moAPI.AssertExpectations(t)
+	moAPI.AssertNumberOfCalls(t, "Attrs", 1)
+}
+
+func TestClientAppendToObjectComposeErrorCleansUp(t *testing.T) {
+	var (
+		msAPI = &mockServiceAPI{}
+		mbAPI = &mockBucketAPI{}
+		moAPI = &mockObjectAPI{}
+		mwAPI = &mockWriterAPI{}
+		mcAPI = &mockComposeAPI{}
+	)
+
+	msAPI.On("Bucket", mock.MatchedBy(func(bucket string) bool { return bucket == "bucket" })).Return(mbAPI)
+
+	mbAPI.On("Object", mock.MatchedBy(
+		func(key string) bool { return key == "key" || strings.HasPrefix(key, "key-mpu-") },
+	)).Return(moAPI)
+
+	moAPI.On("Retryer", mock.Anything).Return(moAPI)
+	moAPI.On("If", mock.Anything).Return(moAPI)
+	moAPI.On("Attrs", mock.Anything).Return(&storage.ObjectAttrs{Size: 5}, nil)
+	moAPI.On("NewWriter", mock.Anything).Return(mwAPI, nil)
+
+	mwAPI.On("SendMD5", mock.Anything)
+	mwAPI.On("SendCRC", mock.Anything)
+	mwAPI.On("Write", mock.Anything).Return(5, nil)
+	mwAPI.On("Close").Return(nil)
+
+	moAPI.On("ComposerFrom", mock.Anything, mock.Anything).Return(mcAPI)
+
+	mcAPI.On("Run", mock.Anything).Return(nil, assert.AnError)
+
+	moAPI.On("Delete", mock.Anything).Return(nil)
+
+	client := &Client{serviceAPI: msAPI}
+
+	err := client.AppendToObject(context.Background(), "bucket", "key", strings.NewReader("value"))
+	require.ErrorIs(t, err, assert.AnError)
+
+	moAPI.AssertExpectations(t)
+	moAPI.AssertNumberOfCalls(t, "ComposerFrom", 1)
+	moAPI.AssertNumberOfCalls(t, "Delete", 1)
+
+	mcAPI.AssertExpectations(t)
+	mcAPI.AssertNumberOfCalls(t, "Run", 1)
+}
+
+func TestClientAppendToObjectComposePreconditionFailed(t *testing.T) {
+	type test struct {
+		name     string
+		size     int64
+		expected bool
+	}
+
+	tests := []*test{
+		{
+			name:     "AlreadyAppended",
+			size:     10,
+			expected: true,
+		},
+		{
+			name: "ConcurrentlyModified",
+			size: 15,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				msAPI = &mockServiceAPI{}
+				mbAPI = &mockBucketAPI{}
+				moAPI = &mockObjectAPI{}
+				mwAPI = &mockWriterAPI{}
+				mcAPI = &mockComposeAPI{}
+			)
+
+			msAPI.On("Bucket", mock.Anything).Return(mbAPI)
+
+			mbAPI.On("Object", mock.Anything).Return(moAPI)
+
+			moAPI.On("Retryer", mock.Anything).Return(moAPI)
+			moAPI.On("If", mock.Anything).Return(moAPI)
+			moAPI.On("Attrs", mock.Anything).Return(&storage.ObjectAttrs{Size: 5}, nil).Once()
+			moAPI.On("Attrs", mock.Anything).Return(&storage.ObjectAttrs{Size: test.size}, nil).Once()
+			moAPI.On("NewWriter", mock.Anything).Return(mwAPI, nil)
+			moAPI.On("ComposerFrom", mock.Anything, mock.Anything).Return(mcAPI)
+			moAPI.On("Delete", mock.Anything).Return(nil)
+
+			mwAPI.On("SendMD5", mock.Anything)
+			mwAPI.On("SendCRC", mock.Anything)
+			mwAPI.On("Write", mock.Anything).Return(5, nil)
+			mwAPI.On("Close").Return(nil)
+
+			mcAPI.On("Run", mock.Anything).Return(nil, &googleapi.Error{Code: http.StatusPreconditionFailed})
+
+			client := &Client{serviceAPI: msAPI}
+
+			err := client.AppendToObject(context.Background(), "bucket", "key", strings.NewReader("value"))
+			if test.expected {
+				require.NoError(t, err)
+			} else {
+				require.True(t, isPreconditionFailed(err))
+			}
+
+			moAPI.AssertNumberOfCalls(t, "Attrs", 2)
+		})
+	}
+}
+
 func TestClientDeleteObjects(t *testing.T) {
 	var (
 		msAPI = &mockServiceAPI{}
@@ -1092,3 +1228,122 @@ func TestClientAbortMultipartUpload(t *testing.T) {
 	moAPI.AssertNumberOfCalls(t, "Retryer", 1)
 	moAPI.AssertNumberOfCalls(t, "Delete", 1)
 }
+
+// benchmarkReader implements the 'readerAPI' interface for an in-memory object.
+type benchmarkReader struct {
+	*bytes.Reader
+}
+
+func (b benchmarkReader) Close() error {
+	return nil
+}
+
+func (b benchmarkReader) Attrs() storage.ReaderObjectAttrs {
+	return storage.ReaderObjectAttrs{Size: b.Size()}
+}
+
+func BenchmarkAppendToObject(b *testing.B) {
+	type benchmark struct {
+		name   string
+		append func(client *Client, data io.ReadSeeker) error
+	}
+
+	benchmarks := []*benchmark{
+		{
+			name: "Compose",
+			append: func(client *Client, data io.ReadSeeker) error {
+				return client.AppendToObject(context.Background(), "bucket", "key", data)
+			},
+		},
+		{
+			// DownloadAndUpload is the alternative where the object can't be composed e.g. when using the XML API
+			name: "DownloadAndUpload",
+			append: func(client *Client, data io.ReadSeeker) error {
+				object, err := client.GetObject(context.Background(), "bucket", "key", nil)
+				if err != nil {
+					return err
+				}
+
+				buffer := &bytes.Buffer{}
+
+				_, err = io.Copy(buffer, io.MultiReader(object.Body, data))
+				if err != nil {
+					return err
+				}
+
+				return client.PutObject(context.Background(), "bucket", "key", bytes.NewReader(buffer.Bytes()))
+			},
+		},
+	}
+
+	for _, benchmark := range benchmarks {
+		b.Run(benchmark.name, func(b *testing.B) {
+			var (
+				msAPI = &mockServiceAPI{}
+				mbAPI = &mockBucketAPI{}
+				moAPI = &mockObjectAPI{}
+				mwAPI = &mockWriterAPI{}
+				mcAPI = &mockComposeAPI{}
+				data  = bytes.NewReader(make([]byte, 1024))
+			)
+
+			var size, requests, transferred int64
+
+			msAPI.On("Bucket", mock.Anything).Return(mbAPI)
+			mbAPI.On("Object", mock.Anything).Return(moAPI)
+
+			moAPI.On("Retryer", mock.Anything).Return(moAPI)
+			moAPI.On("If", mock.Anything).Return(moAPI)
+
+			attrs := func(_ context.Context) *storage.ObjectAttrs { return &storage.ObjectAttrs{Size: size} }
+
+			moAPI.On("Attrs", mock.Anything).Return(attrs, nil).Run(func(_ mock.Arguments) { requests++ })
+
+			reader := func(_ context.Context, _, _ int64) readerAPI {
+				return benchmarkReader{Reader: bytes.NewReader(make([]byte, size))}
+			}
+
+			moAPI.On("NewRangeReader", mock.Anything, mock.Anything, mock.Anything).
+				Return(reader, nil).
+				Run(func(_ mock.Arguments) { requests++; transferred += size })
+
+			moAPI.On("NewWriter", mock.Anything).Return(mwAPI)
+
+			mwAPI.On("SendMD5", mock.Anything)
+			mwAPI.On("SendCRC", mock.Anything)
+
+			written := func(data []byte) int { return len(data) }
+
+			mwAPI.On("Write", mock.Anything).
+				Return(written, nil).
+				Run(func(args mock.Arguments) { transferred += int64(len(args.Get(0).([]byte))) })
+
+			mwAPI.On("Close").Return(nil).Run(func(_ mock.Arguments) { requests++ })
+
+			moAPI.On("ComposerFrom", mock.Anything, mock.Anything).Return(mcAPI)
+
+			mcAPI.On("Run", mock.Anything).Return(nil, nil).Run(func(_ mock.Arguments) { requests++ })
+
+			moAPI.On("Delete", mock.Anything).Return(nil).Run(func(_ mock.Arguments) { requests++ })
+
+			// The object already exists, and is non-empty, this is the steady state for a log-style object
+			size = data.Size()
+
+			client := &Client{serviceAPI: msAPI}
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				_, err := data.Seek(0, io.SeekStart)
+				require.NoError(b, err)
+
+				require.NoError(b, benchmark.append(client, data))
+
+				size += data.Size()
+			}
+
+			b.ReportMetric(float64(requests)/float64(b.N), "requests/op")
+			b.ReportMetric(float64(transferred)/float64(b.N), "transferred-B/op")
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package objgcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"google.golang.org/api/option"
+)
+
+const (
+	// CredentialsJSONEnvVar is the environment variable which may be used to provide the contents of a service account
+	// JSON key file.
+	CredentialsJSONEnvVar = "GOOGLE_APPLICATION_CREDENTIALS_JSON"
+
+	// InteroperabilityEndpoint is the endpoint for the Google Storage XML API, which is compatible with S3 clients.
+	InteroperabilityEndpoint = "https://storage.googleapis.com"
+)
+
+// ClientOptions encapsulates the options available when creating a new client using 'NewClientWithOptions'.
+//
+// NOTE: Credentials are resolved in the following order, the first source which provides credentials is used:
+//  1. Anonymous (no credentials)
+//  2. Service account JSON (provided explicitly)
+//  3. Service account JSON file (provided explicitly)
+//  4. Service account JSON (via the 'GOOGLE_APPLICATION_CREDENTIALS_JSON' environment variable)
+//  5. Application default credentials
+type ClientOptions struct {
+	// Endpoint overrides the storage endpoint, for example when using 'fake-gcs-server' this would be
+	// 'http://localhost:4443/storage/v1/'.
+	//
+	// NOTE: The SDK also supports the 'STORAGE_EMULATOR_HOST' environment variable.
+	Endpoint string
+
+	// Anonymous indicates that requests should be unauthenticated; this is useful when using an emulator or when
+	// accessing public buckets.
+	Anonymous bool
+
+	// CredentialsJSON is the contents of a service account JSON key file.
+	CredentialsJSON []byte
+
+	// CredentialsFile is the path to a service account JSON key file.
+	CredentialsFile string
+
+	// SDKOptions are any additional options which should be passed to the SDK when creating the storage client.
+	SDKOptions []option.ClientOption
+}
+
+// NewClientWithOptions returns a new client which is created using the given options.
+func NewClientWithOptions(ctx context.Context, options ClientOptions) (*Client, error) {
+	if options.Anonymous && (options.CredentialsJSON != nil || options.CredentialsFile != "") {
+		return nil, ErrAnonymousWithCredentials
+	}
+
+	sdkOptions := append(getCredentialsOptions(options), options.SDKOptions...)
+
+	if options.Endpoint != "" {
+		sdkOptions = append(sdkOptions, option.WithEndpoint(options.Endpoint))
+	}
+
+	client, err := storage.NewClient(ctx, sdkOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %w", err)
+	}
+
+	return NewClient(client), nil
+}
+
+// getCredentialsOptions returns the SDK options which should be used to authenticate, returns <nil> in the event that
+// the application default credentials should be used.
+func getCredentialsOptions(options ClientOptions) []option.ClientOption {
+	if options.Anonymous {
+		return []option.ClientOption{option.WithoutAuthentication()}
+	}
+
+	if options.CredentialsJSON != nil {
+		return []option.ClientOption{option.WithCredentialsJSON(options.CredentialsJSON)}
+	}
+
+	if options.CredentialsFile != "" {
+		return []option.ClientOption{option.WithCredentialsFile(options.CredentialsFile)}
+	}
+
+	if env := os.Getenv(CredentialsJSONEnvVar); env != "" {
+		return []option.ClientOption{option.WithCredentialsJSON([]byte(env))}
+	}
+
+	return nil
+}
+
+// HMACOptions encapsulates the options available when creating a client which authenticates using HMAC keys.
+type HMACOptions struct {
+	// AccessKeyID/Secret are the HMAC interoperability keys.
+	//
+	// NOTE: These attributes are required.
+	AccessKeyID, Secret string
+
+	// Endpoint overrides the XML API endpoint, defaults to 'https://storage.googleapis.com'.
+	Endpoint string
+}
+
+// NewHMACClient returns a new client which uses HMAC interoperability keys to interact with Google Storage using its
+// S3 compatible XML API.
+//
+// NOTE: HMAC keys are not supported by the JSON API used by the 'Client' in this package, see 'HMACClient' for the
+// differences in behavior when using the XML API.
+func NewHMACClient(options HMACOptions) (*HMACClient, error) {
+	if options.AccessKeyID == "" || options.Secret == "" {
+		return nil, ErrHMACKeysRequired
+	}
+
+	endpoint := InteroperabilityEndpoint
+	if options.Endpoint != "" {
+		endpoint = options.Endpoint
+	}
+
+	config := &aws.Config{
+		Credentials:      credentials.NewStaticCredentials(options.AccessKeyID, options.Secret, ""),
+		Endpoint:         aws.String(endpoint),
+		Region:           aws.String("auto"),
+		S3ForcePathStyle: aws.Bool(true),
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return newHMACClient(s3.New(sess)), nil
+}
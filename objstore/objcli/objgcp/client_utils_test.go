@@ -0,0 +1,171 @@
+package objgcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testGCS is a minimal fake-gcs-server compatible server which records the authorization header for each request.
+type testGCS struct {
+	server *httptest.Server
+	auth   []string
+}
+
+// newTestGCS creates a new test server which serves a single object 'bucket/key' and provides an OAuth2 token endpoint.
+func newTestGCS(t *testing.T) *testGCS {
+	gcs := &testGCS{}
+
+	gcs.server = httptest.NewServer(http.HandlerFunc(gcs.handle))
+	t.Cleanup(gcs.server.Close)
+
+	return gcs
+}
+
+func (g *testGCS) handle(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case request.URL.Path == "/token":
+		_, _ = writer.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+	case request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/b/bucket/o/key"):
+		g.auth = append(g.auth, request.Header.Get("Authorization"))
+		_, _ = writer.Write([]byte(`{"bucket":"bucket","name":"key","size":"5","etag":"etag"}`))
+	case request.Method == http.MethodHead && request.URL.Path == "/bucket/key":
+		g.auth = append(g.auth, request.Header.Get("Authorization"))
+		writer.Header().Set("Content-Length", "5")
+		writer.Header().Set("ETag", "etag")
+		writer.WriteHeader(http.StatusOK)
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// endpoint returns the JSON API endpoint for the test server.
+func (g *testGCS) endpoint() string {
+	return g.server.URL + "/storage/v1/"
+}
+
+// serviceAccountJSON returns a service account JSON key which will fetch tokens from the test server.
+func (g *testGCS) serviceAccountJSON(t *testing.T) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	encoded := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "project",
+		"private_key_id": "id",
+		"private_key":    string(encoded),
+		"client_email":   "test@project.iam.gserviceaccount.com",
+		"client_id":      "id",
+		"token_uri":      g.server.URL + "/token",
+	})
+	require.NoError(t, err)
+
+	return data
+}
+
+// clearGCPEnv ensures that credentials/endpoints from the environment are not used by tests.
+func clearGCPEnv(t *testing.T) {
+	for _, key := range []string{"STORAGE_EMULATOR_HOST", "GOOGLE_APPLICATION_CREDENTIALS", CredentialsJSONEnvVar} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestNewClientWithOptions(t *testing.T) {
+	type test struct {
+		name     string
+		options  func(t *testing.T, gcs *testGCS) ClientOptions
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name: "Anonymous",
+			options: func(t *testing.T, gcs *testGCS) ClientOptions {
+				return ClientOptions{Anonymous: true}
+			},
+		},
+		{
+			name: "CredentialsJSON",
+			options: func(t *testing.T, gcs *testGCS) ClientOptions {
+				return ClientOptions{CredentialsJSON: gcs.serviceAccountJSON(t)}
+			},
+			expected: "Bearer token",
+		},
+		{
+			name: "CredentialsFile",
+			options: func(t *testing.T, gcs *testGCS) ClientOptions {
+				path := filepath.Join(t.TempDir(), "credentials.json")
+				require.NoError(t, os.WriteFile(path, gcs.serviceAccountJSON(t), 0o600))
+
+				return ClientOptions{CredentialsFile: path}
+			},
+			expected: "Bearer token",
+		},
+		{
+			name: "CredentialsJSONViaEnv",
+			options: func(t *testing.T, gcs *testGCS) ClientOptions {
+				t.Setenv(CredentialsJSONEnvVar, string(gcs.serviceAccountJSON(t)))
+				return ClientOptions{}
+			},
+			expected: "Bearer token",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearGCPEnv(t)
+
+			gcs := newTestGCS(t)
+
+			options := test.options(t, gcs)
+			options.Endpoint = gcs.endpoint()
+
+			client, err := NewClientWithOptions(context.Background(), options)
+			require.NoError(t, err)
+
+			attrs, err := client.GetObjectAttrs(context.Background(), "bucket", "key")
+			require.NoError(t, err)
+			require.Equal(t, int64(5), attrs.Size)
+			require.Equal(t, []string{test.expected}, gcs.auth)
+		})
+	}
+}
+
+func TestNewClientWithOptionsAnonymousWithCredentials(t *testing.T) {
+	_, err := NewClientWithOptions(context.Background(), ClientOptions{Anonymous: true, CredentialsFile: "file"})
+	require.ErrorIs(t, err, ErrAnonymousWithCredentials)
+}
+
+func TestNewHMACClient(t *testing.T) {
+	gcs := newTestGCS(t)
+
+	client, err := NewHMACClient(HMACOptions{AccessKeyID: "GOOG1EXAMPLE", Secret: "secret", Endpoint: gcs.server.URL})
+	require.NoError(t, err)
+
+	attrs, err := client.GetObjectAttrs(context.Background(), "bucket", "key")
+	require.NoError(t, err)
+	require.Equal(t, int64(5), attrs.Size)
+
+	require.Len(t, gcs.auth, 1)
+	require.True(t, strings.HasPrefix(gcs.auth[0], "AWS4-HMAC-SHA256 Credential=GOOG1EXAMPLE/"))
+}
+
+func TestNewHMACClientMissingKeys(t *testing.T) {
+	_, err := NewHMACClient(HMACOptions{AccessKeyID: "GOOG1EXAMPLE"})
+	require.ErrorIs(t, err, ErrHMACKeysRequired)
+}
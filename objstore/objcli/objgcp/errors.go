@@ -0,0 +1,13 @@
+package objgcp
+
+import "errors"
+
+var (
+	// ErrAnonymousWithCredentials is returned if the user has requested anonymous access whilst also providing
+	// credentials.
+	ErrAnonymousWithCredentials = errors.New("anonymous access and credentials are mutually exclusive")
+
+	// ErrHMACKeysRequired is returned if the user attempts to create an HMAC client without providing both the access
+	// key id and secret.
+	ErrHMACKeysRequired = errors.New("both the HMAC access key id and secret are required")
+)
@@ -0,0 +1,249 @@
+package objgcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/couchbase/tools-common/hofp"
+	"github.com/couchbase/tools-common/maths"
+	"github.com/couchbase/tools-common/objstore/objcli"
+	"github.com/couchbase/tools-common/objstore/objcli/objaws"
+	"github.com/couchbase/tools-common/objstore/objerr"
+	"github.com/couchbase/tools-common/objstore/objval"
+	"github.com/couchbase/tools-common/system"
+)
+
+// HMACClient implements the 'objcli.Client' interface allowing the creation/management of objects stored in Google
+// Storage using HMAC interoperability keys via the S3 compatible XML API.
+//
+// NOTE: The XML API does not support multi-object deletes or copying byte ranges into a multipart upload, therefore,
+// objects are deleted individually, appending streams the object into a multipart upload (or downloads/re-uploads
+// objects under the minimum part size) and 'UploadPartCopy' is unsupported.
+type HMACClient struct {
+	*objaws.Client
+	s3 *s3.S3
+}
+
+var _ objcli.Client = (*HMACClient)(nil)
+
+// newHMACClient returns a new client which uses the given S3 client to interact with the Google Storage XML API.
+func newHMACClient(client *s3.S3) *HMACClient {
+	return &HMACClient{Client: objaws.NewClient(client), s3: client}
+}
+
+func (c *HMACClient) Provider() objval.Provider {
+	return objval.ProviderGCP
+}
+
+func (c *HMACClient) AppendToObject(ctx context.Context, bucket, key string, data io.ReadSeeker) error {
+	attrs, err := c.GetObjectAttrs(ctx, bucket, key)
+
+	// As defined by the 'Client' interface, if the given object does not exist, we create it
+	if objerr.IsNotFoundError(err) {
+		return c.PutObject(ctx, bucket, key, data)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get object attributes: %w", err)
+	}
+
+	if attrs.Size < objaws.MinUploadSize {
+		return c.downloadAndAppend(ctx, bucket, attrs, data)
+	}
+
+	return c.createMPUThenStreamAndAppend(ctx, bucket, attrs, data)
+}
+
+// downloadAndAppend downloads an object, and appends the given data to it before uploading it back to Google Storage;
+// this should be used for objects which are less than 5MiB in size (i.e. under the multipart upload minium size).
+func (c *HMACClient) downloadAndAppend(
+	ctx context.Context, bucket string, attrs *objval.ObjectAttrs, data io.ReadSeeker,
+) error {
+	object, err := c.GetObject(ctx, bucket, attrs.Key, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get object: %w", err)
+	}
+
+	defer object.Body.Close()
+
+	buffer := &bytes.Buffer{}
+
+	_, err = io.Copy(buffer, io.MultiReader(object.Body, data))
+	if err != nil {
+		return fmt.Errorf("failed to download and append to object: %w", err)
+	}
+
+	err = c.PutObject(ctx, bucket, attrs.Key, bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		return fmt.Errorf("failed to upload updated object: %w", err)
+	}
+
+	return nil
+}
+
+// createMPUThenStreamAndAppend creates a multipart upload, then kicks off the stream and append operation.
+func (c *HMACClient) createMPUThenStreamAndAppend(
+	ctx context.Context, bucket string, attrs *objval.ObjectAttrs, data io.ReadSeeker,
+) error {
+	id, err := c.CreateMultipartUpload(ctx, bucket, attrs.Key)
+	if err != nil {
+		return fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	err = c.streamAndAppend(ctx, bucket, id, attrs, data)
+	if err == nil {
+		return nil
+	}
+
+	// NOTE: We've failed for some reason, we should try to cleanup after ourselves
+	if err := c.AbortMultipartUpload(ctx, bucket, id, attrs.Key); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+
+	return err
+}
+
+// streamAndAppend streams the source object, followed by the given data, into the multipart upload then completes it.
+// This has the affect of appending data to the object, without buffering the whole object, since the XML API doesn't
+// support copying the source object into the multipart upload; only a single part is held in memory at once.
+func (c *HMACClient) streamAndAppend(
+	ctx context.Context, bucket, id string, attrs *objval.ObjectAttrs, data io.ReadSeeker,
+) error {
+	length, err := aws.SeekerLen(data)
+	if err != nil {
+		return fmt.Errorf("failed to determine length of data: %w", err)
+	}
+
+	object, err := c.GetObject(ctx, bucket, attrs.Key, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get object: %w", err)
+	}
+
+	defer object.Body.Close()
+
+	var (
+		reader = io.MultiReader(object.Body, data)
+		buffer = make([]byte, partSize(attrs.Size+length))
+		parts  = make([]objval.Part, 0)
+	)
+
+	for number := 1; ; number++ {
+		n, err := io.ReadFull(reader, buffer)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("failed to read part %d: %w", number, err)
+		}
+
+		part, err := c.UploadPart(ctx, bucket, id, attrs.Key, number, bytes.NewReader(buffer[:n]))
+		if err != nil {
+			return fmt.Errorf("failed to upload part %d: %w", number, err)
+		}
+
+		parts = append(parts, part)
+
+		if n < len(buffer) {
+			break
+		}
+	}
+
+	err = c.CompleteMultipartUpload(ctx, bucket, id, attrs.Key, parts...)
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+func (c *HMACClient) DeleteObjects(ctx context.Context, bucket string, keys ...string) error {
+	pool := hofp.NewPool(hofp.Options{
+		Context:   ctx,
+		Size:      system.NumWorkers(len(keys)),
+		LogPrefix: "(objgcp)",
+	})
+
+	queue := func(key string) error {
+		return pool.Queue(func(ctx context.Context) error { return c.deleteObject(ctx, bucket, key) })
+	}
+
+	for _, key := range keys {
+		if queue(key) != nil {
+			break
+		}
+	}
+
+	return pool.Stop()
+}
+
+// deleteObject deletes a single object, objects which do not exist are ignored.
+func (c *HMACClient) deleteObject(ctx context.Context, bucket, key string) error {
+	input := &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+
+	_, err := c.s3.DeleteObjectWithContext(ctx, input)
+	if err == nil {
+		return nil
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+		return nil
+	}
+
+	return handleDeleteError(bucket, key, err)
+}
+
+func (c *HMACClient) DeleteDirectory(ctx context.Context, bucket, prefix string) error {
+	fn := func(attrs *objval.ObjectAttrs) error {
+		return c.deleteObject(ctx, bucket, attrs.Key)
+	}
+
+	return c.IterateObjects(ctx, bucket, prefix, "", nil, nil, fn)
+}
+
+// NOTE: The Google Storage XML API does not support copying into a multipart upload.
+func (c *HMACClient) UploadPartCopy(
+	ctx context.Context, bucket, id, dst, src string, number int, br *objval.ByteRange,
+) (objval.Part, error) {
+	return objval.Part{}, objerr.ErrUnsupportedOperation
+}
+
+// partSize returns the size of the parts used to upload an object of the given size, this is the minimum part size unless
+// that would exceed the maximum number of parts.
+func partSize(size int64) int64 {
+	return maths.Max(objaws.MinUploadSize, (size+objaws.MaxUploadParts-1)/objaws.MaxUploadParts)
+}
+
+// handleDeleteError converts an error returned when deleting an object into a user friendly error where possible.
+func handleDeleteError(bucket, key string, err error) error {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return objerr.HandleError(err)
+	}
+
+	switch awsErr.Code() {
+	case "InvalidAccessKeyId", "SignatureDoesNotMatch":
+		return objerr.ErrUnauthenticated
+	case "AccessDenied":
+		return objerr.ErrUnauthorized
+	case s3.ErrCodeNoSuchBucket:
+		return &objerr.NotFoundError{Type: "bucket", Name: bucket}
+	}
+
+	if err := objerr.TryHandleError(awsErr.OrigErr()); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("failed to delete object '%s': %w", key, err)
+}
@@ -0,0 +1,240 @@
+package objgcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/objstore/objcli/objaws"
+	"github.com/couchbase/tools-common/objstore/objerr"
+	"github.com/couchbase/tools-common/objstore/objval"
+)
+
+// testXMLAPI is a minimal in-memory implementation of the Google Storage XML API which, like the real API, rejects
+// multi-object deletes and part copies.
+type testXMLAPI struct {
+	server *httptest.Server
+
+	lock    sync.Mutex
+	objects map[string][]byte
+
+	// uploads are the parts of the in-progress multipart uploads, keyed by upload id then part number
+	uploads map[string]map[int][]byte
+
+	// partSizes are the sizes of all the parts uploaded to the server
+	partSizes []int
+}
+
+// newTestXMLAPI creates a new test server which stores objects for the bucket 'bucket'.
+func newTestXMLAPI(t *testing.T, objects map[string][]byte) *testXMLAPI {
+	api := &testXMLAPI{objects: objects, uploads: make(map[string]map[int][]byte)}
+
+	api.server = httptest.NewServer(http.HandlerFunc(api.handle))
+	t.Cleanup(api.server.Close)
+
+	return api
+}
+
+func (a *testXMLAPI) handle(writer http.ResponseWriter, request *http.Request) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	var (
+		key   = strings.TrimPrefix(request.URL.Path, "/bucket/")
+		query = request.URL.Query()
+	)
+
+	switch {
+	case query.Has("delete"), request.Header.Get("X-Amz-Copy-Source") != "":
+		a.error(writer, http.StatusNotImplemented, "NotImplemented")
+	case query.Has("uploads"), query.Has("uploadId"):
+		a.multipart(writer, request, key)
+	case request.Method == http.MethodGet && request.URL.Path == "/bucket":
+		a.list(writer, request.URL.Query().Get("prefix"))
+	case request.Method == http.MethodHead, request.Method == http.MethodGet:
+		data, ok := a.objects[key]
+		if !ok {
+			a.error(writer, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+
+		writer.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		writer.Header().Set("ETag", "etag")
+
+		if request.Method == http.MethodGet {
+			_, _ = writer.Write(data)
+		}
+	case request.Method == http.MethodPut:
+		data, _ := io.ReadAll(request.Body)
+		a.objects[key] = data
+	case request.Method == http.MethodDelete:
+		if _, ok := a.objects[key]; !ok {
+			a.error(writer, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+
+		delete(a.objects, key)
+		writer.WriteHeader(http.StatusNoContent)
+	default:
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *testXMLAPI) multipart(writer http.ResponseWriter, request *http.Request, key string) {
+	var (
+		query = request.URL.Query()
+		id    = query.Get("uploadId")
+	)
+
+	switch request.Method {
+	case http.MethodPost:
+		if query.Has("uploads") {
+			id = strconv.Itoa(len(a.uploads))
+			a.uploads[id] = make(map[int][]byte)
+
+			_, _ = writer.Write([]byte("<InitiateMultipartUploadResult><UploadId>" + id +
+				"</UploadId></InitiateMultipartUploadResult>"))
+
+			return
+		}
+
+		var complete struct {
+			Parts []struct {
+				PartNumber int
+			} `xml:"Part"`
+		}
+
+		_ = xml.NewDecoder(request.Body).Decode(&complete)
+
+		var data []byte
+		for _, part := range complete.Parts {
+			data = append(data, a.uploads[id][part.PartNumber]...)
+		}
+
+		a.objects[key] = data
+		delete(a.uploads, id)
+
+		_, _ = writer.Write([]byte("<CompleteMultipartUploadResult></CompleteMultipartUploadResult>"))
+	case http.MethodPut:
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		data, _ := io.ReadAll(request.Body)
+
+		a.uploads[id][number] = data
+		a.partSizes = append(a.partSizes, len(data))
+
+		writer.Header().Set("ETag", strconv.Itoa(number))
+	case http.MethodDelete:
+		delete(a.uploads, id)
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *testXMLAPI) list(writer http.ResponseWriter, prefix string) {
+	type content struct {
+		Key  string
+		Size int
+	}
+
+	type result struct {
+		XMLName  xml.Name `xml:"ListBucketResult"`
+		Contents []content
+	}
+
+	var res result
+
+	for key, data := range a.objects {
+		if strings.HasPrefix(key, prefix) {
+			res.Contents = append(res.Contents, content{Key: key, Size: len(data)})
+		}
+	}
+
+	sort.Slice(res.Contents, func(i, j int) bool { return res.Contents[i].Key < res.Contents[j].Key })
+
+	_ = xml.NewEncoder(writer).Encode(res)
+}
+
+func (a *testXMLAPI) error(writer http.ResponseWriter, status int, code string) {
+	writer.WriteHeader(status)
+	_, _ = writer.Write([]byte("<Error><Code>" + code + "</Code></Error>"))
+}
+
+func newTestHMACClient(t *testing.T, objects map[string][]byte) *HMACClient {
+	client, _ := newTestHMACClientWithAPI(t, objects)
+	return client
+}
+
+func newTestHMACClientWithAPI(t *testing.T, objects map[string][]byte) (*HMACClient, *testXMLAPI) {
+	api := newTestXMLAPI(t, objects)
+
+	client, err := NewHMACClient(HMACOptions{AccessKeyID: "GOOG1EXAMPLE", Secret: "secret", Endpoint: api.server.URL})
+	require.NoError(t, err)
+
+	return client, api
+}
+
+func TestHMACClientProvider(t *testing.T) {
+	require.Equal(t, objval.ProviderGCP, newTestHMACClient(t, nil).Provider())
+}
+
+func TestHMACClientAppendToObject(t *testing.T) {
+	objects := map[string][]byte{"key": []byte("Hello")}
+
+	client := newTestHMACClient(t, objects)
+
+	err := client.AppendToObject(context.Background(), "bucket", "key", strings.NewReader(", World!"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("Hello, World!"), objects["key"])
+
+	err = client.AppendToObject(context.Background(), "bucket", "new", strings.NewReader("Hello"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("Hello"), objects["new"])
+}
+
+func TestHMACClientAppendToObjectMultipart(t *testing.T) {
+	existing := bytes.Repeat([]byte("a"), objaws.MinUploadSize+1)
+
+	objects := map[string][]byte{"key": existing}
+
+	client, api := newTestHMACClientWithAPI(t, objects)
+
+	err := client.AppendToObject(context.Background(), "bucket", "key", strings.NewReader(", World!"))
+	require.NoError(t, err)
+	require.Equal(t, append(existing, []byte(", World!")...), objects["key"])
+	require.Equal(t, []int{objaws.MinUploadSize, 9}, api.partSizes)
+	require.Empty(t, api.uploads)
+}
+
+func TestHMACClientDeleteObjects(t *testing.T) {
+	objects := map[string][]byte{"key1": nil, "key2": nil, "key3": nil}
+
+	client := newTestHMACClient(t, objects)
+
+	err := client.DeleteObjects(context.Background(), "bucket", "key1", "key2", "missing")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"key3": nil}, objects)
+}
+
+func TestHMACClientDeleteDirectory(t *testing.T) {
+	objects := map[string][]byte{"dir/key1": nil, "dir/sub/key2": nil, "key3": nil}
+
+	client := newTestHMACClient(t, objects)
+
+	err := client.DeleteDirectory(context.Background(), "bucket", "dir/")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"key3": nil}, objects)
+}
+
+func TestHMACClientUploadPartCopy(t *testing.T) {
+	_, err := newTestHMACClient(t, nil).UploadPartCopy(context.Background(), "bucket", "id", "dst", "src", 1, nil)
+	require.ErrorIs(t, err, objerr.ErrUnsupportedOperation)
+}
@@ -89,6 +89,22 @@ func (_m *mockObjectAPI) Delete(ctx context.Context) error {
 	return r0
 }
 
+// If provides a mock function with given fields: conds
+func (_m *mockObjectAPI) If(conds storage.Conditions) objectAPI {
+	ret := _m.Called(conds)
+
+	var r0 objectAPI
+	if rf, ok := ret.Get(0).(func(storage.Conditions) objectAPI); ok {
+		r0 = rf(conds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(objectAPI)
+		}
+	}
+
+	return r0
+}
+
 // NewRangeReader provides a mock function with given fields: ctx, offset, length
 func (_m *mockObjectAPI) NewRangeReader(ctx context.Context, offset, length int64) (readerAPI, error) {
 	ret := _m.Called(ctx, offset, length)
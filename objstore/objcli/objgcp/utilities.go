@@ -56,6 +56,12 @@ func handleError(bucket, key string, err error) error {
 	return objerr.HandleError(err)
 }
 
+// isPreconditionFailed returns a boolean indicating whether the given error is the result of a failed precondition.
+func isPreconditionFailed(err error) bool {
+	var gerr *googleapi.Error
+	return errors.As(err, &gerr) && gerr.Code == http.StatusPreconditionFailed
+}
+
 // partKey returns a key which should be used for an in-progress multipart upload. This function should be used to
 // generate key names since they'll be prefixed with 'basename(key)-mpu-' allowing efficient listing upon completion.
 func partKey(id, key string) string {
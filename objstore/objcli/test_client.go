@@ -15,6 +15,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/couchbase/tools-common/objstore/objerr"
 	"github.com/couchbase/tools-common/objstore/objval"
@@ -149,21 +151,34 @@ func (t *TestClient) IterateObjects(ctx context.Context, bucket, prefix, delimit
 		return nil
 	}
 
-	for key, object := range b {
-		if !strings.HasPrefix(key, prefix) || ShouldIgnore(key, include, exclude) {
+	// Cloud providers list objects in lexicographical order, do the same here to allow deterministic testing
+	keys := maps.Keys(b)
+	slices.Sort(keys)
+
+	// Directory stubs should only be returned once, in the same way that common prefixes are returned by the cloud
+	// providers.
+	seen := make(map[string]struct{})
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
 			continue
 		}
 
-		var (
-			trimmed = strings.TrimPrefix(key, prefix)
-			attrs   = object.ObjectAttrs
-		)
+		attrs := b[key].ObjectAttrs
 
 		// If this is a nested key, convert it into a directory stub
-		if delimiter != "" && strings.Count(trimmed, delimiter) > 1 {
-			attrs.Key = rootDirectory(trimmed)
-			attrs.Size = 0
-			attrs.LastModified = nil
+		if dir := commonPrefix(key, prefix, delimiter); dir != "" {
+			if _, ok := seen[dir]; ok {
+				continue
+			}
+
+			seen[dir] = struct{}{}
+
+			attrs = objval.ObjectAttrs{Key: dir}
+		}
+
+		if ShouldIgnore(attrs.Key, include, exclude) {
+			continue
 		}
 
 		if err := fn(&attrs); err != nil {
@@ -342,12 +357,19 @@ func partPrefix(id, key string) string {
 	return fmt.Sprintf("%s-mpu-%s", key, id)
 }
 
-// rootDirectory returns the root directory for the provided key.
-func rootDirectory(key string) string {
-	dir := path.Dir(key)
-	if dir == "." || dir == "/" {
-		return key
+// commonPrefix returns the common prefix (directory stub) which the given key should be grouped under when listing with
+// the provided prefix/delimiter, an empty string is returned if the key should not be grouped.
+func commonPrefix(key, prefix, delimiter string) string {
+	if delimiter == "" {
+		return ""
+	}
+
+	trimmed := strings.TrimPrefix(key, prefix)
+
+	idx := strings.Index(trimmed, delimiter)
+	if idx == -1 {
+		return ""
 	}
 
-	return rootDirectory(dir)
+	return prefix + trimmed[:idx+len(delimiter)]
 }
@@ -0,0 +1,295 @@
+package objutil
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/couchbase/tools-common/hofp"
+	"github.com/couchbase/tools-common/objstore/objcli"
+	"github.com/couchbase/tools-common/objstore/objval"
+)
+
+// IterateObjectsOptions encapsulates the options available when using the 'IterateObjects' function to list objects in
+// a remote cloud.
+type IterateObjectsOptions struct {
+	Options
+
+	// Client is the client used to perform the operation.
+	//
+	// NOTE: This attribute is required.
+	Client objcli.Client
+
+	// Bucket is the bucket to list objects from.
+	//
+	// NOTE: This attribute is required.
+	Bucket string
+
+	// Prefix is the prefix under which objects will be listed.
+	Prefix string
+
+	// Delimiter is used to discover the common prefixes (shards) under 'Prefix' which will be listed concurrently.
+	// Defaults to '/'.
+	Delimiter string
+
+	// Include/Exclude are the regular expressions used to filter the objects which are passed to 'Func'.
+	//
+	// NOTE: These attributes are mutually exclusive.
+	Include, Exclude []*regexp.Regexp
+
+	// MaxParallelism is the maximum number of shards which will be listed concurrently. Defaults to the number of vCPUs.
+	MaxParallelism int
+
+	// Deterministic ensures that objects are passed to 'Func' in lexicographical order, rather than the order in which
+	// they are listed.
+	//
+	// NOTE: Listing will still be performed concurrently, however, objects will be buffered in memory until all the
+	// shards before them have been passed to 'Func'.
+	Deterministic bool
+
+	// Func is run once for each object which is listed.
+	//
+	// NOTE: This attribute is required, calls to this function are serialized and therefore do not need to be thread
+	// safe.
+	Func objcli.IterateFunc
+}
+
+// defaults fills any missing attributes to a sane default.
+func (i *IterateObjectsOptions) defaults() {
+	i.Options.defaults()
+
+	if i.Delimiter == "" {
+		i.Delimiter = "/"
+	}
+}
+
+// shard represents a unit of work when iterating objects; either a single object listed whilst discovering common
+// prefixes, or a common prefix which will be listed in its entirety.
+type shard struct {
+	attrs   *objval.ObjectAttrs
+	prefix  string
+	objects []*objval.ObjectAttrs
+	done    chan struct{}
+}
+
+// IterateObjects lists all the objects under the given prefix by first discovering the common prefixes using the given
+// delimiter, then listing each common prefix concurrently. This should be preferred over 'Client.IterateObjects' when
+// listing prefixes which contain a large number of objects.
+//
+// NOTE: Unlike 'Client.IterateObjects', directory stubs are never passed to the iteration function.
+func IterateObjects(opts IterateObjectsOptions) error {
+	if opts.Include != nil && opts.Exclude != nil {
+		return objcli.ErrIncludeAndExcludeAreMutuallyExclusive
+	}
+
+	// Fill out any missing fields with the sane defaults
+	opts.defaults()
+
+	shards, err := discoverShards(opts)
+	if err != nil {
+		return fmt.Errorf("failed to discover common prefixes: %w", err)
+	}
+
+	// Objects which aren't nested under a common prefix can be passed to the iteration function immediately
+	if !opts.Deterministic {
+		for _, s := range shards {
+			if s.attrs == nil {
+				continue
+			}
+
+			if err := opts.Func(s.attrs); err != nil {
+				return err // Purposefully not wrapped
+			}
+		}
+	}
+
+	// The context is cancelled in the event of an error, which ensures we don't block waiting for shards which will
+	// never be listed.
+	ctx, cancel := context.WithCancel(opts.Context)
+	defer cancel()
+
+	pool := hofp.NewPool(hofp.Options{
+		Context:   ctx,
+		Size:      opts.MaxParallelism,
+		LogPrefix: "(objutil)",
+	})
+
+	var (
+		lock  sync.Mutex
+		fnErr error
+	)
+
+	// The iteration function is called from multiple workers, any error it returns is recorded so that it may be
+	// returned to the caller as is, rather than the (wrapped) error returned by the worker pool.
+	fn := func(attrs *objval.ObjectAttrs) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		err := opts.Func(attrs)
+		if err != nil && fnErr == nil {
+			fnErr = err
+		}
+
+		return err
+	}
+
+	list := func(ctx context.Context, s *shard) error {
+		err := iterateShard(ctx, opts, s, fn)
+		if err != nil {
+			cancel()
+		}
+
+		return err
+	}
+
+	queue := func(s *shard) error {
+		if s.attrs != nil {
+			close(s.done)
+			return nil
+		}
+
+		return pool.Queue(func(ctx context.Context) error { return list(ctx, s) })
+	}
+
+	for _, s := range shards {
+		// Can ignore this error, the same error will be propagated by the call to 'Stop' below.
+		if err := queue(s); err != nil {
+			break
+		}
+	}
+
+	// When the caller has requested deterministic ordering, the objects in each shard are buffered and must be passed
+	// to the iteration function in order.
+	if opts.Deterministic {
+		err = emitShards(ctx, shards, fn)
+	}
+
+	// Ensure any remaining workers stop if the iteration function has returned an error
+	if err != nil {
+		cancel()
+	}
+
+	stopErr := pool.Stop()
+
+	// If the caller has returned an error, return control to them; this takes precedence over any cancellation errors
+	// returned by the worker pool.
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if fnErr != nil {
+		return fnErr // Purposefully not wrapped
+	}
+
+	if stopErr != nil {
+		return fmt.Errorf("failed to iterate objects: %w", stopErr)
+	}
+
+	return opts.Context.Err()
+}
+
+// discoverShards lists the given prefix using the delimiter returning the common prefixes and objects in lexicographical
+// order.
+func discoverShards(opts IterateObjectsOptions) ([]*shard, error) {
+	shards := make([]*shard, 0)
+
+	fn := func(attrs *objval.ObjectAttrs) error {
+		if !attrs.IsDir() && objcli.ShouldIgnore(attrs.Key, opts.Include, opts.Exclude) {
+			return nil
+		}
+
+		s := &shard{attrs: attrs, done: make(chan struct{})}
+
+		if attrs.IsDir() {
+			s.attrs, s.prefix = nil, attrs.Key
+		}
+
+		shards = append(shards, s)
+
+		return nil
+	}
+
+	// NOTE: Filtering is performed manually, the include/exclude regular expressions may filter out common prefixes
+	err := opts.Client.IterateObjects(opts.Context, opts.Bucket, opts.Prefix, opts.Delimiter, nil, nil, fn)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	slices.SortFunc(shards, func(a, b *shard) bool { return a.key() < b.key() })
+
+	return shards, nil
+}
+
+// key returns the key/prefix for the given shard.
+func (s *shard) key() string {
+	if s.attrs != nil {
+		return s.attrs.Key
+	}
+
+	return s.prefix
+}
+
+// iterateShard lists all the objects in the given shard, either passing them directly to the iteration function or
+// buffering them when deterministic ordering is required.
+func iterateShard(ctx context.Context, opts IterateObjectsOptions, s *shard, fn objcli.IterateFunc) error {
+	defer close(s.done)
+
+	callback := func(attrs *objval.ObjectAttrs) error {
+		if attrs.IsDir() {
+			return nil
+		}
+
+		if !opts.Deterministic {
+			return fn(attrs)
+		}
+
+		s.objects = append(s.objects, attrs)
+
+		return nil
+	}
+
+	err := opts.Client.IterateObjects(ctx, opts.Bucket, s.prefix, "", opts.Include, opts.Exclude, callback)
+	if err != nil {
+		return fmt.Errorf("failed to iterate objects with prefix '%s': %w", s.prefix, err)
+	}
+
+	slices.SortFunc(s.objects, func(a, b *objval.ObjectAttrs) bool { return a.Key < b.Key })
+
+	return nil
+}
+
+// emitShards passes the objects from each shard to the iteration function in order, blocking until each shard has been
+// listed.
+//
+// NOTE: Returns <nil> if the given context is cancelled, the error which caused cancellation is returned elsewhere.
+func emitShards(ctx context.Context, shards []*shard, fn objcli.IterateFunc) error {
+	for _, s := range shards {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+		}
+
+		if s.attrs != nil {
+			if err := fn(s.attrs); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, attrs := range s.objects {
+			if err := fn(attrs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package objutil
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+
+	"github.com/couchbase/tools-common/objstore/objcli"
+	"github.com/couchbase/tools-common/objstore/objval"
+)
+
+func TestIterateObjectsOptionsDefaults(t *testing.T) {
+	options := IterateObjectsOptions{}
+	options.defaults()
+	require.Equal(t, "/", options.Delimiter)
+	require.Equal(t, context.Background(), options.Context)
+}
+
+func newIterateTestClient(t *testing.T, keys ...string) *objcli.TestClient {
+	client := objcli.NewTestClient(t, objval.ProviderAWS)
+
+	for _, key := range keys {
+		objcli.TestUploadRAW(t, client, key, []byte(key))
+	}
+
+	return client
+}
+
+func TestIterateObjects(t *testing.T) {
+	keys := []string{
+		"prefix/a/1",
+		"prefix/a/2",
+		"prefix/b/1",
+		"prefix/b/c/1",
+		"prefix/c",
+		"prefix/d/1",
+		"other/1",
+	}
+
+	type test struct {
+		name     string
+		prefix   string
+		include  []*regexp.Regexp
+		exclude  []*regexp.Regexp
+		expected []string
+	}
+
+	tests := []*test{
+		{
+			name:     "All",
+			expected: keys,
+		},
+		{
+			name:     "WithPrefix",
+			prefix:   "prefix/",
+			expected: keys[:6],
+		},
+		{
+			name:     "WithInclude",
+			prefix:   "prefix/",
+			include:  []*regexp.Regexp{regexp.MustCompile(`^1$`)},
+			expected: []string{"prefix/a/1", "prefix/b/1", "prefix/b/c/1", "prefix/d/1"},
+		},
+		{
+			name:     "WithExclude",
+			prefix:   "prefix/",
+			exclude:  []*regexp.Regexp{regexp.MustCompile(`^prefix/b/`)},
+			expected: []string{"prefix/a/1", "prefix/a/2", "prefix/c", "prefix/d/1"},
+		},
+	}
+
+	for _, test := range tests {
+		for _, deterministic := range []bool{false, true} {
+			t.Run(fmt.Sprintf(`%s/{"deterministic":%t}`, test.name, deterministic), func(t *testing.T) {
+				var (
+					client = newIterateTestClient(t, keys...)
+					listed = make([]string, 0)
+				)
+
+				err := IterateObjects(IterateObjectsOptions{
+					Client:        client,
+					Bucket:        "bucket",
+					Prefix:        test.prefix,
+					Include:       test.include,
+					Exclude:       test.exclude,
+					Deterministic: deterministic,
+					Func:          func(attrs *objval.ObjectAttrs) error { listed = append(listed, attrs.Key); return nil },
+				})
+				require.NoError(t, err)
+
+				if deterministic {
+					expected := slices.Clone(test.expected)
+					slices.Sort(expected)
+
+					require.Equal(t, expected, listed)
+				} else {
+					require.ElementsMatch(t, test.expected, listed)
+				}
+			})
+		}
+	}
+}
+
+func TestIterateObjectsMaxParallelism(t *testing.T) {
+	var (
+		client = newIterateTestClient(t, "a/1", "b/1", "c/1", "d/1")
+		listed = make([]string, 0)
+	)
+
+	err := IterateObjects(IterateObjectsOptions{
+		Client:         client,
+		Bucket:         "bucket",
+		MaxParallelism: 1,
+		Func:           func(attrs *objval.ObjectAttrs) error { listed = append(listed, attrs.Key); return nil },
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a/1", "b/1", "c/1", "d/1"}, listed)
+}
+
+func TestIterateObjectsIncludeAndExclude(t *testing.T) {
+	err := IterateObjects(IterateObjectsOptions{
+		Client:  newIterateTestClient(t),
+		Bucket:  "bucket",
+		Include: []*regexp.Regexp{},
+		Exclude: []*regexp.Regexp{},
+	})
+	require.ErrorIs(t, err, objcli.ErrIncludeAndExcludeAreMutuallyExclusive)
+}
+
+func TestIterateObjectsFuncError(t *testing.T) {
+	for _, deterministic := range []bool{false, true} {
+		t.Run(fmt.Sprintf(`{"deterministic":%t}`, deterministic), func(t *testing.T) {
+			err := IterateObjects(IterateObjectsOptions{
+				Client:        newIterateTestClient(t, "a", "b/1", "c/1", "c/2"),
+				Bucket:        "bucket",
+				Deterministic: deterministic,
+				Func:          func(attrs *objval.ObjectAttrs) error { return assert.AnError },
+			})
+			require.Equal(t, assert.AnError, err)
+		})
+	}
+}
+
+func TestIterateObjectsFuncErrorInShard(t *testing.T) {
+	for _, deterministic := range []bool{false, true} {
+		t.Run(fmt.Sprintf(`{"deterministic":%t}`, deterministic), func(t *testing.T) {
+			fn := func(attrs *objval.ObjectAttrs) error {
+				if attrs.Key == "c/2" {
+					return assert.AnError
+				}
+
+				return nil
+			}
+
+			err := IterateObjects(IterateObjectsOptions{
+				Client:        newIterateTestClient(t, "a", "b/1", "c/1", "c/2", "d/1"),
+				Bucket:        "bucket",
+				Deterministic: deterministic,
+				Func:          fn,
+			})
+			require.Equal(t, assert.AnError, err)
+		})
+	}
+}
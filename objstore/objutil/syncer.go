@@ -140,10 +140,13 @@ func (s *Syncer) Download(source, destination *CloudOrFileURL) error {
 
 	dl := func(ctx context.Context, key string) error {
 		var (
-			newSource      = CloudOrFileURL{Bucket: source.Bucket, Provider: source.Provider, Path: key}
+			newSource      = *source
 			newDestination = destination.Join(strings.TrimPrefix(key, keyPrefix))
 		)
 
+		// Copy the source so that the region/endpoint/account are retained, only the path differs
+		newSource.Path = key
+
 		return s.downloadFile(ctx, &newSource, newDestination)
 	}
 
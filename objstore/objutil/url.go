@@ -2,9 +2,11 @@ package objutil
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/couchbase/tools-common/objstore/objval"
@@ -13,12 +15,13 @@ import (
 // ErrInvalidCloudPath returns if the user has incorrectly used the cloud style scheme prefixed argument; the error
 // message indicates/display the correct usage to the user.
 type ErrInvalidCloudPath struct {
-	prefix string
+	prefix   string
+	provider objval.Provider
 }
 
 func (e *ErrInvalidCloudPath) Error() string {
 	medium := "BUCKET"
-	if e.prefix == "az://" {
+	if e.provider == objval.ProviderAzure {
 		medium = "CONTAINER"
 	}
 
@@ -31,6 +34,18 @@ type CloudOrFileURL struct {
 	Provider objval.Provider
 	Bucket   string
 	Path     string
+
+	// Region is the region where the bucket resides, populated from the 'region' query parameter or the host for AWS
+	// URLs.
+	Region string
+
+	// Endpoint is the endpoint which should be used to access the bucket, populated from the 'endpoint' query
+	// parameter.
+	Endpoint string
+
+	// Account is the storage account which contains the container, populated from the 'account' query parameter or
+	// the host for Azure URLs.
+	Account string
 }
 
 func (u *CloudOrFileURL) String() string {
@@ -38,7 +53,13 @@ func (u *CloudOrFileURL) String() string {
 		return fmt.Sprintf("file://%s", u.Path)
 	}
 
-	return fmt.Sprintf("%s%s/%s", u.Provider.ToScheme(), u.Bucket, u.Path)
+	str := fmt.Sprintf("%s%s/%s", u.Provider.ToScheme(), u.Bucket, u.Path)
+
+	if query := u.query().Encode(); query != "" {
+		str += "?" + query
+	}
+
+	return str
 }
 
 // Join returns a new CloudOrFileURL with args appended to u.
@@ -46,7 +67,39 @@ func (u *CloudOrFileURL) Join(args ...string) *CloudOrFileURL {
 	parts := []string{u.Path}
 	parts = append(parts, args...)
 
-	return &CloudOrFileURL{Bucket: u.Bucket, Path: path.Join(parts...), Provider: u.Provider}
+	joined := *u
+	joined.Path = path.Join(parts...)
+
+	return &joined
+}
+
+// query returns the query parameters which represent the options for this URL.
+func (u *CloudOrFileURL) query() url.Values {
+	query := make(url.Values)
+
+	for key, value := range map[string]string{"region": u.Region, "endpoint": u.Endpoint, "account": u.Account} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	return query
+}
+
+// setOptions populates the options for this URL using the given query parameters, options which have already been set
+// (for example, using the host) are overridden.
+func (u *CloudOrFileURL) setOptions(query url.Values) {
+	if region := query.Get("region"); region != "" {
+		u.Region = region
+	}
+
+	if endpoint := query.Get("endpoint"); endpoint != "" {
+		u.Endpoint = endpoint
+	}
+
+	if account := query.Get("account"); account != "" {
+		u.Account = account
+	}
 }
 
 // respectTrailingSeparator will add a trailing sep to current if original has one and remove it if current has one but
@@ -82,7 +135,15 @@ func parseFileURL(argument string) (*CloudOrFileURL, error) {
 func parseCloudURL(argument, prefix string) (*CloudOrFileURL, error) {
 	var (
 		provider  objval.Provider
-		supported = []string{"file://", "s3://", "az://", "gs://"}
+		supported = []string{
+			"file://",
+			"s3://",
+			"az://",
+			"gs://",
+			"https://${BUCKET_NAME}.s3.${REGION}.amazonaws.com",
+			"https://${ACCOUNT_NAME}.blob.core.windows.net",
+			"https://storage.googleapis.com",
+		}
 	)
 
 	switch prefix {
@@ -92,14 +153,54 @@ func parseCloudURL(argument, prefix string) (*CloudOrFileURL, error) {
 		provider = objval.ProviderGCP
 	case "s3://":
 		provider = objval.ProviderAWS
+	case "http://", "https://":
+		return parseHTTPURL(argument)
 	default:
 		return nil, fmt.Errorf("cloud prefix provided for an unsupported cloud provider, expected [%s]",
 			strings.Join(supported, ", "))
 	}
 
-	split := strings.Split(strings.TrimPrefix(argument, prefix), "/")
+	argument, query := splitOptions(argument)
+
+	parsed, err := parseBucketAndPath(provider, prefix, strings.TrimPrefix(argument, prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.setOptions(query)
+
+	return parsed, nil
+}
+
+// splitOptions splits the query string from the given argument, where it only contains supported options.
+//
+// NOTE: Object keys may contain a '?', so we only treat the suffix as options where it's a valid query string where all
+// the keys are supported options.
+func splitOptions(argument string) (string, url.Values) {
+	idx := strings.LastIndex(argument, "?")
+	if idx == -1 {
+		return argument, nil
+	}
+
+	query, err := url.ParseQuery(argument[idx+1:])
+	if err != nil || len(query) == 0 {
+		return argument, nil
+	}
+
+	for key := range query {
+		if key != "region" && key != "endpoint" && key != "account" {
+			return argument, nil
+		}
+	}
+
+	return argument[:idx], query
+}
+
+// parseBucketAndPath parses the given path, where the first element is the bucket followed by the path to the object.
+func parseBucketAndPath(provider objval.Provider, prefix, argument string) (*CloudOrFileURL, error) {
+	split := strings.Split(argument, "/")
 	if len(split) == 0 || split[0] == "" {
-		return nil, &ErrInvalidCloudPath{prefix: prefix}
+		return nil, &ErrInvalidCloudPath{prefix: prefix, provider: provider}
 	}
 
 	return &CloudOrFileURL{
@@ -109,8 +210,103 @@ func parseCloudURL(argument, prefix string) (*CloudOrFileURL, error) {
 	}, nil
 }
 
+// awsHostRegex matches the host for virtual-hosted and path-style S3 URLs, capturing the bucket (for virtual-hosted
+// URLs) and the region (where present).
+var awsHostRegex = regexp.MustCompile(`^(?:(.+)\.)?s3(?:[.-]dualstack)?(?:[.-]([a-z0-9-]+))?\.amazonaws\.com(?:\.cn)?$`)
+
+const (
+	// azureHostSuffix is the host suffix for Azure blob storage URLs, the account name is the first label in the host.
+	azureHostSuffix = ".blob.core.windows.net"
+
+	// gcpHost is the host used for path-style Google Storage URLs.
+	gcpHost = "storage.googleapis.com"
+
+	// gcpAuthenticatedHost is the host used for path-style Google Storage URLs copied from the console.
+	gcpAuthenticatedHost = "storage.cloud.google.com"
+)
+
+// parseHTTPURL parses an http(s) URL for one of the supported cloud providers into a CloudOrFileURL; both
+// virtual-hosted and path-style URLs are supported.
+func parseHTTPURL(argument string) (*CloudOrFileURL, error) {
+	parsed, err := url.Parse(argument)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	var (
+		host    = strings.ToLower(parsed.Hostname())
+		prefix  = fmt.Sprintf("%s://%s/", parsed.Scheme, parsed.Host)
+		trimmed = strings.TrimPrefix(parsed.Path, "/")
+		cloud   *CloudOrFileURL
+	)
+
+	switch {
+	case strings.HasSuffix(host, azureHostSuffix):
+		cloud, err = parseBucketAndPath(objval.ProviderAzure, prefix, trimmed)
+		if err == nil {
+			cloud.Account = strings.TrimSuffix(host, azureHostSuffix)
+		}
+	case host == gcpHost || host == gcpAuthenticatedHost:
+		cloud, err = parseBucketAndPath(objval.ProviderGCP, prefix, trimmed)
+	case strings.HasSuffix(host, "."+gcpHost):
+		cloud = newVirtualHostedURL(objval.ProviderGCP, strings.TrimSuffix(host, "."+gcpHost), trimmed)
+	case awsHostRegex.MatchString(host):
+		cloud, err = parseAWSURL(host, prefix, trimmed)
+	default:
+		return nil, fmt.Errorf("unsupported host '%s', expected an AWS, Azure or Google Storage URL", parsed.Host)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	cloud.setOptions(parsed.Query())
+
+	return cloud, nil
+}
+
+// parseAWSURL parses the given S3 host/path into a CloudOrFileURL, the bucket and region are determined using the host
+// where possible.
+func parseAWSURL(host, prefix, trimmed string) (*CloudOrFileURL, error) {
+	var (
+		matches = awsHostRegex.FindStringSubmatch(host)
+		bucket  = matches[1]
+		region  = matches[2]
+		cloud   *CloudOrFileURL
+		err     error
+	)
+
+	if bucket != "" {
+		cloud = newVirtualHostedURL(objval.ProviderAWS, bucket, trimmed)
+	} else {
+		cloud, err = parseBucketAndPath(objval.ProviderAWS, prefix, trimmed)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	cloud.Region = region
+
+	return cloud, nil
+}
+
+// newVirtualHostedURL returns a CloudOrFileURL for a virtual-hosted style URL, where the bucket is determined using the
+// host and the entire path is the path to the object.
+func newVirtualHostedURL(provider objval.Provider, bucket, trimmed string) *CloudOrFileURL {
+	return &CloudOrFileURL{
+		Bucket:   bucket,
+		Path:     respectTrailingSeparator(trimmed, path.Join(trimmed), "/"),
+		Provider: provider,
+	}
+}
+
 // ParseCloudOrFileURL parses a URL which is either a file path or a cloud path. It will automatically convert a local
 // path into an absolute path using the 'fsutil.ConvertToAbsolutePath' function.
+//
+// NOTE: In addition to the 's3://', 'gs://' and 'az://' schemes, http(s) URLs for each of the cloud providers are
+// supported, for example 'https://bucket.s3.us-east-1.amazonaws.com/path'; the 'region', 'endpoint' and 'account'
+// query parameters may be used to populate the corresponding options.
 func ParseCloudOrFileURL(argument string) (*CloudOrFileURL, error) {
 	var prefix string
 
@@ -18,6 +18,9 @@ func TestParseCloudOrFileURL(t *testing.T) {
 		expectedBucket   string
 		expectedPath     string
 		expectedProvider objval.Provider
+		expectedRegion   string
+		expectedEndpoint string
+		expectedAccount  string
 		expectedError    bool
 	}
 
@@ -120,6 +123,147 @@ func TestParseCloudOrFileURL(t *testing.T) {
 			input:         "gs://",
 			expectedError: true,
 		},
+		{
+			name:             "s3-with-options",
+			input:            "s3://bucket/archive?region=us-east-1&endpoint=http%3A%2F%2Flocalhost%3A9000",
+			expectedBucket:   "bucket",
+			expectedPath:     "archive",
+			expectedProvider: objval.ProviderAWS,
+			expectedRegion:   "us-east-1",
+			expectedEndpoint: "http://localhost:9000",
+		},
+		{
+			name:             "s3-with-question-mark-in-path",
+			input:            "s3://bucket/archive?key=value",
+			expectedBucket:   "bucket",
+			expectedPath:     "archive?key=value",
+			expectedProvider: objval.ProviderAWS,
+		},
+		{
+			name:             "az-with-account-option",
+			input:            "az://container/archive?account=account",
+			expectedBucket:   "container",
+			expectedPath:     "archive",
+			expectedProvider: objval.ProviderAzure,
+			expectedAccount:  "account",
+		},
+		{
+			name:             "https-s3-virtual-hosted",
+			input:            "https://bucket.s3.amazonaws.com/path/to/archive",
+			expectedBucket:   "bucket",
+			expectedPath:     "path/to/archive",
+			expectedProvider: objval.ProviderAWS,
+		},
+		{
+			name:             "https-s3-virtual-hosted-with-region",
+			input:            "https://my.bucket.s3.us-west-2.amazonaws.com/path/to/archive/",
+			expectedBucket:   "my.bucket",
+			expectedPath:     "path/to/archive/",
+			expectedProvider: objval.ProviderAWS,
+			expectedRegion:   "us-west-2",
+		},
+		{
+			name:             "https-s3-virtual-hosted-with-legacy-region",
+			input:            "https://bucket.s3-eu-west-1.amazonaws.com/archive",
+			expectedBucket:   "bucket",
+			expectedPath:     "archive",
+			expectedProvider: objval.ProviderAWS,
+			expectedRegion:   "eu-west-1",
+		},
+		{
+			name:             "https-s3-virtual-hosted-no-path",
+			input:            "https://bucket.s3.amazonaws.com",
+			expectedBucket:   "bucket",
+			expectedProvider: objval.ProviderAWS,
+		},
+		{
+			name:             "https-s3-path-style",
+			input:            "https://s3.amazonaws.com/bucket/archive",
+			expectedBucket:   "bucket",
+			expectedPath:     "archive",
+			expectedProvider: objval.ProviderAWS,
+		},
+		{
+			name:             "https-s3-path-style-with-region",
+			input:            "https://s3.us-east-2.amazonaws.com/bucket//archive",
+			expectedBucket:   "bucket",
+			expectedPath:     "archive",
+			expectedProvider: objval.ProviderAWS,
+			expectedRegion:   "us-east-2",
+		},
+		{
+			name:             "https-s3-path-style-region-overridden-by-query",
+			input:            "https://s3.us-east-2.amazonaws.com/bucket/archive?region=eu-west-2&unknown=value",
+			expectedBucket:   "bucket",
+			expectedPath:     "archive",
+			expectedProvider: objval.ProviderAWS,
+			expectedRegion:   "eu-west-2",
+		},
+		{
+			name:             "https-s3-dualstack",
+			input:            "https://bucket.s3.dualstack.ap-south-1.amazonaws.com/archive",
+			expectedBucket:   "bucket",
+			expectedPath:     "archive",
+			expectedProvider: objval.ProviderAWS,
+			expectedRegion:   "ap-south-1",
+		},
+		{
+			name:          "https-s3-path-style-no-bucket",
+			input:         "https://s3.amazonaws.com/",
+			expectedError: true,
+		},
+		{
+			name:             "https-gs-path-style",
+			input:            "https://storage.googleapis.com/bucket/path/to/archive",
+			expectedBucket:   "bucket",
+			expectedPath:     "path/to/archive",
+			expectedProvider: objval.ProviderGCP,
+		},
+		{
+			name:             "https-gs-authenticated-path-style",
+			input:            "https://storage.cloud.google.com/bucket/archive",
+			expectedBucket:   "bucket",
+			expectedPath:     "archive",
+			expectedProvider: objval.ProviderGCP,
+		},
+		{
+			name:             "https-gs-virtual-hosted",
+			input:            "https://bucket.storage.googleapis.com/archive",
+			expectedBucket:   "bucket",
+			expectedPath:     "archive",
+			expectedProvider: objval.ProviderGCP,
+		},
+		{
+			name:          "https-gs-no-bucket",
+			input:         "https://storage.googleapis.com",
+			expectedError: true,
+		},
+		{
+			name:             "https-az",
+			input:            "https://account.blob.core.windows.net/container/path/to/archive",
+			expectedBucket:   "container",
+			expectedPath:     "path/to/archive",
+			expectedProvider: objval.ProviderAzure,
+			expectedAccount:  "account",
+		},
+		{
+			name:             "https-az-with-endpoint",
+			input:            "https://account.blob.core.windows.net/container?endpoint=http%3A%2F%2F127.0.0.1%3A10000",
+			expectedBucket:   "container",
+			expectedProvider: objval.ProviderAzure,
+			expectedEndpoint: "http://127.0.0.1:10000",
+			expectedAccount:  "account",
+		},
+		{
+			name:          "https-az-no-container",
+			input:         "https://account.blob.core.windows.net/",
+			expectedError: true,
+		},
+		{
+			name:          "https-unsupported-host",
+			input:         "https://example.com/bucket/archive",
+			expectedError: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -134,6 +278,9 @@ func TestParseCloudOrFileURL(t *testing.T) {
 			require.Equal(t, test.expectedBucket, url.Bucket)
 			require.Equal(t, test.expectedPath, url.Path)
 			require.Equal(t, test.expectedProvider, url.Provider)
+			require.Equal(t, test.expectedRegion, url.Region)
+			require.Equal(t, test.expectedEndpoint, url.Endpoint)
+			require.Equal(t, test.expectedAccount, url.Account)
 		})
 	}
 }
@@ -141,5 +288,68 @@ func TestParseCloudOrFileURL(t *testing.T) {
 func TestParseCloudOrFileURLArgumentSuggestionsInError(t *testing.T) {
 	_, err := ParseCloudOrFileURL("unknown://path/to/archive")
 	require.Error(t, err)
-	require.True(t, errutil.Contains(err, "[file://, s3://, az://, gs://]"))
+	require.True(t, errutil.Contains(err, "[file://, s3://, az://, gs://, https://${BUCKET_NAME}.s3.${REGION}"+
+		".amazonaws.com, https://${ACCOUNT_NAME}.blob.core.windows.net, https://storage.googleapis.com]"))
+}
+
+func TestParseCloudOrFileURLInvalidContainerError(t *testing.T) {
+	_, err := ParseCloudOrFileURL("https://account.blob.core.windows.net/")
+	require.Error(t, err)
+	require.True(t, errutil.Contains(err, "'https://account.blob.core.windows.net/${CONTAINER_NAME}/${PATH}'"))
+}
+
+func TestCloudOrFileURLString(t *testing.T) {
+	type test struct {
+		name     string
+		url      *CloudOrFileURL
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name:     "File",
+			url:      &CloudOrFileURL{Path: "/path/to/archive"},
+			expected: "file:///path/to/archive",
+		},
+		{
+			name:     "Cloud",
+			url:      &CloudOrFileURL{Provider: objval.ProviderAWS, Bucket: "bucket", Path: "archive"},
+			expected: "s3://bucket/archive",
+		},
+		{
+			name: "CloudWithOptions",
+			url: &CloudOrFileURL{
+				Provider: objval.ProviderAzure,
+				Bucket:   "container",
+				Path:     "archive",
+				Account:  "account",
+				Endpoint: "http://127.0.0.1:10000",
+			},
+			expected: "az://container/archive?account=account&endpoint=http%3A%2F%2F127.0.0.1%3A10000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.url.String())
+
+			if test.url.Provider == objval.ProviderNone {
+				return
+			}
+
+			parsed, err := ParseCloudOrFileURL(test.expected)
+			require.NoError(t, err)
+			require.Equal(t, test.url, parsed)
+		})
+	}
+}
+
+func TestCloudOrFileURLJoinPreservesOptions(t *testing.T) {
+	url := &CloudOrFileURL{Provider: objval.ProviderAWS, Bucket: "bucket", Path: "path", Region: "us-east-1"}
+
+	require.Equal(
+		t,
+		&CloudOrFileURL{Provider: objval.ProviderAWS, Bucket: "bucket", Path: "path/to/archive", Region: "us-east-1"},
+		url.Join("to", "archive"),
+	)
 }
@@ -0,0 +1,74 @@
+package queryutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+// EndpointQueryService is used to execute N1QL/SQL++ statements.
+const EndpointQueryService cbrest.Endpoint = "/query/service"
+
+// RowFunc is the function used when streaming the results of a query, it will be called once for each row in the order
+// they are returned by the Query Service.
+//
+// NOTE: The given row is only valid until the function returns, it should be copied if it needs to be retained.
+type RowFunc func(row json.RawMessage) error
+
+// Client is a wrapper around the 'cbrest' client which implements methods to execute queries using the Query Service.
+type Client struct {
+	*cbrest.Client
+}
+
+// NewClient creates a new client which will dispatch requests using the given 'cbrest' client.
+func NewClient(client *cbrest.Client) *Client {
+	return &Client{client}
+}
+
+// Query executes the given query, streaming the rows to the provided function as they are returned by the Query
+// Service rather than buffering the entire response; the query metadata is returned once all the rows have been
+// processed.
+//
+// Failures reported before results are returned are reported as a 'cbrest.ServiceError', failures reported after
+// results have begun being returned are reported as a 'QueryError' (in which case the metadata is also returned).
+func (c *Client) Query(ctx context.Context, options QueryOptions, fn RowFunc) (*Metadata, error) {
+	if options.Statement == "" {
+		return nil, ErrStatementRequired
+	}
+
+	body, err := options.Encode()
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	request := &cbrest.Request{
+		Body:               body,
+		ContentType:        cbrest.ContentTypeJSON,
+		Endpoint:           EndpointQueryService,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            cbrest.ServiceQuery,
+	}
+
+	// Queries may run for a long time, the server side timeout and the given context are used instead
+	reader, err := c.ExecuteReaderWithContext(ctx, request)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+	defer reader.Close()
+
+	metadata, err := DecodeResponse(reader, fn)
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	return metadata, metadata.Err()
+}
+
+// Execute executes the given query, discarding any returned rows, this should be used for statements which are not
+// expected to return results e.g. DDL statements.
+func (c *Client) Execute(ctx context.Context, options QueryOptions) (*Metadata, error) {
+	return c.Query(ctx, options, func(_ json.RawMessage) error { return nil })
+}
@@ -0,0 +1,173 @@
+package queryutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	handlers := make(cbrest.TestHandlers)
+	handlers.Add(http.MethodPost, string(EndpointQueryService), handler)
+
+	nodes := cbrest.TestNodes{{Services: []cbrest.Service{cbrest.ServiceQuery}}}
+
+	return NewClient(cbrest.NewTestClusterClient(t, nodes, handlers))
+}
+
+func TestClientQuery(t *testing.T) {
+	var actual map[string]any
+
+	client := newTestClient(t, func(writer http.ResponseWriter, request *http.Request) {
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&actual))
+
+		flusher, ok := writer.(http.Flusher)
+		require.True(t, ok)
+
+		_, err := writer.Write([]byte(`{"requestID":"request","clientContextID":"client","signature":{"*":"*"},` +
+			`"results":[`))
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			if i != 0 {
+				_, err = writer.Write([]byte(","))
+				require.NoError(t, err)
+			}
+
+			_, err = fmt.Fprintf(writer, `{"id":%d}`, i)
+			require.NoError(t, err)
+
+			flusher.Flush()
+		}
+
+		_, err = writer.Write([]byte(`],"status":"success","warnings":[{"code":1080,"msg":"warning"}],` +
+			`"metrics":{"elapsedTime":"12.5ms","executionTime":"1.5µs","resultCount":3,"resultSize":24,` +
+			`"warningCount":1}}`))
+		require.NoError(t, err)
+	})
+
+	var rows []string
+
+	metadata, err := client.Query(context.Background(), QueryOptions{
+		Statement:       "SELECT * FROM default",
+		ClientContextID: "client",
+		ScanConsistency: ScanConsistencyRequestPlus,
+	}, func(row json.RawMessage) error {
+		rows = append(rows, string(row))
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]any{
+		"statement":         "SELECT * FROM default",
+		"client_context_id": "client",
+		"scan_consistency":  "request_plus",
+	}, actual)
+
+	require.Equal(t, []string{`{"id":0}`, `{"id":1}`, `{"id":2}`}, rows)
+
+	expected := &Metadata{
+		RequestID:       "request",
+		ClientContextID: "client",
+		Signature:       json.RawMessage(`{"*":"*"}`),
+		Status:          StatusSuccess,
+		Metrics: &Metrics{
+			ElapsedTime:   12500 * time.Microsecond,
+			ExecutionTime: 1500 * time.Nanosecond,
+			ResultCount:   3,
+			ResultSize:    24,
+			WarningCount:  1,
+		},
+		Warnings: []Warning{{Code: 1080, Message: "warning"}},
+	}
+
+	require.Equal(t, expected, metadata)
+}
+
+func TestClientQueryRowFuncError(t *testing.T) {
+	client := newTestClient(t, cbrest.NewTestHandler(t, http.StatusOK,
+		[]byte(`{"results":[{"id":0},{"id":1}],"status":"success"}`)))
+
+	rowErr := errors.New("row error")
+
+	var rows int
+
+	_, err := client.Query(context.Background(), QueryOptions{Statement: "SELECT 1"}, func(_ json.RawMessage) error {
+		rows++
+		return rowErr
+	})
+	require.ErrorIs(t, err, rowErr)
+	require.Equal(t, 1, rows)
+}
+
+func TestClientQueryErrors(t *testing.T) {
+	type test struct {
+		name   string
+		status int
+		body   string
+		check  func(t *testing.T, metadata *Metadata, err error)
+	}
+
+	tests := []*test{
+		{
+			name:   "StatusCode",
+			status: http.StatusBadRequest,
+			body:   `{"requestID":"request","errors":[{"code":3000,"msg":"syntax error"}],"status":"errors"}`,
+			check: func(t *testing.T, metadata *Metadata, err error) {
+				var serviceErr *cbrest.ServiceError
+				require.ErrorAs(t, err, &serviceErr)
+				require.Equal(t, http.StatusBadRequest, serviceErr.Status())
+				require.True(t, IsQueryErrorCode(err, 3000))
+				require.Nil(t, metadata)
+			},
+		},
+		{
+			name:   "Fatal",
+			status: http.StatusOK,
+			body:   `{"results":[{"id":0}],"errors":[{"code":5000,"msg":"fatal","retry":true}],"status":"fatal"}`,
+			check: func(t *testing.T, metadata *Metadata, err error) {
+				var queryErr *QueryError
+				require.ErrorAs(t, err, &queryErr)
+				require.Equal(t, StatusFatal, queryErr.Status())
+				require.Equal(t, []Error{{Code: 5000, Message: "fatal", Retry: true}}, queryErr.Errors())
+				require.True(t, IsQueryErrorCode(err, 5000))
+				require.NotNil(t, metadata)
+			},
+		},
+		{
+			name:   "NotJSON",
+			status: http.StatusUnauthorized,
+			body:   "Unauthorized",
+			check: func(t *testing.T, metadata *Metadata, err error) {
+				var authErr *cbrest.AuthenticationError
+				require.ErrorAs(t, err, &authErr)
+				require.False(t, IsQueryErrorCode(err, 3000))
+				require.Nil(t, metadata)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newTestClient(t, cbrest.NewTestHandler(t, test.status, []byte(test.body)))
+
+			metadata, err := client.Execute(context.Background(), QueryOptions{Statement: "SELECT 1"})
+			test.check(t, metadata, err)
+		})
+	}
+}
+
+func TestClientQueryNoStatement(t *testing.T) {
+	client := &Client{}
+
+	_, err := client.Execute(context.Background(), QueryOptions{})
+	require.ErrorIs(t, err, ErrStatementRequired)
+}
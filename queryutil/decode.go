@@ -0,0 +1,117 @@
+package queryutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DecodeResponse incrementally decodes a Query Service response body, dispatching each row in the 'results' array to
+// the given function; the remaining attributes are returned as the query metadata.
+//
+// NOTE: The Analytics Service returns responses in the same format, so this may also be used to decode the results of
+// analytics statements.
+func DecodeResponse(reader io.Reader, fn RowFunc) (*Metadata, error) {
+	decoder := json.NewDecoder(reader)
+
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	remaining := make(map[string]json.RawMessage)
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key: %w", err)
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token '%v', expected a key", token)
+		}
+
+		if key == "results" {
+			err = decodeRows(decoder, fn)
+			if err != nil {
+				return nil, err // Purposefully not wrapped
+			}
+
+			continue
+		}
+
+		var value json.RawMessage
+
+		err = decoder.Decode(&value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode value for key '%s': %w", key, err)
+		}
+
+		remaining[key] = value
+	}
+
+	err = expectDelim(decoder, '}')
+	if err != nil {
+		return nil, err // Purposefully not wrapped
+	}
+
+	// Purposefully ignored, marshaling a map of raw messages can't fail
+	encoded, _ := json.Marshal(remaining)
+
+	var metadata *Metadata
+
+	err = json.Unmarshal(encoded, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// decodeRows decodes the 'results' array, dispatching each row to the given function.
+func decodeRows(decoder *json.Decoder, fn RowFunc) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode results: %w", err)
+	}
+
+	// The Query Service may return 'null' results e.g. when a statement fails during planning
+	if token == nil {
+		return nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("unexpected token '%v', expected '['", token)
+	}
+
+	for decoder.More() {
+		var row json.RawMessage
+
+		err = decoder.Decode(&row)
+		if err != nil {
+			return fmt.Errorf("failed to decode row: %w", err)
+		}
+
+		err = fn(row)
+		if err != nil {
+			return err // Purposefully not wrapped
+		}
+	}
+
+	return expectDelim(decoder, ']')
+}
+
+// expectDelim reads the next token from the decoder, returning an error if it's not the expected delimiter.
+func expectDelim(decoder *json.Decoder, expected json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode token: %w", err)
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("unexpected token '%v', expected '%s'", token, expected)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package queryutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+// ErrStatementRequired is returned if the user attempts to execute a query without providing a statement.
+var ErrStatementRequired = errors.New("a statement is required")
+
+// QueryError is returned if a query fails after the Query Service has started returning results, in which case the
+// failure is reported in the query metadata; failures reported before then are reported as a 'cbrest.ServiceError'.
+//
+// NOTE: The Analytics Service reports failures in the same way, so this error is also returned for analytics
+// statements.
+type QueryError struct {
+	status Status
+	errors []Error
+}
+
+func (e *QueryError) Error() string {
+	msgs := make([]string, 0, len(e.errors))
+	for _, err := range e.errors {
+		msgs = append(msgs, fmt.Sprintf("%d: %s", err.Code, err.Message))
+	}
+
+	return fmt.Sprintf("query completed with status '%s': %s", e.status, strings.Join(msgs, "; "))
+}
+
+// Status returns the status of the query, as reported in the query metadata.
+func (e *QueryError) Status() Status {
+	return e.status
+}
+
+// Errors returns a copy of the errors returned by the Query Service.
+func (e *QueryError) Errors() []Error {
+	return slices.Clone(e.errors)
+}
+
+// HasCode returns a boolean indicating whether the Query Service returned an error with the given code.
+func (e *QueryError) HasCode(code int) bool {
+	return slices.IndexFunc(e.errors, func(err Error) bool { return err.Code == code }) != -1
+}
+
+// IsQueryErrorCode returns a boolean indicating whether the given error is a 'QueryError' or 'cbrest.ServiceError'
+// which contains an error with the given code i.e. whether the query failed with the given code, regardless of whether
+// it was reported before or after results were returned.
+func IsQueryErrorCode(err error, code int) bool {
+	var queryErr *QueryError
+	return errors.As(err, &queryErr) && queryErr.HasCode(code) || cbrest.IsServiceErrorCode(err, code)
+}
@@ -0,0 +1,123 @@
+package queryutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Status represents the status of a query, as returned by the Query Service.
+type Status string
+
+const (
+	// StatusSuccess indicates that the query completed successfully.
+	StatusSuccess Status = "success"
+
+	// StatusErrors indicates that the query failed, the errors will be returned in the metadata.
+	StatusErrors Status = "errors"
+
+	// StatusFatal indicates that the query failed after results had started being returned.
+	StatusFatal Status = "fatal"
+
+	// StatusTimeout indicates that the query reached the server side timeout.
+	StatusTimeout Status = "timeout"
+)
+
+// Metadata represents the non-row attributes returned by the Query Service after executing a query.
+type Metadata struct {
+	RequestID       string          `json:"requestID"`
+	ClientContextID string          `json:"clientContextID"`
+	Signature       json.RawMessage `json:"signature"`
+	Status          Status          `json:"status"`
+	Metrics         *Metrics        `json:"metrics"`
+	Warnings        []Warning       `json:"warnings"`
+	Errors          []Error         `json:"errors"`
+	Profile         json.RawMessage `json:"profile"`
+}
+
+// Err returns a 'QueryError' if the metadata reports that the query failed, otherwise <nil>.
+func (m *Metadata) Err() error {
+	if m.Status == StatusSuccess && len(m.Errors) == 0 {
+		return nil
+	}
+
+	return &QueryError{status: m.Status, errors: m.Errors}
+}
+
+// Metrics represents the metrics returned by the Query Service after executing a query.
+type Metrics struct {
+	ElapsedTime   time.Duration
+	ExecutionTime time.Duration
+	ResultCount   uint64
+	ResultSize    uint64
+	MutationCount uint64
+	SortCount     uint64
+	ErrorCount    uint64
+	WarningCount  uint64
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, durations are encoded by the Query Service as strings.
+func (m *Metrics) UnmarshalJSON(data []byte) error {
+	type overlay struct {
+		ElapsedTime   string `json:"elapsedTime"`
+		ExecutionTime string `json:"executionTime"`
+		ResultCount   uint64 `json:"resultCount"`
+		ResultSize    uint64 `json:"resultSize"`
+		MutationCount uint64 `json:"mutationCount"`
+		SortCount     uint64 `json:"sortCount"`
+		ErrorCount    uint64 `json:"errorCount"`
+		WarningCount  uint64 `json:"warningCount"`
+	}
+
+	var decoded overlay
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	*m = Metrics{
+		ResultCount:   decoded.ResultCount,
+		ResultSize:    decoded.ResultSize,
+		MutationCount: decoded.MutationCount,
+		SortCount:     decoded.SortCount,
+		ErrorCount:    decoded.ErrorCount,
+		WarningCount:  decoded.WarningCount,
+	}
+
+	m.ElapsedTime, err = parseDuration(decoded.ElapsedTime)
+	if err != nil {
+		return fmt.Errorf("failed to parse elapsed time: %w", err)
+	}
+
+	m.ExecutionTime, err = parseDuration(decoded.ExecutionTime)
+	if err != nil {
+		return fmt.Errorf("failed to parse execution time: %w", err)
+	}
+
+	return nil
+}
+
+// parseDuration parses a duration returned by the Query Service, where an empty string is treated as zero.
+func parseDuration(duration string) (time.Duration, error) {
+	if duration == "" {
+		return 0, nil
+	}
+
+	return time.ParseDuration(duration)
+}
+
+// Warning represents a warning returned by the Query Service.
+type Warning struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+// Error represents an error returned by the Query Service.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+
+	// Retry is a hint from the Query Service indicating whether the query may succeed if retried.
+	Retry bool `json:"retry"`
+}
@@ -0,0 +1,106 @@
+package queryutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScanConsistency represents the consistency guarantees required when using indexes to satisfy a query.
+type ScanConsistency string
+
+const (
+	// ScanConsistencyNotBounded indicates that the query should use the indexes as they are, this is the fastest but may
+	// return stale results.
+	ScanConsistencyNotBounded ScanConsistency = "not_bounded"
+
+	// ScanConsistencyRequestPlus indicates that the indexes should be updated to include all the mutations which had
+	// occurred at the time the query was submitted, before being used to satisfy the query.
+	ScanConsistencyRequestPlus ScanConsistency = "request_plus"
+)
+
+// QueryOptions encapsulates the options which may be used when executing a statement.
+type QueryOptions struct {
+	// Statement is the N1QL/SQL++ statement to execute.
+	//
+	// NOTE: This attribute is required.
+	Statement string
+
+	// NamedParameters are the values for the named parameters in the statement, the '$' prefix is optional and will be
+	// added if not provided.
+	NamedParameters map[string]any
+
+	// PositionalParameters are the values for the positional parameters in the statement e.g. '$1' or '?'.
+	PositionalParameters []any
+
+	// ScanConsistency is the scan consistency to use when executing the query, the Query Service defaults to
+	// 'ScanConsistencyNotBounded'.
+	ScanConsistency ScanConsistency
+
+	// Timeout is the server side timeout for the query, where zero indicates that the Query Service default should be
+	// used.
+	//
+	// NOTE: The context provided when executing the query should be used to control the client side timeout.
+	Timeout time.Duration
+
+	// ClientContextID is an identifier which is returned in the query metadata, and may be used to identify the query
+	// in the active/completed requests, a random UUID will be used if not provided.
+	ClientContextID string
+
+	// QueryContext is the default bucket/scope used to resolve partial keyspace references in the statement, for example
+	// 'default:`bucket`.`scope`'.
+	QueryContext string
+
+	// ReadOnly indicates that the query service should reject the query if it would modify any data.
+	ReadOnly bool
+}
+
+// Encode returns the JSON encoded request body for the query.
+//
+// NOTE: The Analytics Service accepts the same request body, so this may also be used to execute analytics statements.
+func (o QueryOptions) Encode() ([]byte, error) {
+	body := map[string]any{"statement": o.Statement}
+
+	for name, value := range o.NamedParameters {
+		if !strings.HasPrefix(name, "$") {
+			name = "$" + name
+		}
+
+		body[name] = value
+	}
+
+	if len(o.PositionalParameters) != 0 {
+		body["args"] = o.PositionalParameters
+	}
+
+	if o.ScanConsistency != "" {
+		body["scan_consistency"] = o.ScanConsistency
+	}
+
+	if o.Timeout != 0 {
+		body["timeout"] = o.Timeout.String()
+	}
+
+	body["client_context_id"] = o.ClientContextID
+	if o.ClientContextID == "" {
+		body["client_context_id"] = uuid.NewString()
+	}
+
+	if o.QueryContext != "" {
+		body["query_context"] = o.QueryContext
+	}
+
+	if o.ReadOnly {
+		body["readonly"] = true
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	return encoded, nil
+}
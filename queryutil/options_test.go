@@ -0,0 +1,67 @@
+package queryutil
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryOptionsEncode(t *testing.T) {
+	type test struct {
+		name     string
+		options  QueryOptions
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name:     "Statement",
+			options:  QueryOptions{Statement: "SELECT 1", ClientContextID: "id"},
+			expected: `{"statement":"SELECT 1","client_context_id":"id"}`,
+		},
+		{
+			name: "AllOptions",
+			options: QueryOptions{
+				Statement:            "SELECT * FROM default WHERE a = $a AND b = $b AND c = $1",
+				NamedParameters:      map[string]any{"a": 1, "$b": "b"},
+				PositionalParameters: []any{true},
+				ScanConsistency:      ScanConsistencyRequestPlus,
+				Timeout:              75 * time.Second,
+				ClientContextID:      "id",
+				QueryContext:         "default:`bucket`.`scope`",
+				ReadOnly:             true,
+			},
+			expected: `{
+  "statement": "SELECT * FROM default WHERE a = $a AND b = $b AND c = $1",
+  "$a": 1,
+  "$b": "b",
+  "args": [true],
+  "scan_consistency": "request_plus",
+  "timeout": "1m15s",
+  "client_context_id": "id",
+  "query_context": "default:` + "`bucket`.`scope`" + `",
+  "readonly": true
+}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := test.options.Encode()
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, string(body))
+		})
+	}
+}
+
+func TestQueryOptionsEncodeGeneratesClientContextID(t *testing.T) {
+	body, err := QueryOptions{Statement: "SELECT 1"}.Encode()
+	require.NoError(t, err)
+
+	var decoded map[string]any
+
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	require.NotEmpty(t, decoded["client_context_id"])
+}
@@ -0,0 +1,336 @@
+package xdcrutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+const (
+	// EndpointRemoteClusters is used to list/create remote cluster references.
+	EndpointRemoteClusters cbrest.Endpoint = "/pools/default/remoteClusters"
+
+	// EndpointRemoteCluster is used to delete a single remote cluster reference.
+	EndpointRemoteCluster cbrest.Endpoint = "/pools/default/remoteClusters/%s"
+
+	// EndpointCreateReplication is used to create a replication.
+	EndpointCreateReplication cbrest.Endpoint = "/controller/createReplication"
+
+	// EndpointCancelReplication is used to delete a replication.
+	EndpointCancelReplication cbrest.Endpoint = "/controller/cancelXDCR/%s"
+
+	// EndpointReplicationSettings is used to get/update the settings for a single replication.
+	EndpointReplicationSettings cbrest.Endpoint = "/settings/replications/%s"
+)
+
+// Client is a wrapper around the 'cbrest' client which implements methods to manage remote cluster references and XDCR
+// replications.
+type Client struct {
+	*cbrest.Client
+}
+
+// NewClient creates a new client which will dispatch requests using the given 'cbrest' client.
+func NewClient(client *cbrest.Client) *Client {
+	return &Client{client}
+}
+
+// ListRemoteClusters returns all the remote cluster references, excluding those which have been deleted.
+func (c *Client) ListRemoteClusters(ctx context.Context) ([]*RemoteCluster, error) {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointRemoteClusters,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            cbrest.ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var (
+		decoded []*RemoteCluster
+		deleted []struct {
+			Deleted bool `json:"deleted"`
+		}
+	)
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// Purposefully ignored, this can't fail if we've successfully unmarshalled the remote clusters
+	_ = json.Unmarshal(response.Body, &deleted)
+
+	clusters := make([]*RemoteCluster, 0, len(decoded))
+
+	for idx, cluster := range decoded {
+		if !deleted[idx].Deleted {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	return clusters, nil
+}
+
+// CreateRemoteCluster creates a new remote cluster reference, returning the created reference.
+func (c *Client) CreateRemoteCluster(ctx context.Context, options RemoteClusterOptions) (*RemoteCluster, error) {
+	if len(options.ClientCertificate) != 0 && len(options.ClientKey) == 0 {
+		return nil, ErrClientKeyRequired
+	}
+
+	request := &cbrest.Request{
+		Body:               []byte(options.values().Encode()),
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointRemoteClusters,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            cbrest.ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleRemoteClusterError(options.Name, response, err)
+	}
+
+	var cluster *RemoteCluster
+
+	err = json.Unmarshal(response.Body, &cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return cluster, nil
+}
+
+// DeleteRemoteCluster deletes the given remote cluster reference, a 'RemoteClusterNotFoundError' is returned if the
+// reference does not exist.
+//
+// NOTE: Remote cluster references can't be deleted whilst they're used by a replication.
+func (c *Client) DeleteRemoteCluster(ctx context.Context, name string) error {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointRemoteCluster.Format(name),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodDelete,
+		Service:            cbrest.ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleRemoteClusterError(name, response, err)
+	}
+
+	return nil
+}
+
+// ListReplications returns all the replications from this cluster, as reported by the tasks API.
+func (c *Client) ListReplications(ctx context.Context) ([]*Replication, error) {
+	tasks, err := c.ListTasks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	replications := make([]*Replication, 0)
+
+	for _, task := range tasks {
+		if task.Type == cbrest.TaskTypeXDCR {
+			replications = append(replications, newReplication(task))
+		}
+	}
+
+	return replications, nil
+}
+
+// CreateReplication creates a new replication, returning its id.
+func (c *Client) CreateReplication(ctx context.Context, options ReplicationOptions) (string, error) {
+	values, err := options.values()
+	if err != nil {
+		return "", err // Purposefully not wrapped
+	}
+
+	request := &cbrest.Request{
+		Body:               []byte(values.Encode()),
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointCreateReplication,
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            cbrest.ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return "", handleReplicationError(options, "", response, err)
+	}
+
+	type overlay struct {
+		ID string `json:"id"`
+	}
+
+	var decoded overlay
+
+	err = json.Unmarshal(response.Body, &decoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return decoded.ID, nil
+}
+
+// PauseReplication pauses the given replication, this is a no-op if the replication is already paused.
+func (c *Client) PauseReplication(ctx context.Context, id string) error {
+	return c.setPauseRequested(ctx, id, true)
+}
+
+// ResumeReplication resumes the given paused replication, this is a no-op if the replication is already running.
+func (c *Client) ResumeReplication(ctx context.Context, id string) error {
+	return c.setPauseRequested(ctx, id, false)
+}
+
+// DeleteReplication deletes the given replication, a 'ReplicationNotFoundError' is returned if the replication does not
+// exist.
+func (c *Client) DeleteReplication(ctx context.Context, id string) error {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointCancelReplication.Format(id),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodDelete,
+		Service:            cbrest.ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleReplicationError(ReplicationOptions{}, id, response, err)
+	}
+
+	return nil
+}
+
+// GetReplicationSettings returns the settings for the given replication, a 'ReplicationNotFoundError' is returned if
+// the replication does not exist.
+func (c *Client) GetReplicationSettings(ctx context.Context, id string) (*ReplicationSettings, error) {
+	request := &cbrest.Request{
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointReplicationSettings.Format(id),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodGet,
+		Service:            cbrest.ServiceManagement,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return nil, handleReplicationError(ReplicationOptions{}, id, response, err)
+	}
+
+	var settings *ReplicationSettings
+
+	err = json.Unmarshal(response.Body, &settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return settings, nil
+}
+
+// setPauseRequested pauses/resumes the given replication.
+func (c *Client) setPauseRequested(ctx context.Context, id string, pause bool) error {
+	values := url.Values{"pauseRequested": {strconv.FormatBool(pause)}}
+
+	request := &cbrest.Request{
+		Body:               []byte(values.Encode()),
+		ContentType:        cbrest.ContentTypeURLEncoded,
+		Endpoint:           EndpointReplicationSettings.Format(id),
+		ExpectedStatusCode: http.StatusOK,
+		Method:             http.MethodPost,
+		Service:            cbrest.ServiceManagement,
+		Idempotent:         true,
+	}
+
+	response, err := c.ExecuteWithContext(ctx, request)
+	if err != nil {
+		return handleReplicationError(ReplicationOptions{}, id, response, err)
+	}
+
+	return nil
+}
+
+// decodeErrors decodes the errors returned by the XDCR service, which are keyed by the option name (or '_' for errors
+// which don't relate to a specific option); the raw body is returned keyed by '_' if it's not in the expected format.
+func decodeErrors(response *cbrest.Response) map[string]string {
+	var decoded map[string]string
+	if json.Unmarshal(response.Body, &decoded) == nil && len(decoded) != 0 {
+		return decoded
+	}
+
+	return map[string]string{"_": strings.TrimSpace(string(response.Body))}
+}
+
+// handleRemoteClusterError converts the error returned by a remote cluster management request into a typed error where
+// possible, using the response body returned by the XDCR service.
+func handleRemoteClusterError(name string, response *cbrest.Response, err error) error {
+	if response == nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return &RemoteClusterNotFoundError{name: name}
+	}
+
+	if response.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	errs := decodeErrors(response)
+	msg := strings.ToLower(errs["_"])
+
+	switch {
+	case strings.Contains(msg, "unknown remote cluster"):
+		return &RemoteClusterNotFoundError{name: name}
+	case strings.Contains(msg, "duplicate cluster names"):
+		return &RemoteClusterExistsError{name: name}
+	}
+
+	return &ValidationError{errors: errs}
+}
+
+// handleReplicationError converts the error returned by a replication management request into a typed error where
+// possible, using the response body returned by the XDCR service.
+func handleReplicationError(options ReplicationOptions, id string, response *cbrest.Response, err error) error {
+	if response == nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return &ReplicationNotFoundError{id: id}
+	}
+
+	if response.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	errs := decodeErrors(response)
+	msg := strings.ToLower(errs["_"])
+
+	switch {
+	case strings.Contains(msg, "does not exist"):
+		return &ReplicationNotFoundError{id: id}
+	case strings.Contains(msg, "already exists"):
+		return &ReplicationExistsError{
+			source: options.SourceBucket,
+			remote: options.RemoteCluster,
+			target: options.TargetBucket,
+		}
+	case strings.Contains(strings.ToLower(errs["toCluster"]), "unknown remote cluster"):
+		return &RemoteClusterNotFoundError{name: options.RemoteCluster}
+	}
+
+	return &ValidationError{errors: errs}
+}
@@ -0,0 +1,509 @@
+package xdcrutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+// testReplication is a replication stored by the XDCR simulation.
+type testReplication struct {
+	remote   string
+	source   string
+	target   string
+	settings map[string]any
+}
+
+// testXDCR is a minimal simulation of the XDCR remote cluster and replication management endpoints.
+type testXDCR struct {
+	lock         sync.Mutex
+	remotes      map[string]map[string]any
+	replications map[string]*testReplication
+	uuids        int
+}
+
+func (x *testXDCR) fail(t *testing.T, writer http.ResponseWriter, key, msg string) {
+	cbrest.WriteTestJSON(t, writer, http.StatusBadRequest, map[string]string{key: msg})
+}
+
+func (x *testXDCR) handlers(t *testing.T, names, ids []string) cbrest.TestHandlers {
+	handlers := make(cbrest.TestHandlers)
+
+	handlers.Add(http.MethodGet, string(EndpointRemoteClusters), func(writer http.ResponseWriter, _ *http.Request) {
+		x.lock.Lock()
+		defer x.lock.Unlock()
+
+		// Deleted references are still reported by the cluster, they should be skipped by the client
+		remotes := []map[string]any{{"name": "deleted", "uuid": "deleted", "deleted": true}}
+
+		for _, name := range names {
+			if remote, ok := x.remotes[name]; ok {
+				remotes = append(remotes, remote)
+			}
+		}
+
+		cbrest.WriteTestJSON(t, writer, http.StatusOK, remotes)
+	})
+
+	handlers.Add(http.MethodPost, string(EndpointRemoteClusters), func(writer http.ResponseWriter,
+		request *http.Request,
+	) {
+		x.lock.Lock()
+		defer x.lock.Unlock()
+
+		require.NoError(t, request.ParseForm())
+
+		name := request.PostForm.Get("name")
+
+		if _, ok := x.remotes[name]; ok {
+			x.fail(t, writer, "_", "Duplicate cluster names are not allowed")
+			return
+		}
+
+		if request.PostForm.Get("hostname") == "" {
+			x.fail(t, writer, "hostname", "hostname (ip) is missing")
+			return
+		}
+
+		x.uuids++
+
+		remote := map[string]any{
+			"name":             name,
+			"uuid":             fmt.Sprintf("uuid%d", x.uuids),
+			"hostname":         request.PostForm.Get("hostname"),
+			"username":         request.PostForm.Get("username"),
+			"demandEncryption": request.PostForm.Get("demandEncryption") == "1",
+			"encryptionType":   request.PostForm.Get("encryptionType"),
+			"certificate":      request.PostForm.Get("certificate"),
+		}
+
+		x.remotes[name] = remote
+
+		cbrest.WriteTestJSON(t, writer, http.StatusOK, remote)
+	})
+
+	for _, name := range names {
+		name := name
+
+		handlers.Add(http.MethodDelete, "/pools/default/remoteClusters/"+name, func(writer http.ResponseWriter,
+			_ *http.Request,
+		) {
+			x.lock.Lock()
+			defer x.lock.Unlock()
+
+			if _, ok := x.remotes[name]; !ok {
+				cbrest.WriteTestJSON(t, writer, http.StatusNotFound, map[string]string{"_": "unknown remote cluster"})
+				return
+			}
+
+			delete(x.remotes, name)
+
+			cbrest.WriteTestJSON(t, writer, http.StatusOK, "ok")
+		})
+	}
+
+	handlers.Add(http.MethodPost, string(EndpointCreateReplication), func(writer http.ResponseWriter,
+		request *http.Request,
+	) {
+		x.lock.Lock()
+		defer x.lock.Unlock()
+
+		require.NoError(t, request.ParseForm())
+
+		remote, ok := x.remotes[request.PostForm.Get("toCluster")]
+		if !ok {
+			x.fail(t, writer, "toCluster", "unknown remote cluster")
+			return
+		}
+
+		var (
+			source = request.PostForm.Get("fromBucket")
+			target = request.PostForm.Get("toBucket")
+			id     = fmt.Sprintf("%s/%s/%s", remote["uuid"], source, target)
+		)
+
+		if _, ok := x.replications[id]; ok {
+			x.fail(t, writer, "_", fmt.Sprintf("Replication to bucket %s on cluster %s already exists", target,
+				remote["uuid"]))
+
+			return
+		}
+
+		settings := map[string]any{
+			"pauseRequested":   false,
+			"filterExpression": request.PostForm.Get("filterExpression"),
+			"priority":         "High",
+		}
+
+		if rules := request.PostForm.Get("colMappingRules"); rules != "" {
+			settings["colMappingRules"] = rules
+			settings["collectionsExplicitMapping"] = request.PostForm.Get("collectionsExplicitMapping") == "true"
+			settings["collectionsMigrationMode"] = request.PostForm.Get("collectionsMigrationMode") == "true"
+		}
+
+		x.replications[id] = &testReplication{
+			remote:   remote["uuid"].(string),
+			source:   source,
+			target:   target,
+			settings: settings,
+		}
+
+		cbrest.WriteTestJSON(t, writer, http.StatusOK, map[string]string{"id": id})
+	})
+
+	handlers.Add(http.MethodGet, string(cbrest.EndpointTasks), func(writer http.ResponseWriter, _ *http.Request) {
+		x.lock.Lock()
+		defer x.lock.Unlock()
+
+		tasks := []map[string]any{{"type": "rebalance", "status": "notRunning"}}
+
+		for _, id := range ids {
+			replication, ok := x.replications[id]
+			if !ok {
+				continue
+			}
+
+			status := "running"
+			if replication.settings["pauseRequested"].(bool) {
+				status = "paused"
+			}
+
+			tasks = append(tasks, map[string]any{
+				"id":     id,
+				"type":   "xdcr",
+				"status": status,
+				"source": replication.source,
+				"target": fmt.Sprintf("/remoteClusters/%s/buckets/%s", replication.remote, replication.target),
+				"errors": []any{},
+			})
+		}
+
+		cbrest.WriteTestJSON(t, writer, http.StatusOK, tasks)
+	})
+
+	for _, id := range ids {
+		id := id
+
+		notFound := func(writer http.ResponseWriter) bool {
+			if _, ok := x.replications[id]; ok {
+				return false
+			}
+
+			x.fail(t, writer, "_", fmt.Sprintf("Replication specification %s does not exist", id))
+
+			return true
+		}
+
+		handlers.Add(http.MethodGet, "/settings/replications/"+id, func(writer http.ResponseWriter,
+			_ *http.Request,
+		) {
+			x.lock.Lock()
+			defer x.lock.Unlock()
+
+			if notFound(writer) {
+				return
+			}
+
+			cbrest.WriteTestJSON(t, writer, http.StatusOK, x.replications[id].settings)
+		})
+
+		handlers.Add(http.MethodPost, "/settings/replications/"+id, func(writer http.ResponseWriter,
+			request *http.Request,
+		) {
+			x.lock.Lock()
+			defer x.lock.Unlock()
+
+			require.NoError(t, request.ParseForm())
+
+			if notFound(writer) {
+				return
+			}
+
+			settings := x.replications[id].settings
+			settings["pauseRequested"] = request.PostForm.Get("pauseRequested") == "true"
+
+			cbrest.WriteTestJSON(t, writer, http.StatusOK, settings)
+		})
+
+		handlers.Add(http.MethodDelete, "/controller/cancelXDCR/"+id, func(writer http.ResponseWriter,
+			_ *http.Request,
+		) {
+			x.lock.Lock()
+			defer x.lock.Unlock()
+
+			if notFound(writer) {
+				return
+			}
+
+			delete(x.replications, id)
+
+			cbrest.WriteTestJSON(t, writer, http.StatusOK, "ok")
+		})
+	}
+
+	return handlers
+}
+
+func newTestClient(t *testing.T, xdcr *testXDCR, names, ids []string) *Client {
+	if xdcr.remotes == nil {
+		xdcr.remotes = make(map[string]map[string]any)
+	}
+
+	if xdcr.replications == nil {
+		xdcr.replications = make(map[string]*testReplication)
+	}
+
+	nodes := cbrest.TestNodes{{Services: []cbrest.Service{cbrest.ServiceData}}}
+
+	return NewClient(cbrest.NewTestClusterClient(t, nodes, xdcr.handlers(t, names, ids)))
+}
+
+func TestClientRemoteClusterManagement(t *testing.T) {
+	client := newTestClient(t, &testXDCR{}, []string{"remote1", "remote2"}, nil)
+
+	ctx := context.Background()
+
+	clusters, err := client.ListRemoteClusters(ctx)
+	require.NoError(t, err)
+	require.Empty(t, clusters)
+
+	cluster, err := client.CreateRemoteCluster(ctx, RemoteClusterOptions{
+		Name:     "remote1",
+		Hostname: "172.20.1.1",
+		Username: "admin",
+		Password: "password",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &RemoteCluster{
+		Name:       "remote1",
+		UUID:       "uuid1",
+		Hostname:   "172.20.1.1",
+		Username:   "admin",
+		SecureType: SecureTypeNone,
+	}, cluster)
+
+	_, err = client.CreateRemoteCluster(ctx, RemoteClusterOptions{
+		Name:        "remote2",
+		Hostname:    "172.20.1.2",
+		SecureType:  SecureTypeFull,
+		Certificate: []byte("ca"),
+	})
+	require.NoError(t, err)
+
+	clusters, err = client.ListRemoteClusters(ctx)
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+	require.Equal(t, "remote2", clusters[1].Name)
+	require.Equal(t, SecureTypeFull, clusters[1].SecureType)
+	require.Equal(t, "ca", clusters[1].Certificate)
+
+	require.NoError(t, client.DeleteRemoteCluster(ctx, "remote1"))
+
+	clusters, err = client.ListRemoteClusters(ctx)
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	require.Equal(t, "remote2", clusters[0].Name)
+}
+
+func TestClientRemoteClusterManagementErrors(t *testing.T) {
+	type test struct {
+		name  string
+		fn    func(client *Client) error
+		check func(t *testing.T, err error)
+	}
+
+	tests := []*test{
+		{
+			name: "CreateExists",
+			fn: func(client *Client) error {
+				_, err := client.CreateRemoteCluster(context.Background(), RemoteClusterOptions{
+					Name:     "remote",
+					Hostname: "172.20.1.1",
+				})
+
+				return err
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsRemoteClusterExists(err)) },
+		},
+		{
+			name: "CreateClientKeyRequired",
+			fn: func(client *Client) error {
+				_, err := client.CreateRemoteCluster(context.Background(), RemoteClusterOptions{
+					Name:              "missing",
+					Hostname:          "172.20.1.1",
+					SecureType:        SecureTypeFull,
+					ClientCertificate: []byte("cert"),
+				})
+
+				return err
+			},
+			check: func(t *testing.T, err error) { require.ErrorIs(t, err, ErrClientKeyRequired) },
+		},
+		{
+			name: "CreateValidation",
+			fn: func(client *Client) error {
+				_, err := client.CreateRemoteCluster(context.Background(), RemoteClusterOptions{Name: "missing"})
+				return err
+			},
+			check: func(t *testing.T, err error) {
+				var validationErr *ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				require.Equal(t, map[string]string{"hostname": "hostname (ip) is missing"}, validationErr.Errors())
+			},
+		},
+		{
+			name:  "DeleteNotFound",
+			fn:    func(client *Client) error { return client.DeleteRemoteCluster(context.Background(), "missing") },
+			check: func(t *testing.T, err error) { require.True(t, IsRemoteClusterNotFound(err)) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			xdcr := &testXDCR{remotes: map[string]map[string]any{"remote": {"name": "remote", "uuid": "uuid"}}}
+			test.check(t, test.fn(newTestClient(t, xdcr, []string{"remote", "missing"}, nil)))
+		})
+	}
+}
+
+func TestClientReplicationManagement(t *testing.T) {
+	var (
+		xdcr = &testXDCR{remotes: map[string]map[string]any{"remote": {"name": "remote", "uuid": "uuid"}}}
+		ids  = []string{"uuid/source/target", "uuid/source/other"}
+	)
+
+	client := newTestClient(t, xdcr, nil, ids)
+
+	ctx := context.Background()
+
+	replications, err := client.ListReplications(ctx)
+	require.NoError(t, err)
+	require.Empty(t, replications)
+
+	id, err := client.CreateReplication(ctx, ReplicationOptions{
+		SourceBucket:       "source",
+		RemoteCluster:      "remote",
+		TargetBucket:       "target",
+		FilterExpression:   "REGEXP_CONTAINS(META().id, '^a')",
+		CollectionMappings: map[string]string{"s1.c1": "s2.c2"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ids[0], id)
+
+	_, err = client.CreateReplication(ctx, ReplicationOptions{
+		SourceBucket:  "source",
+		RemoteCluster: "remote",
+		TargetBucket:  "other",
+	})
+	require.NoError(t, err)
+
+	settings, err := client.GetReplicationSettings(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, &ReplicationSettings{
+		FilterExpression:           "REGEXP_CONTAINS(META().id, '^a')",
+		CollectionsExplicitMapping: true,
+		CollectionMappings:         map[string]string{"s1.c1": "s2.c2"},
+		Priority:                   "High",
+	}, settings)
+
+	require.NoError(t, client.PauseReplication(ctx, id))
+
+	replications, err = client.ListReplications(ctx)
+	require.NoError(t, err)
+	require.Len(t, replications, 2)
+	require.Equal(t, &Replication{
+		ID:                id,
+		RemoteClusterUUID: "uuid",
+		SourceBucket:      "source",
+		TargetBucket:      "target",
+		Status:            cbrest.TaskStatusPaused,
+	}, replications[0])
+	require.False(t, replications[1].Paused())
+
+	require.NoError(t, client.ResumeReplication(ctx, id))
+
+	settings, err = client.GetReplicationSettings(ctx, id)
+	require.NoError(t, err)
+	require.False(t, settings.PauseRequested)
+
+	require.NoError(t, client.DeleteReplication(ctx, id))
+
+	replications, err = client.ListReplications(ctx)
+	require.NoError(t, err)
+	require.Len(t, replications, 1)
+	require.Equal(t, ids[1], replications[0].ID)
+}
+
+func TestClientReplicationManagementErrors(t *testing.T) {
+	type test struct {
+		name  string
+		fn    func(client *Client) error
+		check func(t *testing.T, err error)
+	}
+
+	tests := []*test{
+		{
+			name: "CreateExists",
+			fn: func(client *Client) error {
+				_, err := client.CreateReplication(context.Background(), ReplicationOptions{
+					SourceBucket:  "source",
+					RemoteCluster: "remote",
+					TargetBucket:  "target",
+				})
+
+				return err
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsReplicationExists(err)) },
+		},
+		{
+			name: "CreateUnknownRemoteCluster",
+			fn: func(client *Client) error {
+				_, err := client.CreateReplication(context.Background(), ReplicationOptions{
+					SourceBucket:  "source",
+					RemoteCluster: "missing",
+					TargetBucket:  "target",
+				})
+
+				return err
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsRemoteClusterNotFound(err)) },
+		},
+		{
+			name:  "PauseNotFound",
+			fn:    func(client *Client) error { return client.PauseReplication(context.Background(), "uuid/a/b") },
+			check: func(t *testing.T, err error) { require.True(t, IsReplicationNotFound(err)) },
+		},
+		{
+			name: "GetSettingsNotFound",
+			fn: func(client *Client) error {
+				_, err := client.GetReplicationSettings(context.Background(), "uuid/a/b")
+				return err
+			},
+			check: func(t *testing.T, err error) { require.True(t, IsReplicationNotFound(err)) },
+		},
+		{
+			name:  "DeleteNotFound",
+			fn:    func(client *Client) error { return client.DeleteReplication(context.Background(), "uuid/a/b") },
+			check: func(t *testing.T, err error) { require.True(t, IsReplicationNotFound(err)) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			xdcr := &testXDCR{
+				remotes: map[string]map[string]any{"remote": {"name": "remote", "uuid": "uuid"}},
+				replications: map[string]*testReplication{
+					"uuid/source/target": {remote: "uuid", source: "source", target: "target"},
+				},
+			}
+
+			test.check(t, test.fn(newTestClient(t, xdcr, nil, []string{"uuid/source/target", "uuid/a/b"})))
+		})
+	}
+}
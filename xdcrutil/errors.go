@@ -0,0 +1,100 @@
+package xdcrutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// ErrClientKeyRequired is returned if the user attempts to create a remote cluster reference which uses a client
+// certificate without providing the corresponding private key.
+var ErrClientKeyRequired = errors.New("a client key is required when using a client certificate")
+
+// RemoteClusterNotFoundError is returned if the user attempts to interact with a remote cluster reference which doesn't
+// exist.
+type RemoteClusterNotFoundError struct {
+	name string
+}
+
+func (e *RemoteClusterNotFoundError) Error() string {
+	return fmt.Sprintf("remote cluster '%s' not found", e.name)
+}
+
+// IsRemoteClusterNotFound returns a boolean indicating whether the given error is a 'RemoteClusterNotFoundError'.
+func IsRemoteClusterNotFound(err error) bool {
+	var notFound *RemoteClusterNotFoundError
+	return errors.As(err, &notFound)
+}
+
+// RemoteClusterExistsError is returned if the user attempts to create a remote cluster reference which already exists.
+type RemoteClusterExistsError struct {
+	name string
+}
+
+func (e *RemoteClusterExistsError) Error() string {
+	return fmt.Sprintf("remote cluster '%s' already exists", e.name)
+}
+
+// IsRemoteClusterExists returns a boolean indicating whether the given error is a 'RemoteClusterExistsError'.
+func IsRemoteClusterExists(err error) bool {
+	var exists *RemoteClusterExistsError
+	return errors.As(err, &exists)
+}
+
+// ReplicationNotFoundError is returned if the user attempts to interact with a replication which doesn't exist.
+type ReplicationNotFoundError struct {
+	id string
+}
+
+func (e *ReplicationNotFoundError) Error() string {
+	return fmt.Sprintf("replication '%s' not found", e.id)
+}
+
+// IsReplicationNotFound returns a boolean indicating whether the given error is a 'ReplicationNotFoundError'.
+func IsReplicationNotFound(err error) bool {
+	var notFound *ReplicationNotFoundError
+	return errors.As(err, &notFound)
+}
+
+// ReplicationExistsError is returned if the user attempts to create a replication which already exists.
+type ReplicationExistsError struct {
+	source, remote, target string
+}
+
+func (e *ReplicationExistsError) Error() string {
+	return fmt.Sprintf("replication from bucket '%s' to bucket '%s' on remote cluster '%s' already exists", e.source,
+		e.target, e.remote)
+}
+
+// IsReplicationExists returns a boolean indicating whether the given error is a 'ReplicationExistsError'.
+func IsReplicationExists(err error) bool {
+	var exists *ReplicationExistsError
+	return errors.As(err, &exists)
+}
+
+// ValidationError is returned if the XDCR service rejected the options supplied when creating a remote cluster
+// reference/replication.
+type ValidationError struct {
+	errors map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	keys := maps.Keys(e.errors)
+	slices.Sort(keys)
+
+	msgs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", key, e.errors[key]))
+	}
+
+	return fmt.Sprintf("invalid options: %s", strings.Join(msgs, ", "))
+}
+
+// Errors returns the validation errors returned by the XDCR service, keyed by the option name; errors which don't
+// relate to a specific option are keyed by '_'.
+func (e *ValidationError) Errors() map[string]string {
+	return maps.Clone(e.errors)
+}
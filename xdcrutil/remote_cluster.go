@@ -0,0 +1,148 @@
+package xdcrutil
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// SecureType represents the level of encryption used when communicating with a remote cluster.
+type SecureType string
+
+const (
+	// SecureTypeNone indicates that no data is encrypted.
+	SecureTypeNone SecureType = "none"
+
+	// SecureTypeHalf indicates that only credentials are encrypted.
+	SecureTypeHalf SecureType = "half"
+
+	// SecureTypeFull indicates that all data is encrypted, the certificate for the remote cluster should be provided.
+	SecureTypeFull SecureType = "full"
+)
+
+// RemoteCluster represents a reference to a remote cluster which may be used as the target of a replication.
+type RemoteCluster struct {
+	Name       string
+	UUID       string
+	Hostname   string
+	Username   string
+	SecureType SecureType
+
+	// Certificate is the PEM encoded certificate used to authenticate the remote cluster.
+	Certificate string
+
+	// ClientCertificate is the PEM encoded certificate used to authenticate with the remote cluster.
+	ClientCertificate string
+
+	// NetworkType is the network used to connect to the remote cluster, where 'external' indicates that alternate
+	// addresses should be used.
+	NetworkType string
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, determining the secure type from the legacy encryption
+// attributes for versions of Couchbase Server which don't report it.
+func (r *RemoteCluster) UnmarshalJSON(data []byte) error {
+	type overlay struct {
+		Name              string     `json:"name"`
+		UUID              string     `json:"uuid"`
+		Hostname          string     `json:"hostname"`
+		Username          string     `json:"username"`
+		SecureType        SecureType `json:"secureType"`
+		DemandEncryption  bool       `json:"demandEncryption"`
+		EncryptionType    SecureType `json:"encryptionType"`
+		Certificate       string     `json:"certificate"`
+		ClientCertificate string     `json:"clientCertificate"`
+		NetworkType       string     `json:"network_type"`
+	}
+
+	var decoded overlay
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	*r = RemoteCluster{
+		Name:              decoded.Name,
+		UUID:              decoded.UUID,
+		Hostname:          decoded.Hostname,
+		Username:          decoded.Username,
+		SecureType:        decoded.SecureType,
+		Certificate:       decoded.Certificate,
+		ClientCertificate: decoded.ClientCertificate,
+		NetworkType:       decoded.NetworkType,
+	}
+
+	if r.SecureType != "" {
+		return nil
+	}
+
+	r.SecureType = SecureTypeNone
+
+	if decoded.DemandEncryption {
+		r.SecureType = SecureTypeFull
+	}
+
+	if decoded.DemandEncryption && decoded.EncryptionType != "" {
+		r.SecureType = decoded.EncryptionType
+	}
+
+	return nil
+}
+
+// RemoteClusterOptions encapsulates the options which may be supplied when creating a remote cluster reference.
+type RemoteClusterOptions struct {
+	// Name is the name of the remote cluster reference.
+	//
+	// NOTE: This attribute is required.
+	Name string
+
+	// Hostname is the address of a node in the remote cluster.
+	//
+	// NOTE: This attribute is required.
+	Hostname string
+
+	// Username/Password are the credentials used to authenticate with the remote cluster, these may be omitted when
+	// using a client certificate.
+	Username string
+	Password string
+
+	// SecureType is the level of encryption which should be used, no encryption is used by default.
+	SecureType SecureType
+
+	// Certificate is the PEM encoded certificate used to authenticate the remote cluster.
+	Certificate []byte
+
+	// ClientCertificate/ClientKey are the PEM encoded certificate/key used to authenticate with the remote cluster.
+	ClientCertificate []byte
+	ClientKey         []byte
+
+	// NetworkType is the network used to connect to the remote cluster, where 'external' indicates that alternate
+	// addresses should be used.
+	NetworkType string
+}
+
+// values returns the options encoded as form values.
+func (r RemoteClusterOptions) values() url.Values {
+	values := url.Values{"name": {r.Name}, "hostname": {r.Hostname}}
+
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	set("username", r.Username)
+	set("password", r.Password)
+
+	if r.SecureType == SecureTypeHalf || r.SecureType == SecureTypeFull {
+		values.Set("demandEncryption", "1")
+		values.Set("encryptionType", string(r.SecureType))
+	}
+
+	set("certificate", string(r.Certificate))
+	set("clientCertificate", string(r.ClientCertificate))
+	set("clientKey", string(r.ClientKey))
+	set("network_type", r.NetworkType)
+
+	return values
+}
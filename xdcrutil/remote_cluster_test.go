@@ -0,0 +1,95 @@
+package xdcrutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteClusterUnmarshalJSON(t *testing.T) {
+	type test struct {
+		name     string
+		data     string
+		expected SecureType
+	}
+
+	tests := []*test{
+		{
+			name:     "SecureType",
+			data:     `{"name":"remote","secureType":"half","demandEncryption":true,"encryptionType":"half"}`,
+			expected: SecureTypeHalf,
+		},
+		{
+			name:     "LegacyNone",
+			data:     `{"name":"remote","demandEncryption":false}`,
+			expected: SecureTypeNone,
+		},
+		{
+			name:     "LegacyFull",
+			data:     `{"name":"remote","demandEncryption":true}`,
+			expected: SecureTypeFull,
+		},
+		{
+			name:     "LegacyHalf",
+			data:     `{"name":"remote","demandEncryption":true,"encryptionType":"half"}`,
+			expected: SecureTypeHalf,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cluster *RemoteCluster
+
+			require.NoError(t, json.Unmarshal([]byte(test.data), &cluster))
+			require.Equal(t, "remote", cluster.Name)
+			require.Equal(t, test.expected, cluster.SecureType)
+		})
+	}
+}
+
+func TestRemoteClusterOptionsValues(t *testing.T) {
+	type test struct {
+		name     string
+		options  RemoteClusterOptions
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name: "Credentials",
+			options: RemoteClusterOptions{
+				Name:     "remote",
+				Hostname: "172.20.1.1",
+				Username: "admin",
+				Password: "password",
+			},
+			expected: "hostname=172.20.1.1&name=remote&password=password&username=admin",
+		},
+		{
+			name: "Full",
+			options: RemoteClusterOptions{
+				Name:              "remote",
+				Hostname:          "172.20.1.1",
+				SecureType:        SecureTypeFull,
+				Certificate:       []byte("ca"),
+				ClientCertificate: []byte("cert"),
+				ClientKey:         []byte("key"),
+				NetworkType:       "external",
+			},
+			expected: "certificate=ca&clientCertificate=cert&clientKey=key&demandEncryption=1&encryptionType=full&" +
+				"hostname=172.20.1.1&name=remote&network_type=external",
+		},
+		{
+			name:     "None",
+			options:  RemoteClusterOptions{Name: "remote", Hostname: "172.20.1.1", SecureType: SecureTypeNone},
+			expected: "hostname=172.20.1.1&name=remote",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.options.values().Encode())
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package xdcrutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+// Replication represents an XDCR replication from a bucket on this cluster to a bucket on a remote cluster.
+type Replication struct {
+	// ID is the unique identifier for the replication in the form '<remote cluster uuid>/<source>/<target>'.
+	ID                string
+	RemoteClusterUUID string
+	SourceBucket      string
+	TargetBucket      string
+
+	// Status is the status of the replication, either running or paused.
+	Status cbrest.TaskStatus
+
+	// Errors are the most recent errors reported by the replication.
+	Errors []string
+}
+
+// newReplication creates a replication from the XDCR task reported by the cluster.
+func newReplication(task *cbrest.Task) *Replication {
+	replication := &Replication{
+		ID:           task.ID,
+		SourceBucket: task.Bucket,
+		Status:       task.Status,
+		Errors:       task.Errors,
+	}
+
+	// The target is reported in the form '/remoteClusters/<remote cluster uuid>/buckets/<target>'
+	parts := strings.Split(strings.TrimPrefix(task.Target, "/"), "/")
+	if len(parts) == 4 {
+		replication.RemoteClusterUUID, replication.TargetBucket = parts[1], parts[3]
+	}
+
+	return replication
+}
+
+// Paused returns a boolean indicating whether the replication is paused.
+func (r *Replication) Paused() bool {
+	return r.Status == cbrest.TaskStatusPaused
+}
+
+// ReplicationOptions encapsulates the options which may be supplied when creating a replication.
+type ReplicationOptions struct {
+	// SourceBucket is the name of the bucket on this cluster to replicate from.
+	//
+	// NOTE: This attribute is required.
+	SourceBucket string
+
+	// RemoteCluster is the name of the remote cluster reference to replicate to.
+	//
+	// NOTE: This attribute is required.
+	RemoteCluster string
+
+	// TargetBucket is the name of the bucket on the remote cluster to replicate to.
+	//
+	// NOTE: This attribute is required.
+	TargetBucket string
+
+	// FilterExpression is an optional expression used to filter which documents are replicated.
+	FilterExpression string
+
+	// CollectionMappings explicitly map source scopes/collections to target scopes/collections e.g. 's1.c1' to 's2.c2',
+	// by default, scopes/collections are implicitly mapped to the target scopes/collections with the same name.
+	CollectionMappings map[string]string
+
+	// MigrationMode indicates that the collection mappings are migration rules, where the keys are filter expressions
+	// used to select documents from the default collection of the source bucket.
+	MigrationMode bool
+
+	// Priority is the priority of the replication in relation to other replications e.g. 'High', 'Medium' or 'Low'.
+	Priority string
+}
+
+// values returns the options encoded as form values.
+func (r ReplicationOptions) values() (url.Values, error) {
+	values := url.Values{
+		"fromBucket":      {r.SourceBucket},
+		"toCluster":       {r.RemoteCluster},
+		"toBucket":        {r.TargetBucket},
+		"replicationType": {"continuous"},
+	}
+
+	if r.FilterExpression != "" {
+		values.Set("filterExpression", r.FilterExpression)
+	}
+
+	if r.Priority != "" {
+		values.Set("priority", r.Priority)
+	}
+
+	if len(r.CollectionMappings) == 0 {
+		return values, nil
+	}
+
+	rules, err := json.Marshal(r.CollectionMappings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal collection mappings: %w", err)
+	}
+
+	values.Set("colMappingRules", string(rules))
+
+	if r.MigrationMode {
+		values.Set("collectionsMigrationMode", "true")
+	} else {
+		values.Set("collectionsExplicitMapping", "true")
+	}
+
+	return values, nil
+}
+
+// ReplicationSettings represents the settings for a replication.
+type ReplicationSettings struct {
+	PauseRequested                 bool   `json:"pauseRequested"`
+	FilterExpression               string `json:"filterExpression"`
+	CollectionsExplicitMapping     bool   `json:"collectionsExplicitMapping"`
+	CollectionsMigrationMode       bool   `json:"collectionsMigrationMode"`
+	CheckpointInterval             int    `json:"checkpointInterval"`
+	WorkerBatchSize                int    `json:"workerBatchSize"`
+	DocBatchSizeKB                 int    `json:"docBatchSizeKb"`
+	FailureRestartInterval         int    `json:"failureRestartInterval"`
+	OptimisticReplicationThreshold int    `json:"optimisticReplicationThreshold"`
+	SourceNozzlePerNode            int    `json:"sourceNozzlePerNode"`
+	TargetNozzlePerNode            int    `json:"targetNozzlePerNode"`
+	NetworkUsageLimit              int    `json:"networkUsageLimit"`
+	CompressionType                string `json:"compressionType"`
+	Priority                       string `json:"priority"`
+	LogLevel                       string `json:"logLevel"`
+
+	// CollectionMappings are the explicit mappings/migration rules for the replication.
+	CollectionMappings map[string]string `json:"-"`
+}
+
+// UnmarshalJSON implements the 'json.Unmarshaler' interface, the collection mapping rules may be returned as either an
+// object, or a JSON encoded string depending on the version of Couchbase Server.
+func (r *ReplicationSettings) UnmarshalJSON(data []byte) error {
+	type alias ReplicationSettings
+
+	decoded := struct {
+		*alias
+		Rules json.RawMessage `json:"colMappingRules"`
+	}{
+		alias: (*alias)(r),
+	}
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err // Purposefully not wrapped
+	}
+
+	if len(decoded.Rules) == 0 {
+		return nil
+	}
+
+	var encoded string
+	if json.Unmarshal(decoded.Rules, &encoded) == nil {
+		decoded.Rules = json.RawMessage(encoded)
+	}
+
+	if len(decoded.Rules) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(decoded.Rules, &r.CollectionMappings)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal collection mapping rules: %w", err)
+	}
+
+	return nil
+}
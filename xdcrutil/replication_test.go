@@ -0,0 +1,137 @@
+package xdcrutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/couchbase/tools-common/cbrest"
+)
+
+func TestNewReplication(t *testing.T) {
+	task := &cbrest.Task{
+		ID:     "uuid/source/target",
+		Type:   cbrest.TaskTypeXDCR,
+		Status: cbrest.TaskStatusPaused,
+		Bucket: "source",
+		Target: "/remoteClusters/uuid/buckets/target",
+		Errors: []string{"error"},
+	}
+
+	expected := &Replication{
+		ID:                "uuid/source/target",
+		RemoteClusterUUID: "uuid",
+		SourceBucket:      "source",
+		TargetBucket:      "target",
+		Status:            cbrest.TaskStatusPaused,
+		Errors:            []string{"error"},
+	}
+
+	replication := newReplication(task)
+	require.Equal(t, expected, replication)
+	require.True(t, replication.Paused())
+}
+
+func TestReplicationOptionsValues(t *testing.T) {
+	type test struct {
+		name     string
+		options  ReplicationOptions
+		expected string
+	}
+
+	tests := []*test{
+		{
+			name:    "Bucket",
+			options: ReplicationOptions{SourceBucket: "source", RemoteCluster: "remote", TargetBucket: "target"},
+			expected: "fromBucket=source&replicationType=continuous&toBucket=target&" +
+				"toCluster=remote",
+		},
+		{
+			name: "ExplicitMapping",
+			options: ReplicationOptions{
+				SourceBucket:       "source",
+				RemoteCluster:      "remote",
+				TargetBucket:       "target",
+				FilterExpression:   "REGEXP_CONTAINS(META().id, '^a')",
+				CollectionMappings: map[string]string{"s1.c1": "s2.c2"},
+				Priority:           "Low",
+			},
+			expected: "colMappingRules=%7B%22s1.c1%22%3A%22s2.c2%22%7D&collectionsExplicitMapping=true&" +
+				"filterExpression=REGEXP_CONTAINS%28META%28%29.id%2C+%27%5Ea%27%29&fromBucket=source&priority=Low&" +
+				"replicationType=continuous&toBucket=target&toCluster=remote",
+		},
+		{
+			name: "MigrationMode",
+			options: ReplicationOptions{
+				SourceBucket:       "source",
+				RemoteCluster:      "remote",
+				TargetBucket:       "target",
+				CollectionMappings: map[string]string{"type=\"beer\"": "s1.beers"},
+				MigrationMode:      true,
+			},
+			expected: "colMappingRules=%7B%22type%3D%5C%22beer%5C%22%22%3A%22s1.beers%22%7D&" +
+				"collectionsMigrationMode=true&fromBucket=source&replicationType=continuous&toBucket=target&" +
+				"toCluster=remote",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, err := test.options.values()
+			require.NoError(t, err)
+			require.Equal(t, test.expected, values.Encode())
+		})
+	}
+}
+
+func TestReplicationSettingsUnmarshalJSON(t *testing.T) {
+	type test struct {
+		name     string
+		data     string
+		expected *ReplicationSettings
+	}
+
+	tests := []*test{
+		{
+			name: "Object",
+			data: `{"pauseRequested":true,"filterExpression":"a","collectionsExplicitMapping":true,` +
+				`"colMappingRules":{"s1.c1":"s2.c2"},"checkpointInterval":600,"priority":"High"}`,
+			expected: &ReplicationSettings{
+				PauseRequested:             true,
+				FilterExpression:           "a",
+				CollectionsExplicitMapping: true,
+				CollectionMappings:         map[string]string{"s1.c1": "s2.c2"},
+				CheckpointInterval:         600,
+				Priority:                   "High",
+			},
+		},
+		{
+			name: "String",
+			data: `{"collectionsMigrationMode":true,"colMappingRules":"{\"type=\\\"beer\\\"\":\"s1.beers\"}"}`,
+			expected: &ReplicationSettings{
+				CollectionsMigrationMode: true,
+				CollectionMappings:       map[string]string{`type="beer"`: "s1.beers"},
+			},
+		},
+		{
+			name:     "EmptyString",
+			data:     `{"colMappingRules":""}`,
+			expected: &ReplicationSettings{},
+		},
+		{
+			name:     "Missing",
+			data:     `{"workerBatchSize":500}`,
+			expected: &ReplicationSettings{WorkerBatchSize: 500},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var settings *ReplicationSettings
+
+			require.NoError(t, json.Unmarshal([]byte(test.data), &settings))
+			require.Equal(t, test.expected, settings)
+		})
+	}
+}